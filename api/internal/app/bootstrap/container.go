@@ -11,13 +11,22 @@ import (
 	"time-attendance-be/internal/modules/department"
 	"time-attendance-be/internal/modules/leave"
 	"time-attendance-be/internal/modules/notes"
+	"time-attendance-be/internal/modules/offboarding"
+	"time-attendance-be/internal/modules/rbac"
+	"time-attendance-be/internal/modules/scheduler"
 	"time-attendance-be/internal/modules/stats"
+	"time-attendance-be/internal/modules/timesheet"
 	"time-attendance-be/internal/modules/user"
 	"time-attendance-be/internal/modules/workcalendar"
 	"time-attendance-be/internal/pkg/clock"
 	platformauth "time-attendance-be/internal/platform/auth"
 	"time-attendance-be/internal/platform/db"
+	"time-attendance-be/internal/platform/events"
+	"time-attendance-be/internal/platform/lease"
 	"time-attendance-be/internal/platform/logger"
+	"time-attendance-be/internal/platform/mailer"
+	"time-attendance-be/internal/platform/oidc"
+	jobscheduler "time-attendance-be/internal/platform/scheduler"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -32,9 +41,17 @@ type Container struct {
 
 	JWT *platformauth.Manager
 
+	// Scheduled jobs; only the holder of the scheduler lease runs them
+	Jobs    *jobscheduler.Scheduler
+	Elector *lease.Elector
+
+	// Development identity provider for testing single sign-on (nil unless OIDC_MOCK_IDP)
+	MockIdP *oidc.MockIdP
+
 	// Middlewares
-	AuthRequired  *middleware.AuthRequired
-	AdminRequired *middleware.AdminRequired
+	AuthRequired    *middleware.AuthRequired
+	AdminRequired   *middleware.AdminRequired
+	ManagerRequired *middleware.ManagerRequired
 
 	// Modules
 	Auth        *auth.Module
@@ -46,6 +63,10 @@ type Container struct {
 	Leave       *leave.Module
 	WorkCalendar *workcalendar.Module
 	Audit       *audit.Module
+	Scheduler   *scheduler.Module
+	Timesheets  *timesheet.Module
+	Roles       *rbac.Module
+	Offboarding *offboarding.Module
 }
 
 func NewContainer(cfg *config.Config) *Container {
@@ -54,6 +75,11 @@ func NewContainer(cfg *config.Config) *Container {
 	gormDB := db.NewMySQL(cfg, log)
 
 	jwtMgr := platformauth.NewManager(cfg)
+	eventBus := events.NewBus(log)
+	mail, err := mailer.New(cfg, log)
+	if err != nil {
+		log.Fatal("mailer init failed", zap.Error(err))
+	}
 
 	// Repositories
 	userRepo := user.NewRepo(gormDB)
@@ -63,10 +89,32 @@ func NewContainer(cfg *config.Config) *Container {
 	leaveRepo := leave.NewRepo(gormDB)
 	workCalRepo := workcalendar.NewRepo(gormDB)
 	auditRepo := audit.NewRepo(gormDB)
+	authRepo := auth.NewRepo(gormDB)
+	timesheetRepo := timesheet.NewRepo(gormDB)
+	rbacRepo := rbac.NewRepo(gormDB)
+	offboardingRepo := offboarding.NewRepo(gormDB)
 
 	// Services
-	authSvc := auth.NewService(cfg, userRepo, jwtMgr)
+	authSvc := auth.NewService(cfg, userRepo, authRepo, jwtMgr, mail, log)
+	var mockIdP *oidc.MockIdP
+	if cfg.OIDC.MockIdP && !cfg.IsProduction() {
+		if mockIdP, err = oidc.NewMockIdP(cfg.OIDC.IssuerURL); err != nil {
+			log.Fatal("mock identity provider init failed", zap.Error(err))
+		}
+	}
+	if cfg.OIDC.Enabled {
+		if cfg.OIDC.IssuerURL == "" || cfg.OIDC.ClientID == "" {
+			log.Fatal("OIDC_ENABLED requires OIDC_ISSUER_URL and OIDC_CLIENT_ID")
+		}
+		authSvc.SetOIDCProvider(oidc.NewProvider(cfg.OIDC, nil))
+	}
 	userSvc := user.NewService(cfg, userRepo, deptRepo)
+	rbacSvc := rbac.NewService(rbacRepo, userRepo, deptRepo, log)
+	if err := rbacSvc.EnsureBuiltinRoles(context.Background()); err != nil {
+		log.Error("built-in roles sync failed", zap.Error(err))
+	}
+	authSvc.SetPermissionStore(rbacSvc) // Admin 2FA policy covers every role with admin area access
+	userSvc.SetPermissionStore(rbacSvc) // Password/email changes only for users the caller covers
 	deptSvc := department.NewService(deptRepo)
 	attSvc := attendance.NewService(cfg, attRepo, clk)
 	attSvc.SetUserRepo(userRepo) // Set userRepo for attendance service
@@ -79,9 +127,15 @@ func NewContainer(cfg *config.Config) *Container {
 	leaveSvc := leave.NewService(cfg, userRepo, leaveRepo, log)
 	leaveSvc.SetAttendanceRepo(attRepo) // Set attendance repo for auto leave detection
 	leaveSvc.SetWorkCalendarRepo(workCalAdapter) // Use adapter instead of direct repo
+	leaveSvc.SetSettlementRepo(offboardingRepo)  // Offboarded users' summaries use the settled balance
+	attSvc.SetLeaveRepo(leaveSvc)                // Per-day leave flags on the timesheet
+	attSvc.SetEventBus(eventBus)                 // Publish session changes
+	leaveSvc.SubscribeEvents(eventBus)           // Queue summary recompute when sessions change
 
-	// Create leave module
-	leaveMod := leave.NewModule(leaveSvc)
+	workCalSvc := workcalendar.NewService(cfg, workCalRepo, leaveSvc, userRepo)
+	timesheetSvc := timesheet.NewService(cfg, timesheetRepo, userRepo, attRepo, leaveSvc, workCalSvc, mail, log)
+	attSvc.SetMonthLock(timesheetSvc) // Signed-off months are read-only until reopened
+	offboardingSvc := offboarding.NewService(cfg, offboardingRepo, userRepo, attSvc, leaveSvc, authSvc, log)
 
 	// Ensure work calendar for current year exists
 	_ = workCalRepo.EnsureYear(context.Background(), clock.New(cfg.TimeLocation()).Now().Year())
@@ -89,36 +143,59 @@ func NewContainer(cfg *config.Config) *Container {
 	// Audit service
 	auditSvc := audit.NewService(auditRepo)
 
+	// Create leave module
+	leaveMod := leave.NewModule(leaveSvc, auditSvc)
+
 	// Modules
-	authMod := auth.NewModule(authSvc, cfg)
+	authMod := auth.NewModule(authSvc, cfg, auditSvc)
 	usersMod := user.NewModule(userSvc, auditSvc)
-	deptMod := department.NewModule(deptSvc)
+	deptMod := department.NewModule(deptSvc, auditSvc)
 	attMod := attendance.NewModule(attSvc)
 	noteMod := notes.NewModule(noteSvc)
 	statsMod := stats.NewModule(cfg, gormDB, clk)
-	workCalMod := workcalendar.NewModule(workCalRepo, leaveSvc, log, auditSvc)
+	workCalMod := workcalendar.NewModule(cfg, workCalRepo, workCalSvc, leaveSvc, userRepo, log, auditSvc)
 	auditMod := audit.NewModule(auditRepo)
+	timesheetMod := timesheet.NewModule(timesheetSvc, auditSvc)
+	rbacMod := rbac.NewModule(rbacSvc, auditSvc)
+	offboardingMod := offboarding.NewModule(offboardingSvc, auditSvc)
+
+	// Scheduled jobs (cron in APP_TZ) + lease so one leader across API replicas runs them
+	jobs := jobscheduler.New(gormDB, cfg.TimeLocation(), cfg.Scheduler.InstanceID, log)
+	leaveSvc.RegisterJobs(jobs)
+	authSvc.RegisterJobs(jobs)
+	timesheetSvc.RegisterJobs(jobs)
+	elector := lease.NewElector(gormDB, "scheduler", cfg.Scheduler.InstanceID, cfg.Scheduler.LeaseTTL, log)
+	schedulerMod := scheduler.NewModule(elector, jobs, auditSvc)
 
 	// Middlewares
-	authRequired := middleware.NewAuthRequired(cfg, jwtMgr, userRepo)
-	adminRequired := middleware.NewAdminRequired()
+	authRequired := middleware.NewAuthRequired(cfg, jwtMgr, userRepo, authSvc, authSvc, rbacSvc)
+	adminRequired := middleware.NewAdminRequired(authSvc)
+	managerRequired := middleware.NewManagerRequired(deptSvc)
 
 	return &Container{
-		Cfg:           cfg,
-		Logger:        log,
-		DB:            gormDB,
-		Clock:         clk,
-		JWT:           jwtMgr,
-		AuthRequired:  authRequired,
-		AdminRequired: adminRequired,
-		Auth:          authMod,
-		Users:         usersMod,
-		Departments:   deptMod,
-		Attendance:    attMod,
-		Notes:         noteMod,
-		Stats:         statsMod,
-		Leave:         leaveMod,
-		WorkCalendar:  workCalMod,
-		Audit:         auditMod,
+		Cfg:             cfg,
+		Logger:          log,
+		DB:              gormDB,
+		Clock:           clk,
+		JWT:             jwtMgr,
+		Jobs:            jobs,
+		Elector:         elector,
+		MockIdP:         mockIdP,
+		AuthRequired:    authRequired,
+		AdminRequired:   adminRequired,
+		ManagerRequired: managerRequired,
+		Auth:            authMod,
+		Users:           usersMod,
+		Departments:     deptMod,
+		Attendance:      attMod,
+		Notes:           noteMod,
+		Stats:           statsMod,
+		Leave:           leaveMod,
+		WorkCalendar:    workCalMod,
+		Audit:           auditMod,
+		Scheduler:       schedulerMod,
+		Timesheets:      timesheetMod,
+		Roles:           rbacMod,
+		Offboarding:     offboardingMod,
 	}
 }
@@ -4,12 +4,18 @@ import (
 	"time-attendance-be/internal/app/health"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 )
 
 func RegisterRoutes(app *fiber.App, c *Container) {
 	// Health
 	health.Register(app)
 
+	// Mock identity provider (development only)
+	if c.MockIdP != nil {
+		app.All(c.MockIdP.BasePath()+"/*", adaptor.HTTPHandler(c.MockIdP))
+	}
+
 	v1 := app.Group("/api/v1")
 
 	// Auth
@@ -17,15 +23,29 @@ func RegisterRoutes(app *fiber.App, c *Container) {
 
 	// Me
 	c.Users.RegisterMe(v1, c.AuthRequired.Handle)
+	c.Auth.RegisterMe(v1, c.AuthRequired.Handle)
+	c.Roles.RegisterMe(v1, c.AuthRequired.Handle)
 
 	// User features
 	c.Attendance.RegisterMe(v1, c.AuthRequired.Handle)
 	c.Notes.RegisterMe(v1, c.AuthRequired.Handle)
 	c.Stats.RegisterMe(v1, c.AuthRequired.Handle)
 	c.Leave.RegisterMe(v1, c.AuthRequired.Handle)
-
-	// Admin
+	c.WorkCalendar.RegisterMe(v1, c.AuthRequired.Handle)
+	c.Timesheets.RegisterMe(v1, c.AuthRequired.Handle)
+
+	// Manager area: department managers, limited to the departments they manage
+	manager := v1.Group("/manager", c.AuthRequired.Handle, c.ManagerRequired.Handle)
+	c.Departments.RegisterManager(manager)
+	c.Attendance.RegisterManager(manager)
+	c.Leave.RegisterManager(manager)
+	c.Notes.RegisterManager(manager)
+	c.Stats.RegisterManager(manager)
+	c.Timesheets.RegisterManager(manager)
+
+	// Admin area: any permission gets in, each route checks its own
 	admin := v1.Group("/admin", c.AuthRequired.Handle, c.AdminRequired.Handle)
+	c.Auth.RegisterAdmin(admin)
 	c.Users.RegisterAdmin(admin)
 	c.Departments.RegisterAdmin(admin)
 	c.Attendance.RegisterAdmin(admin)
@@ -33,4 +53,8 @@ func RegisterRoutes(app *fiber.App, c *Container) {
 	c.Leave.RegisterAdmin(admin)
 	c.WorkCalendar.RegisterAdmin(admin)
 	c.Audit.RegisterAdmin(admin)
+	c.Scheduler.RegisterAdmin(admin)
+	c.Timesheets.RegisterAdmin(admin)
+	c.Roles.RegisterAdmin(admin)
+	c.Offboarding.RegisterAdmin(admin)
 }
@@ -0,0 +1,131 @@
+package authx
+
+import "strings"
+
+// APIKeyPrefix marks a bearer token as an API key rather than a JWT
+const APIKeyPrefix = "tak_"
+
+// APIKey is an authenticated API key as seen by the auth middleware
+type APIKey struct {
+	ID        uint
+	UserID    uint // owner (personal) or creating admin (service); the request acts as this user
+	Service   bool // service keys may only call admin routes
+	Scopes    []string
+	TwoFactor bool // created from a session that passed 2FA
+}
+
+// API key scopes. A :write scope includes the matching :read scope.
+const (
+	ScopeAttendanceRead  = "attendance:read"
+	ScopeAttendanceWrite = "attendance:write"
+	ScopeLeaveRead       = "leave:read"
+	ScopeLeaveWrite      = "leave:write"
+	ScopeTimesheetsRead  = "timesheets:read"
+	ScopeTimesheetsWrite = "timesheets:write"
+	ScopeUsersRead       = "users:read"
+	ScopeUsersWrite      = "users:write"
+	ScopeCalendarRead    = "calendar:read"
+	ScopeCalendarWrite   = "calendar:write"
+	ScopeNotesRead       = "notes:read"
+	ScopeNotesWrite      = "notes:write"
+	ScopeReportsExport   = "reports:export"
+)
+
+// AllScopes lists every scope with a short description, for the key creation form
+var AllScopes = []struct {
+	Scope       string
+	Description string
+}{
+	{ScopeAttendanceRead, "Read attendance sessions"},
+	{ScopeAttendanceWrite, "Check in/out and edit attendance sessions"},
+	{ScopeLeaveRead, "Read leave summaries, grants and policies"},
+	{ScopeLeaveWrite, "Grant, adjust and recalculate leave"},
+	{ScopeTimesheetsRead, "Read monthly timesheets"},
+	{ScopeTimesheetsWrite, "Confirm, dispute and approve timesheets"},
+	{ScopeUsersRead, "Read users and departments"},
+	{ScopeUsersWrite, "Create and edit users and departments"},
+	{ScopeCalendarRead, "Read the work calendar"},
+	{ScopeCalendarWrite, "Edit the work calendar"},
+	{ScopeNotesRead, "Read notes"},
+	{ScopeNotesWrite, "Write notes"},
+	{ScopeReportsExport, "Export attendance and read statistics and leave summaries"},
+}
+
+// IsScope reports whether s is a known scope
+func IsScope(s string) bool {
+	for _, sc := range AllScopes {
+		if sc.Scope == s {
+			return true
+		}
+	}
+	return false
+}
+
+// scopedRoutes maps path prefixes to a resource; GET needs <resource>:read, anything else
+// <resource>:write. Routes not listed here (sessions, passwords, 2FA, API keys, audit,
+// scheduler, security settings) can't be called with an API key.
+var scopedRoutes = []struct {
+	prefix   string
+	resource string
+}{
+	{"/api/v1/attendance", "attendance"},
+	{"/api/v1/admin/attendance", "attendance"},
+	{"/api/v1/me/leave", "leave"},
+	{"/api/v1/admin/leave", "leave"},
+	{"/api/v1/me/timesheets", "timesheets"},
+	{"/api/v1/admin/timesheets", "timesheets"},
+	{"/api/v1/me", "users"}, // exact: profile
+	{"/api/v1/admin/users", "users"},
+	{"/api/v1/admin/departments", "users"},
+	{"/api/v1/calendar", "calendar"},
+	{"/api/v1/admin/work-calendar", "calendar"},
+	{"/api/v1/notes", "notes"},
+}
+
+// exportRoutes are reachable (GET only) with reports:export
+var exportRoutes = []string{
+	"/api/v1/admin/attendance/export",
+	"/api/v1/stats",
+	"/api/v1/admin/overview",
+	"/api/v1/admin/leave/summaries",
+}
+
+// ScopesAllow reports whether an API key with scopes may call method + path
+func ScopesAllow(scopes []string, method, path string) bool {
+	path = strings.TrimRight(path, "/")
+	if method == "GET" && hasScope(scopes, ScopeReportsExport) {
+		for _, p := range exportRoutes {
+			if matchPrefix(path, p) {
+				return true
+			}
+		}
+	}
+
+	for _, r := range scopedRoutes {
+		match := matchPrefix(path, r.prefix)
+		if r.prefix == "/api/v1/me" {
+			match = path == r.prefix
+		}
+		if !match {
+			continue
+		}
+		if method == "GET" || method == "HEAD" {
+			return hasScope(scopes, r.resource+":read") || hasScope(scopes, r.resource+":write")
+		}
+		return hasScope(scopes, r.resource+":write")
+	}
+	return false
+}
+
+func matchPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func hasScope(scopes []string, s string) bool {
+	for _, sc := range scopes {
+		if sc == s {
+			return true
+		}
+	}
+	return false
+}
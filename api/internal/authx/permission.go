@@ -0,0 +1,242 @@
+package authx
+
+import (
+	"sort"
+
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Permissions guarding the admin API. Roles are named sets of these.
+const (
+	PermUsersRead         = "users.read"
+	PermUsersWrite        = "users.write"
+	PermDepartmentsManage = "departments.manage"
+	PermAttendanceRead    = "attendance.read"
+	PermAttendanceWrite   = "attendance.write"
+	PermAttendanceExport  = "attendance.export"
+	PermLeaveRead         = "leave.read"
+	PermLeaveManage       = "leave.manage"
+	PermLeavePolicy       = "leave.policy"
+	PermTimesheetsRead    = "timesheets.read"
+	PermTimesheetsApprove = "timesheets.approve"
+	PermCalendarManage    = "calendar.manage"
+	PermStatsRead         = "stats.read"
+	PermAuditRead         = "audit.read"
+	PermSchedulerManage   = "scheduler.manage"
+	PermSecurityManage    = "security.manage"
+	PermRolesManage       = "roles.manage"
+)
+
+// AllPermissions lists every permission with a short description, for the role editor.
+// Scopable permissions can be granted for single departments; the others only take
+// effect from company-wide assignments.
+var AllPermissions = []struct {
+	Permission  string
+	Description string
+	Scopable    bool
+}{
+	{PermUsersRead, "View users", true},
+	{PermUsersWrite, "Create, edit and delete users", false},
+	{PermDepartmentsManage, "Manage departments", false},
+	{PermAttendanceRead, "View attendance sessions", true},
+	{PermAttendanceWrite, "Create, edit and close attendance sessions", false},
+	{PermAttendanceExport, "Export attendance", true},
+	{PermLeaveRead, "View leave summaries, grants and comp-off", false},
+	{PermLeaveManage, "Grant, adjust and recalculate leave, approve comp-off", false},
+	{PermLeavePolicy, "Edit accrual and birthday leave policies", false},
+	{PermTimesheetsRead, "View monthly timesheets", true},
+	{PermTimesheetsApprove, "Approve, reject and reopen timesheets, resolve corrections", false},
+	{PermCalendarManage, "Edit the work calendar and leave rules", false},
+	{PermStatsRead, "View company statistics", false},
+	{PermAuditRead, "Read the audit log", false},
+	{PermSchedulerManage, "Inspect and trigger scheduled jobs", false},
+	{PermSecurityManage, "Security policy, 2FA resets, unlocks, login history and service keys", false},
+	{PermRolesManage, "Manage roles and role assignments", false},
+}
+
+// IsPermission reports whether p is a known permission
+func IsPermission(p string) bool {
+	for _, ap := range AllPermissions {
+		if ap.Permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// IsScopable reports whether p can be granted for single departments
+func IsScopable(p string) bool {
+	for _, ap := range AllPermissions {
+		if ap.Permission == p {
+			return ap.Scopable
+		}
+	}
+	return false
+}
+
+// Grants are a user's effective permissions, each held company-wide or for some departments
+type Grants struct {
+	all   map[string]bool
+	depts map[string]map[uint]bool
+}
+
+func NewGrants() *Grants {
+	return &Grants{all: map[string]bool{}, depts: map[string]map[uint]bool{}}
+}
+
+// Add grants perm company-wide (departmentID nil) or for one department. Department
+// grants of permissions that aren't scopable are ignored.
+func (g *Grants) Add(perm string, departmentID *uint) {
+	if departmentID == nil {
+		g.all[perm] = true
+		return
+	}
+	if !IsScopable(perm) {
+		return
+	}
+	if g.depts[perm] == nil {
+		g.depts[perm] = map[uint]bool{}
+	}
+	g.depts[perm][*departmentID] = true
+}
+
+// AddAll grants every permission company-wide
+func (g *Grants) AddAll() {
+	for _, ap := range AllPermissions {
+		g.all[ap.Permission] = true
+	}
+}
+
+// Has reports whether perm is held for at least one department
+func (g *Grants) Has(perm string) bool {
+	return g.all[perm] || len(g.depts[perm]) > 0
+}
+
+// Empty reports whether no permission is held at all
+func (g *Grants) Empty() bool {
+	return len(g.all) == 0 && len(g.depts) == 0
+}
+
+// Covers reports whether g holds every permission of other, for at least the same departments
+func (g *Grants) Covers(other *Grants) bool {
+	for perm := range other.all {
+		if !g.all[perm] {
+			return false
+		}
+	}
+	for perm, depts := range other.depts {
+		if g.all[perm] {
+			continue
+		}
+		for id := range depts {
+			if !g.depts[perm][id] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Scope returns the departments perm is held for
+func (g *Grants) Scope(perm string) Scope {
+	if g.all[perm] {
+		return Scope{All: true}
+	}
+	ids := make([]uint, 0, len(g.depts[perm]))
+	for id := range g.depts[perm] {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return Scope{Departments: ids}
+}
+
+// List returns the held permissions in catalog order, each with its scope
+func (g *Grants) List() []GrantedPermission {
+	var out []GrantedPermission
+	for _, ap := range AllPermissions {
+		if g.Has(ap.Permission) {
+			out = append(out, GrantedPermission{Permission: ap.Permission, Scope: g.Scope(ap.Permission)})
+		}
+	}
+	return out
+}
+
+// GrantedPermission is one entry of Grants.List
+type GrantedPermission struct {
+	Permission string
+	Scope      Scope
+}
+
+// Scope is the set of departments a permission covers
+type Scope struct {
+	All         bool
+	Departments []uint
+}
+
+// Allows reports whether the scope covers departmentID. Users without a department are
+// only covered by company-wide grants.
+func (s Scope) Allows(departmentID *uint) bool {
+	if s.All {
+		return true
+	}
+	if departmentID == nil {
+		return false
+	}
+	for _, id := range s.Departments {
+		if id == *departmentID {
+			return true
+		}
+	}
+	return false
+}
+
+// Narrow applies the scope to a department filter. A requested department must be covered;
+// the returned list restricts results to the scope's departments (nil = no restriction).
+func (s Scope) Narrow(requested *uint) ([]uint, error) {
+	if s.All {
+		return nil, nil
+	}
+	if requested != nil && !s.Allows(requested) {
+		return nil, response.Forbidden("You can only view data of your departments")
+	}
+	if s.Departments == nil {
+		return []uint{}, nil
+	}
+	return s.Departments, nil
+}
+
+// Require is route middleware that lets the request through when the user holds any of
+// perms (for at least one department; handlers narrow department-scoped grants).
+func Require(perms ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		u := GetUser(c)
+		if u == nil {
+			return response.Unauthorized("Unauthorized")
+		}
+		g, err := u.Grants(c.Context())
+		if err != nil {
+			return response.Internal(err)
+		}
+		for _, p := range perms {
+			if g.Has(p) {
+				return c.Next()
+			}
+		}
+		return response.Forbidden("You do not have permission to do this")
+	}
+}
+
+// PermissionScope returns the departments the current user holds perm for
+func PermissionScope(c *fiber.Ctx, perm string) (Scope, error) {
+	u := GetUser(c)
+	if u == nil {
+		return Scope{}, response.Unauthorized("Unauthorized")
+	}
+	g, err := u.Grants(c.Context())
+	if err != nil {
+		return Scope{}, response.Internal(err)
+	}
+	return g.Scope(perm), nil
+}
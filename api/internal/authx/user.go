@@ -1,12 +1,52 @@
 package authx
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 // User represents the essential user information stored in the context.
 // This is a stripped-down version to break import cycles.
 type User struct {
-	ID   uint
-	Role string
+	ID        uint
+	Role      string
+	SessionID uint // login session of the access token (0 for API key requests)
+	TwoFactor bool // the session passed the TOTP login step
+	APIKeyID  uint // set when the request authenticated with an API key instead of a session
+
+	// ManagedDepartments are the departments the user manages; set on /manager routes
+	ManagedDepartments []uint
+
+	grants     *Grants
+	loadGrants func(ctx context.Context) (*Grants, error)
+}
+
+// SetGrantsLoader sets how the user's permissions are loaded; they are only fetched
+// when a route or handler first asks for them.
+func (u *User) SetGrantsLoader(load func(ctx context.Context) (*Grants, error)) {
+	u.loadGrants = load
+}
+
+// ManagerScope returns the departments the user manages as a Scope
+func (u *User) ManagerScope() Scope {
+	return Scope{Departments: u.ManagedDepartments}
+}
+
+// Grants returns the user's permissions (none when no loader is set)
+func (u *User) Grants(ctx context.Context) (*Grants, error) {
+	if u.grants != nil {
+		return u.grants, nil
+	}
+	if u.loadGrants == nil {
+		return NewGrants(), nil
+	}
+	g, err := u.loadGrants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u.grants = g
+	return g, nil
 }
 
 const CtxUserKey = "auth_user"
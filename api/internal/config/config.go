@@ -17,6 +17,10 @@ type Config struct {
 	DB               DBConfig
 	CORSAllowOrigins string
 	Auth             AuthConfig
+	Leave            LeaveConfig
+	Scheduler        SchedulerConfig
+	Mail             MailConfig
+	OIDC             OIDCConfig
 }
 
 type DBConfig struct {
@@ -38,6 +42,50 @@ type AuthConfig struct {
 	AuthRateLimitEnabled bool
 	AuthRateLimitMax     int
 	AuthRateLimitWindow  time.Duration
+	PasswordResetTTL     time.Duration // how long an emailed reset link stays valid
+	PasswordResetURL     string        // frontend page the reset link points to (?token= is appended)
+	TOTPIssuer           string        // issuer shown in authenticator apps
+	LockoutThreshold     int           // consecutive failed logins that lock the account
+	LockoutBase          time.Duration // first lock; each further lock doubles it
+	LockoutMax           time.Duration
+	IPFailureMax         int // failed logins from one IP within IPFailureWindow before it is blocked
+	IPFailureWindow      time.Duration
+	LoginHistoryDays     int // login attempts older than this are purged
+}
+
+type MailConfig struct {
+	Driver       string // "smtp" or "outbox"
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+	OutboxDir    string // where the outbox driver writes .eml files
+}
+
+// OIDCConfig configures single sign-on with the company identity provider
+type OIDCConfig struct {
+	Enabled             bool
+	IssuerURL           string // discovery is read from IssuerURL + /.well-known/openid-configuration
+	ClientID            string
+	ClientSecret        string
+	RedirectURL         string // this API's callback: .../api/v1/auth/oidc/callback
+	Scopes              string // space separated
+	PostLoginURL        string // frontend page the browser lands on after the callback
+	AutoProvision       bool   // create unknown users on first login
+	DefaultRole         string // role of auto-provisioned users
+	DefaultDepartmentID int    // department of auto-provisioned users (0 = none)
+	AllowedDomains      string // comma separated email domains allowed to sign in (empty = any)
+	MockIdP             bool   // serve a mock identity provider at IssuerURL (never in production)
+}
+
+type LeaveConfig struct {
+	CompOffExpiryDays int
+}
+
+type SchedulerConfig struct {
+	InstanceID string        // identity used for the scheduler lease (defaults to hostname = pod name)
+	LeaseTTL   time.Duration // how long a dead leader blocks failover
 }
 
 // Load builds a Config instance by starting with the hard-coded defaults and then overriding
@@ -101,6 +149,51 @@ func Load() *Config {
 	setBool("AUTH_AUTH_RATE_LIMIT_ENABLED", &cfg.Auth.AuthRateLimitEnabled)
 	setInt("AUTH_AUTH_RATE_LIMIT_MAX", &cfg.Auth.AuthRateLimitMax)
 	setDur("AUTH_AUTH_RATE_LIMIT_WINDOW", &cfg.Auth.AuthRateLimitWindow)
+	setDur("AUTH_PASSWORD_RESET_TTL", &cfg.Auth.PasswordResetTTL)
+	setStr("AUTH_PASSWORD_RESET_URL", &cfg.Auth.PasswordResetURL)
+	setStr("AUTH_TOTP_ISSUER", &cfg.Auth.TOTPIssuer)
+	setInt("AUTH_LOCKOUT_THRESHOLD", &cfg.Auth.LockoutThreshold)
+	setDur("AUTH_LOCKOUT_BASE", &cfg.Auth.LockoutBase)
+	setDur("AUTH_LOCKOUT_MAX", &cfg.Auth.LockoutMax)
+	setInt("AUTH_IP_FAILURE_MAX", &cfg.Auth.IPFailureMax)
+	setDur("AUTH_IP_FAILURE_WINDOW", &cfg.Auth.IPFailureWindow)
+	setInt("AUTH_LOGIN_HISTORY_DAYS", &cfg.Auth.LoginHistoryDays)
+
+	// Mail
+	setStr("MAIL_DRIVER", &cfg.Mail.Driver)
+	setStr("MAIL_FROM", &cfg.Mail.From)
+	setStr("MAIL_SMTP_HOST", &cfg.Mail.SMTPHost)
+	setInt("MAIL_SMTP_PORT", &cfg.Mail.SMTPPort)
+	setStr("MAIL_SMTP_USER", &cfg.Mail.SMTPUser)
+	setStr("MAIL_SMTP_PASSWORD", &cfg.Mail.SMTPPassword)
+	setStr("MAIL_OUTBOX_DIR", &cfg.Mail.OutboxDir)
+
+	// OIDC
+	setBool("OIDC_ENABLED", &cfg.OIDC.Enabled)
+	setStr("OIDC_ISSUER_URL", &cfg.OIDC.IssuerURL)
+	setStr("OIDC_CLIENT_ID", &cfg.OIDC.ClientID)
+	setStr("OIDC_CLIENT_SECRET", &cfg.OIDC.ClientSecret)
+	setStr("OIDC_REDIRECT_URL", &cfg.OIDC.RedirectURL)
+	setStr("OIDC_SCOPES", &cfg.OIDC.Scopes)
+	setStr("OIDC_POST_LOGIN_URL", &cfg.OIDC.PostLoginURL)
+	setBool("OIDC_AUTO_PROVISION", &cfg.OIDC.AutoProvision)
+	setStr("OIDC_DEFAULT_ROLE", &cfg.OIDC.DefaultRole)
+	setInt("OIDC_DEFAULT_DEPARTMENT_ID", &cfg.OIDC.DefaultDepartmentID)
+	setStr("OIDC_ALLOWED_DOMAINS", &cfg.OIDC.AllowedDomains)
+	setBool("OIDC_MOCK_IDP", &cfg.OIDC.MockIdP)
+
+	// Leave
+	setInt("LEAVE_COMP_OFF_EXPIRY_DAYS", &cfg.Leave.CompOffExpiryDays)
+
+	// Scheduler
+	setStr("SCHEDULER_INSTANCE_ID", &cfg.Scheduler.InstanceID)
+	setDur("SCHEDULER_LEASE_TTL", &cfg.Scheduler.LeaseTTL)
+	if cfg.Scheduler.InstanceID == "" {
+		if host, err := os.Hostname(); err == nil {
+			cfg.Scheduler.InstanceID = host
+		}
+		cfg.Scheduler.InstanceID = fmt.Sprintf("%s-%d", cfg.Scheduler.InstanceID, os.Getpid())
+	}
 
 	return &cfg
 }
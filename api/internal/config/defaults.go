@@ -35,6 +35,37 @@ func defaultConfig() Config {
 			AuthRateLimitEnabled: true,
 			AuthRateLimitMax:     20,
 			AuthRateLimitWindow:  60 * time.Second,
+			PasswordResetTTL:     30 * time.Minute,
+			PasswordResetURL:     "http://localhost:3000/reset-password",
+			TOTPIssuer:           "Time Attendance",
+			LockoutThreshold:     5,
+			LockoutBase:          time.Minute, // 1m, 2m, 4m, ... tối đa 1h
+			LockoutMax:           time.Hour,
+			IPFailureMax:         30,
+			IPFailureWindow:      15 * time.Minute,
+			LoginHistoryDays:     90,
+		},
+
+		Leave: LeaveConfig{
+			CompOffExpiryDays: 90, // Ngày nghỉ bù hết hạn sau 90 ngày kể từ ngày làm
+		},
+
+		Scheduler: SchedulerConfig{
+			LeaseTTL: 30 * time.Second, // Pod leader chết -> pod khác tiếp quản sau tối đa 30s
+		},
+
+		Mail: MailConfig{
+			Driver:    "outbox", // Dev: ghi mail ra file .eml thay vì gửi thật
+			From:      "Time Attendance <no-reply@local.test>",
+			SMTPPort:  587,
+			OutboxDir: "./tmp/outbox",
+		},
+
+		OIDC: OIDCConfig{
+			RedirectURL:  "http://localhost:8080/api/v1/auth/oidc/callback",
+			Scopes:       "openid email profile",
+			PostLoginURL: "http://localhost:3000/",
+			DefaultRole:  "user",
 		},
 	}
 }
@@ -1,23 +1,51 @@
 package middleware
 
 import (
+	"context"
+	"net/http"
+
 	"time-attendance-be/internal/authx"
 	"time-attendance-be/internal/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-type AdminRequired struct{}
+// TwoFactorPolicy tells whether admin routes need a session that passed 2FA (implemented by auth.Service)
+type TwoFactorPolicy interface {
+	AdminTwoFactorRequired(ctx context.Context) (bool, error)
+}
 
-func NewAdminRequired() *AdminRequired { return &AdminRequired{} }
+// AdminRequired guards the admin area: the user must hold at least one permission. Routes
+// then check their own permission with authx.Require.
+type AdminRequired struct {
+	policy TwoFactorPolicy
+}
+
+func NewAdminRequired(policy TwoFactorPolicy) *AdminRequired {
+	return &AdminRequired{policy: policy}
+}
 
 func (m *AdminRequired) Handle(c *fiber.Ctx) error {
 	u := authx.GetUser(c)
 	if u == nil {
 		return response.Unauthorized("Unauthorized")
 	}
-	if u.Role != "admin" {
+	grants, err := u.Grants(c.Context())
+	if err != nil {
+		return response.Internal(err)
+	}
+	if grants.Empty() {
 		return response.Forbidden("Forbidden")
 	}
+	if !u.TwoFactor && m.policy != nil {
+		required, err := m.policy.AdminTwoFactorRequired(c.Context())
+		if err != nil {
+			return response.Internal(err)
+		}
+		if required {
+			// Distinct code so the frontend can send the admin to 2FA setup / sign-in
+			return response.New("two_factor_required", "Two-factor authentication is required for admin access", http.StatusForbidden)
+		}
+	}
 	return c.Next()
 }
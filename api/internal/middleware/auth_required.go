@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 
 	"time-attendance-be/internal/authx"
@@ -13,14 +14,34 @@ import (
 	"gorm.io/gorm"
 )
 
+// SessionStore tells whether a login session is still active and whether it passed
+// two-factor authentication (implemented by auth.Service)
+type SessionStore interface {
+	SessionState(ctx context.Context, sessionID uint) (active, twoFactor bool, err error)
+}
+
+// APIKeyStore authenticates API keys (implemented by auth.Service). Returns nil for unknown,
+// revoked or expired keys.
+type APIKeyStore interface {
+	AuthenticateAPIKey(ctx context.Context, key, ip string) (*authx.APIKey, error)
+}
+
+// PermissionStore loads a user's effective permissions (implemented by rbac.Service)
+type PermissionStore interface {
+	Grants(ctx context.Context, userID uint, role string) (*authx.Grants, error)
+}
+
 type AuthRequired struct {
-	cfg    *config.Config
-	jwtMgr *platformauth.Manager
-	users  *user.Repo
+	cfg      *config.Config
+	jwtMgr   *platformauth.Manager
+	users    *user.Repo
+	sessions SessionStore
+	apiKeys  APIKeyStore
+	perms    PermissionStore
 }
 
-func NewAuthRequired(cfg *config.Config, jwtMgr *platformauth.Manager, users *user.Repo) *AuthRequired {
-	return &AuthRequired{cfg: cfg, jwtMgr: jwtMgr, users: users}
+func NewAuthRequired(cfg *config.Config, jwtMgr *platformauth.Manager, users *user.Repo, sessions SessionStore, apiKeys APIKeyStore, perms PermissionStore) *AuthRequired {
+	return &AuthRequired{cfg: cfg, jwtMgr: jwtMgr, users: users, sessions: sessions, apiKeys: apiKeys, perms: perms}
 }
 
 func (m *AuthRequired) Handle(c *fiber.Ctx) error {
@@ -36,23 +57,80 @@ func (m *AuthRequired) Handle(c *fiber.Ctx) error {
 	if token == "" {
 		return response.Unauthorized("Unauthorized")
 	}
+	if strings.HasPrefix(token, authx.APIKeyPrefix) {
+		return m.handleAPIKey(c, token)
+	}
 
+	// Only session-bound access tokens; refresh, two-factor and untyped (older) tokens are refused
 	claims, err := m.jwtMgr.VerifyToken(token)
-	if err != nil {
+	if err != nil || claims.TokenType != platformauth.TokenTypeAccess || claims.SessionID == 0 {
 		return response.Unauthorized("Unauthorized")
 	}
 
-	u, err := m.users.GetByID(c.Context(), uint(claims.UserID))
+	// Revoked sessions (logout, revoked from another device, refresh token reuse) end immediately
+	active, twoFactor, err := m.sessions.SessionState(c.Context(), claims.SessionID)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return response.Unauthorized("Unauthorized")
-		}
 		return response.Internal(err)
 	}
-	if u.Status != "active" {
-		return response.Forbidden("Account disabled")
+	if !active {
+		return response.Unauthorized("Session has ended")
+	}
+
+	u, err := m.activeUser(c, uint(claims.UserID))
+	if err != nil {
+		return err
+	}
+
+	m.setUser(c, &authx.User{ID: u.ID, Role: u.Role, SessionID: claims.SessionID, TwoFactor: twoFactor})
+	return c.Next()
+}
+
+// handleAPIKey authenticates a request made with an API key. The request acts as the key's
+// user, limited to the routes the key's scopes cover; service keys only reach admin routes.
+func (m *AuthRequired) handleAPIKey(c *fiber.Ctx, token string) error {
+	key, err := m.apiKeys.AuthenticateAPIKey(c.Context(), token, c.IP())
+	if err != nil {
+		return response.Internal(err)
+	}
+	if key == nil {
+		return response.Unauthorized("Invalid API key")
+	}
+	if key.Service && !strings.HasPrefix(c.Path(), "/api/v1/admin/") {
+		return response.Forbidden("Service keys can only call admin endpoints")
+	}
+	if !authx.ScopesAllow(key.Scopes, c.Method(), c.Path()) {
+		return response.Forbidden("API key scopes do not allow this endpoint")
 	}
 
-	c.Locals(authx.CtxUserKey, &authx.User{ID: u.ID, Role: u.Role})
+	u, err := m.activeUser(c, key.UserID)
+	if err != nil {
+		return err
+	}
+
+	m.setUser(c, &authx.User{ID: u.ID, Role: u.Role, TwoFactor: key.TwoFactor, APIKeyID: key.ID})
 	return c.Next()
 }
+
+// setUser stores the principal; its permissions are loaded on first use
+func (m *AuthRequired) setUser(c *fiber.Ctx, au *authx.User) {
+	if m.perms != nil {
+		au.SetGrantsLoader(func(ctx context.Context) (*authx.Grants, error) {
+			return m.perms.Grants(ctx, au.ID, au.Role)
+		})
+	}
+	c.Locals(authx.CtxUserKey, au)
+}
+
+func (m *AuthRequired) activeUser(c *fiber.Ctx, id uint) (*user.User, error) {
+	u, err := m.users.GetByID(c.Context(), id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, response.Unauthorized("Unauthorized")
+		}
+		return nil, response.Internal(err)
+	}
+	if u.Status != "active" {
+		return nil, response.Forbidden("Account disabled")
+	}
+	return u, nil
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ManagerStore tells which departments a user manages (implemented by department.Service)
+type ManagerStore interface {
+	ManagedDepartments(ctx context.Context, userID uint) ([]uint, error)
+}
+
+// ManagerRequired guards the /manager area: the user must manage at least one department.
+// Handlers limit their queries to authx.User.ManagedDepartments.
+type ManagerRequired struct {
+	store ManagerStore
+}
+
+func NewManagerRequired(store ManagerStore) *ManagerRequired {
+	return &ManagerRequired{store: store}
+}
+
+func (m *ManagerRequired) Handle(c *fiber.Ctx) error {
+	u := authx.GetUser(c)
+	if u == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	ids, err := m.store.ManagedDepartments(c.Context(), u.ID)
+	if err != nil {
+		return response.Internal(err)
+	}
+	if len(ids) == 0 {
+		return response.Forbidden("You do not manage a department")
+	}
+	u.ManagedDepartments = ids
+	return c.Next()
+}
@@ -0,0 +1,27 @@
+package attendance
+
+import "time"
+
+// EventSessionChanged is published after a session is created, closed, edited or deleted
+const EventSessionChanged = "attendance.session_changed"
+
+// Session change actions
+const (
+	SessionCheckedIn  = "CHECKED_IN"
+	SessionCheckedOut = "CHECKED_OUT"
+	SessionCreated    = "CREATED" // manual entry by an admin
+	SessionUpdated    = "UPDATED"
+	SessionClosed     = "CLOSED" // closed by an admin
+	SessionDeleted    = "DELETED"
+)
+
+// SessionChanged tells subscribers that a user's attendance for WorkDate changed
+type SessionChanged struct {
+	SessionID uint
+	UserID    uint
+	WorkDate  time.Time
+	Action    string
+	Status    string // session status after the change (empty when deleted)
+}
+
+func (SessionChanged) Name() string { return EventSessionChanged }
@@ -2,6 +2,7 @@ package attendance
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"time"
 	"time-attendance-be/internal/authx"
@@ -105,6 +106,44 @@ func (h *Handler) CheckOut(c *fiber.Ctx) error {
 // Admin handlers
 // GET /api/v1/admin/attendance?from=&to=&userId=&departmentId=&status=
 func (h *Handler) ListAdmin(c *fiber.Ctx) error {
+	filter := parseAdminListFilter(c)
+	scope, err := authx.PermissionScope(c, authx.PermAttendanceRead)
+	if err != nil {
+		return err
+	}
+	if err := scopeFilter(scope, &filter); err != nil {
+		return err
+	}
+
+	res, err := h.svc.ListAdmin(c.Context(), filter)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	return response.OK(c, res)
+}
+
+// GET /api/v1/manager/attendance?from=&to=&userId=&departmentId=&status=
+// Same listing as the admin one, limited to the departments the user manages
+func (h *Handler) ListManager(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	filter := parseAdminListFilter(c)
+	if err := scopeFilter(a.ManagerScope(), &filter); err != nil {
+		return err
+	}
+
+	res, err := h.svc.ListAdmin(c.Context(), filter)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	return response.OK(c, res)
+}
+
+func parseAdminListFilter(c *fiber.Ctx) AdminListFilter {
 	filter := AdminListFilter{
 		From: c.Query("from"),
 		To:   c.Query("to"),
@@ -127,13 +166,17 @@ func (h *Handler) ListAdmin(c *fiber.Ctx) error {
 	if status := c.Query("status"); status != "" {
 		filter.Status = &status
 	}
+	return filter
+}
 
-	res, err := h.svc.ListAdmin(c.Context(), filter)
+// scopeFilter limits a listing to the departments of scope
+func scopeFilter(scope authx.Scope, filter *AdminListFilter) error {
+	ids, err := scope.Narrow(filter.DepartmentID)
 	if err != nil {
-		return response.Internal(err)
+		return err
 	}
-
-	return response.OK(c, res)
+	filter.DepartmentIDs = ids
+	return nil
 }
 
 // POST /api/v1/admin/attendance
@@ -162,7 +205,9 @@ func (h *Handler) CreateManual(c *fiber.Ctx) error {
 		CheckOutAt: req.CheckOutAt,
 		Reason:     req.Reason,
 	})
-	if err != nil {
+	if errors.Is(err, ErrMonthLocked) {
+		return response.Conflict(err.Error())
+	} else if err != nil {
 		return response.Validation(err.Error(), nil)
 	}
 
@@ -202,7 +247,9 @@ func (h *Handler) UpdateSession(c *fiber.Ctx) error {
 		UserID:     req.UserID,
 		WorkDate:   req.WorkDate,
 	})
-	if err != nil {
+	if errors.Is(err, ErrMonthLocked) {
+		return response.Conflict(err.Error())
+	} else if err != nil {
 		return response.Validation(err.Error(), nil)
 	}
 
@@ -232,7 +279,9 @@ func (h *Handler) CloseSession(c *fiber.Ctx) error {
 	}
 
 	session, err := h.svc.CloseSession(c.Context(), uint(id), req.CheckOutAt, req.Reason)
-	if err != nil {
+	if errors.Is(err, ErrMonthLocked) {
+		return response.Conflict(err.Error())
+	} else if err != nil {
 		return response.Validation(err.Error(), nil)
 	}
 
@@ -247,7 +296,9 @@ func (h *Handler) DeleteSession(c *fiber.Ctx) error {
 		return response.Validation("Invalid session ID", nil)
 	}
 
-	if err := h.svc.DeleteSession(c.Context(), uint(id)); err != nil {
+	if err := h.svc.DeleteSession(c.Context(), uint(id)); errors.Is(err, ErrMonthLocked) {
+		return response.Conflict(err.Error())
+	} else if err != nil {
 		return response.Internal(err)
 	}
 
@@ -257,27 +308,13 @@ func (h *Handler) DeleteSession(c *fiber.Ctx) error {
 
 // GET /api/v1/admin/attendance/export?from=&to=&userId=&departmentId=&status=&format=csv
 func (h *Handler) Export(c *fiber.Ctx) error {
-	filter := AdminListFilter{
-		From: c.Query("from"),
-		To:   c.Query("to"),
-	}
-
-	if userIdStr := c.Query("userId"); userIdStr != "" {
-		var userId uint
-		if _, err := fmt.Sscanf(userIdStr, "%d", &userId); err == nil {
-			filter.UserID = &userId
-		}
-	}
-
-	if deptIdStr := c.Query("departmentId"); deptIdStr != "" {
-		var deptId uint
-		if _, err := fmt.Sscanf(deptIdStr, "%d", &deptId); err == nil {
-			filter.DepartmentID = &deptId
-		}
+	filter := parseAdminListFilter(c)
+	scope, err := authx.PermissionScope(c, authx.PermAttendanceExport)
+	if err != nil {
+		return err
 	}
-
-	if status := c.Query("status"); status != "" {
-		filter.Status = &status
+	if err := scopeFilter(scope, &filter); err != nil {
+		return err
 	}
 
 	format := c.Query("format", "csv")
@@ -48,6 +48,27 @@ func (r *Repo) SumDayUnitByRange(ctx context.Context, userID uint, from, to stri
 	return total, err
 }
 
+// UserDayUnit is the closed-session units a user worked on one working day
+type UserDayUnit struct {
+	UserID   uint
+	WorkDate string // YYYY-MM-DD
+	DayUnit  float64
+}
+
+// SumDayUnitByUserDay is SumDayUnitByRange for all users at once, grouped per user and day
+func (r *Repo) SumDayUnitByUserDay(ctx context.Context, from, to string) ([]UserDayUnit, error) {
+	var rows []UserDayUnit
+	err := r.db.WithContext(ctx).
+		Model(&Session{}).
+		Joins("INNER JOIN work_calendar wc ON DATE(attendance_sessions.work_date) = wc.work_date").
+		Where("attendance_sessions.status = 'CLOSED' AND DATE(attendance_sessions.work_date) >= ? AND DATE(attendance_sessions.work_date) <= ?", from, to).
+		Where("wc.is_working_day = ?", true).
+		Select("attendance_sessions.user_id AS user_id, DATE_FORMAT(attendance_sessions.work_date, '%Y-%m-%d') AS work_date, COALESCE(SUM(attendance_sessions.day_unit), 0) AS day_unit").
+		Group("attendance_sessions.user_id, DATE_FORMAT(attendance_sessions.work_date, '%Y-%m-%d')").
+		Scan(&rows).Error
+	return rows, err
+}
+
 func (r *Repo) FindLatestOpen(userID uint) (*Session, error) {
 	var s Session
 	if err := r.db.Where("user_id = ? AND status = 'OPEN'", userID).
@@ -57,6 +78,16 @@ func (r *Repo) FindLatestOpen(userID uint) (*Session, error) {
 	return &s, nil
 }
 
+// ListOpenByUser returns the user's sessions that have no check-out yet
+func (r *Repo) ListOpenByUser(ctx context.Context, userID uint) ([]Session, error) {
+	var sessions []Session
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND status = 'OPEN'", userID).
+		Order("work_date asc").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
 func (r *Repo) Save(s *Session) error { return r.db.Save(s).Error }
 
 // GetSessionsWithDayUnitZero returns all closed sessions with day_unit = 0
@@ -70,6 +101,26 @@ func (r *Repo) GetSessionsWithDayUnitZero(ctx context.Context, fromDate, toDate
 	return sessions, err
 }
 
+// ListClosedByRange returns CLOSED sessions of all users in [from, to]
+func (r *Repo) ListClosedByRange(ctx context.Context, from, to string) ([]Session, error) {
+	var sessions []Session
+	err := r.db.WithContext(ctx).
+		Where("status = 'CLOSED' AND DATE(work_date) >= ? AND DATE(work_date) <= ?", from, to).
+		Order("work_date ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// ListByRange returns the sessions of all users in [from, to], any status
+func (r *Repo) ListByRange(ctx context.Context, from, to string) ([]Session, error) {
+	var sessions []Session
+	err := r.db.WithContext(ctx).
+		Where("DATE(work_date) >= ? AND DATE(work_date) <= ?", from, to).
+		Order("work_date ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
 // GetDatesWithoutAttendance returns dates in a range that don't have attendance sessions for a user
 // Excludes weekends (Saturday=6, Sunday=0)
 func (r *Repo) GetDatesWithoutAttendance(ctx context.Context, userID uint, fromDate, toDate time.Time) ([]time.Time, error) {
@@ -144,11 +195,12 @@ func (r *Repo) GetYearMonthWithAttendance(ctx context.Context) ([]struct {
 
 // Admin repo methods
 type AdminListFilter struct {
-	From         string
-	To           string
-	UserID       *uint
-	DepartmentID *uint
-	Status       *string
+	From          string
+	To            string
+	UserID        *uint
+	DepartmentID  *uint
+	DepartmentIDs []uint // when not nil, only these departments (department-scoped permissions)
+	Status        *string
 }
 
 type AdminSessionRow struct {
@@ -178,6 +230,9 @@ func (r *Repo) ListAdmin(ctx context.Context, filter AdminListFilter) ([]AdminSe
 	if filter.DepartmentID != nil {
 		query = query.Where("u.department_id = ?", *filter.DepartmentID)
 	}
+	if filter.DepartmentIDs != nil {
+		query = query.Where("u.department_id IN ?", filter.DepartmentIDs)
+	}
 	if filter.Status != nil {
 		query = query.Where("s.status = ?", *filter.Status)
 	}
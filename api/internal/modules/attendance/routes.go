@@ -1,6 +1,8 @@
 package attendance
 
 import (
+	"time-attendance-be/internal/authx"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -18,11 +20,14 @@ func (m *Module) RegisterMe(v1 fiber.Router, auth fiber.Handler) {
 
 func (m *Module) RegisterAdmin(admin fiber.Router) {
 	g := admin.Group("/attendance")
-	g.Get("", m.h.ListAdmin)
-	g.Get("/export", m.h.Export)
-	g.Post("", m.h.CreateManual)
-	g.Patch("/:id", m.h.UpdateSession)
-	g.Post("/:id/close", m.h.CloseSession)
-	g.Delete("/:id", m.h.DeleteSession)
+	g.Get("", authx.Require(authx.PermAttendanceRead), m.h.ListAdmin)
+	g.Get("/export", authx.Require(authx.PermAttendanceExport), m.h.Export)
+	g.Post("", authx.Require(authx.PermAttendanceWrite), m.h.CreateManual)
+	g.Patch("/:id", authx.Require(authx.PermAttendanceWrite), m.h.UpdateSession)
+	g.Post("/:id/close", authx.Require(authx.PermAttendanceWrite), m.h.CloseSession)
+	g.Delete("/:id", authx.Require(authx.PermAttendanceWrite), m.h.DeleteSession)
 }
 
+func (m *Module) RegisterManager(mgr fiber.Router) {
+	mgr.Get("/attendance", m.h.ListManager)
+}
@@ -8,6 +8,7 @@ import (
 	"time-attendance-be/internal/config"
 	"time-attendance-be/internal/modules/user"
 	"time-attendance-be/internal/pkg/clock"
+	"time-attendance-be/internal/platform/events"
 
 	"gorm.io/gorm"
 )
@@ -18,10 +19,26 @@ type LeaveUsageInfo struct {
 	IsUnpaid   bool
 }
 
+// LeaveRepo provides per-day leave allocation (implemented by leave.Service)
+type LeaveRepo interface {
+	GetLeaveUsageInfoByUserAndMonth(ctx context.Context, userID uint, year, month int) ([]LeaveUsageInfo, error)
+}
+
+// MonthLock reports whether a user's month is signed off (implemented by timesheet.Service)
+type MonthLock interface {
+	IsMonthLocked(ctx context.Context, userID uint, workDate time.Time) (bool, error)
+}
+
+// ErrMonthLocked is returned when changing a session in a signed-off month
+var ErrMonthLocked = errors.New("the timesheet of this month is signed off; reopen it before changing attendance")
+
 type Service struct {
 	cfg         *config.Config
 	attRepo     *Repo
 	userRepo    UserRepo
+	leaveRepo   LeaveRepo
+	monthLock   MonthLock
+	events      *events.Bus
 	clock       clock.Clock
 }
 
@@ -41,6 +58,48 @@ func (s *Service) SetUserRepo(repo UserRepo) {
 	s.userRepo = repo
 }
 
+func (s *Service) SetLeaveRepo(repo LeaveRepo) {
+	s.leaveRepo = repo
+}
+
+func (s *Service) SetEventBus(bus *events.Bus) {
+	s.events = bus
+}
+
+func (s *Service) SetMonthLock(lock MonthLock) {
+	s.monthLock = lock
+}
+
+// checkMonthLock returns ErrMonthLocked if the user's month of workDate is signed off
+func (s *Service) checkMonthLock(ctx context.Context, userID uint, workDate time.Time) error {
+	if s.monthLock == nil {
+		return nil
+	}
+	locked, err := s.monthLock.IsMonthLocked(ctx, userID, workDate)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrMonthLocked
+	}
+	return nil
+}
+
+// sessionChanged publishes a SessionChanged event for the session
+func (s *Service) sessionChanged(ctx context.Context, session *Session, action string) {
+	status := session.Status
+	if action == SessionDeleted {
+		status = ""
+	}
+	s.events.Publish(ctx, SessionChanged{
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		WorkDate:  session.WorkDate,
+		Action:    action,
+		Status:    status,
+	})
+}
+
 func (s *Service) GetToday(ctx context.Context, userID uint) (*Session, error) {
 	today := s.clock.Now().Format("2006-01-02")
 	session, err := s.attRepo.FindByUserDate(userID, today)
@@ -83,6 +142,7 @@ func (s *Service) CheckIn(ctx context.Context, userID uint) (*Session, error) {
 	if err := s.attRepo.Create(newSession); err != nil {
 		return nil, err
 	}
+	s.sessionChanged(ctx, newSession, SessionCheckedIn)
 
 	return newSession, nil
 }
@@ -114,6 +174,7 @@ func (s *Service) CheckOut(ctx context.Context, userID uint, reason *string) (*S
 	if err := s.attRepo.Save(session); err != nil {
 		return nil, err
 	}
+	s.sessionChanged(ctx, session, SessionCheckedOut)
 
 	return session, nil
 }
@@ -136,8 +197,30 @@ func (s *Service) ListMe(ctx context.Context, userID uint, from, to string) (*Li
 		return nil, err
 	}
 
-	// Leave usage map (optional; empty in new design)
+	// Leave usage map (derived from the monthly leave breakdown of each month in range)
 	leaveUsageMap := make(map[string]LeaveUsageInfo)
+	if s.leaveRepo != nil {
+		fromDate, errFrom := time.Parse("2006-01-02", from)
+		toDate, errTo := time.Parse("2006-01-02", to)
+		if errFrom == nil && errTo == nil {
+			cur := time.Date(fromDate.Year(), fromDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+			for !cur.After(toDate) {
+				infos, err := s.leaveRepo.GetLeaveUsageInfoByUserAndMonth(ctx, userID, cur.Year(), int(cur.Month()))
+				if err != nil {
+					return nil, err
+				}
+				for _, info := range infos {
+					dateStr := info.UsageDate.Format("2006-01-02")
+					// A day is unpaid if any part of it is unpaid
+					if existing, ok := leaveUsageMap[dateStr]; ok && existing.IsUnpaid {
+						continue
+					}
+					leaveUsageMap[dateStr] = info
+				}
+				cur = cur.AddDate(0, 1, 0)
+			}
+		}
+	}
 
 	loc := s.cfg.TimeLocation()
 	listRows := make([]TodayListRow, 0, len(rows))
@@ -264,6 +347,9 @@ func (s *Service) CreateManual(ctx context.Context, req CreateManualRequest) (*S
 		return nil, errors.New("invalid work date format")
 	}
 	workDate = time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 0, 0, 0, 0, loc)
+	if err := s.checkMonthLock(ctx, req.UserID, workDate); err != nil {
+		return nil, err
+	}
 
 	// Parse check-in time
 	checkInAt, err := time.Parse(time.RFC3339, req.CheckInAt)
@@ -305,6 +391,7 @@ func (s *Service) CreateManual(ctx context.Context, req CreateManualRequest) (*S
 	if err := s.attRepo.Create(session); err != nil {
 		return nil, err
 	}
+	s.sessionChanged(ctx, session, SessionCreated)
 
 	return session, nil
 }
@@ -336,6 +423,9 @@ func (s *Service) UpdateSession(ctx context.Context, id uint, req UpdateSessionR
 			return nil, errors.New("invalid work date format")
 		}
 		workDate = time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 0, 0, 0, 0, loc)
+		if err := s.checkMonthLock(ctx, *req.UserID, workDate); err != nil {
+			return nil, err
+		}
 		
 		// Parse check-in time (required for new session)
 		if req.CheckInAt == nil {
@@ -380,6 +470,7 @@ func (s *Service) UpdateSession(ctx context.Context, id uint, req UpdateSessionR
 		if err := s.attRepo.Create(newSession); err != nil {
 			return nil, err
 		}
+		s.sessionChanged(ctx, newSession, SessionCreated)
 		
 		return newSession, nil
 	}
@@ -387,6 +478,9 @@ func (s *Service) UpdateSession(ctx context.Context, id uint, req UpdateSessionR
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkMonthLock(ctx, session.UserID, session.WorkDate); err != nil {
+		return nil, err
+	}
 
 	// Update existing session
 	loc := s.cfg.TimeLocation()
@@ -427,6 +521,7 @@ func (s *Service) UpdateSession(ctx context.Context, id uint, req UpdateSessionR
 	if err := s.attRepo.Update(ctx, session); err != nil {
 		return nil, err
 	}
+	s.sessionChanged(ctx, session, SessionUpdated)
 
 	return session, nil
 }
@@ -436,6 +531,9 @@ func (s *Service) CloseSession(ctx context.Context, id uint, checkOutAt string,
 	if err != nil {
 		return nil, err
 	}
+	if err := s.checkMonthLock(ctx, session.UserID, session.WorkDate); err != nil {
+		return nil, err
+	}
 
 	loc := s.cfg.TimeLocation()
 	co, err := time.Parse(time.RFC3339, checkOutAt)
@@ -453,10 +551,60 @@ func (s *Service) CloseSession(ctx context.Context, id uint, checkOutAt string,
 	if err := s.attRepo.Update(ctx, session); err != nil {
 		return nil, err
 	}
+	s.sessionChanged(ctx, session, SessionClosed)
 
 	return session, nil
 }
 
+// CheckMonthLock returns ErrMonthLocked if the user's month of workDate is signed off
+func (s *Service) CheckMonthLock(ctx context.Context, userID uint, workDate time.Time) error {
+	return s.checkMonthLock(ctx, userID, workDate)
+}
+
+// CloseOpenSessions checks the user out of every open session, at the end of the working
+// day or now if that is earlier. Used when a user is offboarded. Nothing is closed if any
+// session is in a signed-off month.
+func (s *Service) CloseOpenSessions(ctx context.Context, userID uint, reason string) (int, error) {
+	sessions, err := s.attRepo.ListOpenByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for i := range sessions {
+		if err := s.checkMonthLock(ctx, userID, sessions[i].WorkDate); err != nil {
+			return 0, err
+		}
+	}
+	loc := s.cfg.TimeLocation()
+	now := s.clock.Now().In(loc)
+	for i := range sessions {
+		y, m, d := sessions[i].WorkDate.Date()
+		co := combineDateAndHM(time.Date(y, m, d, 0, 0, 0, 0, loc), WorkEndCalc)
+		if co.After(now) {
+			co = now
+		}
+		if co.Before(sessions[i].CheckInAt) {
+			co = sessions[i].CheckInAt
+		}
+		if _, err := s.CloseSession(ctx, sessions[i].ID, co.Format(time.RFC3339), reason); err != nil {
+			return i, err
+		}
+	}
+	return len(sessions), nil
+}
+
 func (s *Service) DeleteSession(ctx context.Context, id uint) error {
-	return s.attRepo.Delete(ctx, id)
+	session, err := s.attRepo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := s.checkMonthLock(ctx, session.UserID, session.WorkDate); err != nil {
+		return err
+	}
+	if err := s.attRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.sessionChanged(ctx, session, SessionDeleted)
+	return nil
 }
\ No newline at end of file
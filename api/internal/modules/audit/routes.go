@@ -1,6 +1,10 @@
 package audit
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"time-attendance-be/internal/authx"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 type Module struct {
 	h *Handler
@@ -13,6 +17,6 @@ func NewModule(repo *Repo) *Module {
 }
 
 func (m *Module) RegisterAdmin(admin fiber.Router) {
-	g := admin.Group("/audit")
+	g := admin.Group("/audit", authx.Require(authx.PermAuditRead))
 	g.Get("", m.h.List)
 }
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"strconv"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /api/v1/me/api-keys/scopes
+func (h *Handler) ListAPIKeyScopes(c *fiber.Ctx) error {
+	res := make([]APIScopeResponse, len(authx.AllScopes))
+	for i, sc := range authx.AllScopes {
+		res[i] = APIScopeResponse{Scope: sc.Scope, Description: sc.Description}
+	}
+	return response.OK(c, res)
+}
+
+// GET /api/v1/me/api-keys
+// Personal tokens of the current user (active only)
+func (h *Handler) ListMyAPIKeys(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	keys, err := h.svc.ListAPIKeys(c.Context(), APIKeyFilter{UserID: &a.ID, Kind: APIKeyPersonal})
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toAPIKeyResponses(keys))
+}
+
+// POST /api/v1/me/api-keys
+// Body: { "name": "HR script", "scopes": ["attendance:read"], "expiresInDays": 90 }.
+// The key is only returned in this response.
+func (h *Handler) CreateMyAPIKey(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	k, secret, err := h.svc.CreateAPIKey(c.Context(), a.ID, APIKeyPersonal, CreateAPIKeyInput{
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		ExpiresInDays: req.ExpiresInDays,
+	}, a.TwoFactor)
+	if err != nil {
+		return err
+	}
+	res := toAPIKeyResponse(k)
+	res.Key = secret
+	return response.Created(c, res)
+}
+
+// DELETE /api/v1/me/api-keys/:id
+func (h *Handler) RevokeMyAPIKey(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	if err := h.svc.RevokeOwnAPIKey(c.Context(), a.ID, uint(id)); err != nil {
+		return err
+	}
+	return response.OK(c, true)
+}
+
+// GET /api/v1/admin/api-keys?kind=&userId=&includeRevoked=
+func (h *Handler) AdminListAPIKeys(c *fiber.Ctx) error {
+	f := APIKeyFilter{Kind: c.Query("kind"), IncludeRevoked: c.QueryBool("includeRevoked")}
+	if f.Kind != "" && f.Kind != APIKeyPersonal && f.Kind != APIKeyService {
+		return response.Validation("kind must be PERSONAL or SERVICE", nil)
+	}
+	if s := c.Query("userId"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return response.Validation("invalid userId", nil)
+		}
+		uid := uint(id)
+		f.UserID = &uid
+	}
+
+	keys, err := h.svc.ListAPIKeys(c.Context(), f)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toAPIKeyResponses(keys))
+}
+
+// POST /api/v1/admin/api-keys
+// Creates a service key for an integration. Body as for personal tokens; the key is only
+// returned in this response.
+func (h *Handler) AdminCreateServiceKey(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	k, secret, err := h.svc.CreateAPIKey(c.Context(), adminUser.ID, APIKeyService, CreateAPIKeyInput{
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		ExpiresInDays: req.ExpiresInDays,
+	}, adminUser.TwoFactor)
+	if err != nil {
+		return err
+	}
+	res := toAPIKeyResponse(k)
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"CREATE",
+			"api_key",
+			strconv.FormatUint(uint64(k.ID), 10),
+			nil,
+			res,
+			"",
+		)
+	}
+
+	res.Key = secret
+	return response.Created(c, res)
+}
+
+// DELETE /api/v1/admin/api-keys/:id
+// Revokes any key, personal or service
+func (h *Handler) AdminRevokeAPIKey(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	before, err := h.svc.RevokeAPIKey(c.Context(), adminUser.ID, uint(id))
+	if err != nil {
+		return err
+	}
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"REVOKE",
+			"api_key",
+			c.Params("id"),
+			toAPIKeyResponse(before),
+			nil,
+			c.Query("reason"),
+		)
+	}
+
+	return response.OK(c, true)
+}
+
+func toAPIKeyResponses(keys []APIKey) []APIKeyResponse {
+	res := make([]APIKeyResponse, len(keys))
+	for i := range keys {
+		res[i] = toAPIKeyResponse(&keys[i])
+	}
+	return res
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey is a named, scoped, revocable key for scripts and integrations (table auth_api_keys).
+// Only the SHA-256 of the key is stored; Prefix is kept to recognise it in lists.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey"`
+	Name       string     `gorm:"type:varchar(100);not null"`
+	Kind       string     `gorm:"type:enum('PERSONAL','SERVICE');not null"`
+	UserID     uint       `gorm:"not null;index"` // owner; for service keys the admin who created it
+	Prefix     string     `gorm:"type:varchar(16);not null"`
+	KeyHash    string     `gorm:"type:char(64);not null;uniqueIndex"`
+	Scopes     string     `gorm:"type:varchar(500);not null"` // space separated
+	TwoFactor  bool       `gorm:"type:tinyint(1);not null;default:0"`
+	ExpiresAt  *time.Time // nil = no expiry
+	LastUsedAt *time.Time
+	LastUsedIP string    `gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt  time.Time `gorm:"not null"`
+	RevokedAt  *time.Time
+	RevokedBy  *uint
+}
+
+func (APIKey) TableName() string {
+	return "auth_api_keys"
+}
+
+// API key kinds
+const (
+	APIKeyPersonal = "PERSONAL" // acts as its owner, within its scopes
+	APIKeyService  = "SERVICE"  // admin integration key (payroll, HR scripts), admin routes only
+)
+
+// ScopeList returns the key's scopes
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// IsActive reports whether the key can be used at now
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
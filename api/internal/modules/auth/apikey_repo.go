@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// API key methods
+
+// CreateAPIKey stores a new key
+func (r *Repo) CreateAPIKey(ctx context.Context, k *APIKey) error {
+	return r.db.WithContext(ctx).Create(k).Error
+}
+
+// GetAPIKey returns a key by ID
+func (r *Repo) GetAPIKey(ctx context.Context, id uint) (*APIKey, error) {
+	var k APIKey
+	if err := r.db.WithContext(ctx).First(&k, id).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// GetAPIKeyByHash returns a key by the hash of its value, or nil if unknown
+func (r *Repo) GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error) {
+	var rows []APIKey
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", hash).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// APIKeyFilter selects keys
+type APIKeyFilter struct {
+	UserID         *uint
+	Kind           string
+	IncludeRevoked bool
+}
+
+// ListAPIKeys returns keys matching the filter, newest first
+func (r *Repo) ListAPIKeys(ctx context.Context, f APIKeyFilter) ([]APIKey, error) {
+	q := r.db.WithContext(ctx).Model(&APIKey{})
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.Kind != "" {
+		q = q.Where("kind = ?", f.Kind)
+	}
+	if !f.IncludeRevoked {
+		q = q.Where("revoked_at IS NULL")
+	}
+	var rows []APIKey
+	err := q.Order("created_at DESC, id DESC").Find(&rows).Error
+	return rows, err
+}
+
+// RevokeAPIKey revokes an active key; returns false if it was already revoked
+func (r *Repo) RevokeAPIKey(ctx context.Context, id, by uint) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_by": by})
+	return res.RowsAffected > 0, res.Error
+}
+
+// TouchAPIKey records a use of the key, at most once per interval so busy keys don't
+// write on every request
+func (r *Repo) TouchAPIKey(ctx context.Context, id uint, ip string, now time.Time, interval time.Duration) error {
+	return r.db.WithContext(ctx).Model(&APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip}).Error
+}
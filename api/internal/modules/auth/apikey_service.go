@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyTouchInterval limits last-used updates to one per key per minute
+	apiKeyTouchInterval = time.Minute
+	// maxActiveAPIKeys per owner
+	maxActiveAPIKeys = 25
+	// maxAPIKeyDays is the longest expiry that can be chosen (0 = no expiry)
+	maxAPIKeyDays = 730
+)
+
+// CreateAPIKeyInput describes a key to create
+type CreateAPIKeyInput struct {
+	Name          string
+	Scopes        []string
+	ExpiresInDays int // 0 = no expiry
+}
+
+// CreateAPIKey creates a key owned by userID and returns it with its value. The value is only
+// available here; afterwards only its hash is known. twoFactor tells whether the creating
+// session passed 2FA, which the key inherits.
+func (s *Service) CreateAPIKey(ctx context.Context, userID uint, kind string, in CreateAPIKeyInput, twoFactor bool) (*APIKey, string, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len(name) > 100 {
+		return nil, "", response.Validation("name is required (max 100 characters)", nil)
+	}
+	scopes, err := normalizeScopes(in.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if in.ExpiresInDays < 0 || in.ExpiresInDays > maxAPIKeyDays {
+		return nil, "", response.Validation("expiresInDays must be between 0 (no expiry) and 730", nil)
+	}
+
+	uid := userID
+	active, err := s.repo.ListAPIKeys(ctx, APIKeyFilter{UserID: &uid, Kind: kind})
+	if err != nil {
+		return nil, "", response.Internal(err)
+	}
+	if len(active) >= maxActiveAPIKeys {
+		return nil, "", response.Conflict("Too many active API keys, revoke an unused one first")
+	}
+
+	secret, err := newAPIKeyValue()
+	if err != nil {
+		return nil, "", response.Internal(err)
+	}
+	now := time.Now()
+	k := &APIKey{
+		Name:      name,
+		Kind:      kind,
+		UserID:    userID,
+		Prefix:    secret[:len(authx.APIKeyPrefix)+8],
+		KeyHash:   hashTokenID(secret),
+		Scopes:    strings.Join(scopes, " "),
+		TwoFactor: twoFactor,
+		CreatedAt: now,
+	}
+	if in.ExpiresInDays > 0 {
+		exp := now.AddDate(0, 0, in.ExpiresInDays)
+		k.ExpiresAt = &exp
+	}
+	if err := s.repo.CreateAPIKey(ctx, k); err != nil {
+		return nil, "", response.Internal(err)
+	}
+	s.logger.Info("api key created", zap.Uint("keyID", k.ID), zap.Uint("userID", userID), zap.String("kind", kind))
+	return k, secret, nil
+}
+
+// ListAPIKeys returns keys matching the filter
+func (s *Service) ListAPIKeys(ctx context.Context, f APIKeyFilter) ([]APIKey, error) {
+	rows, err := s.repo.ListAPIKeys(ctx, f)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	return rows, nil
+}
+
+// RevokeOwnAPIKey revokes one of the user's personal keys
+func (s *Service) RevokeOwnAPIKey(ctx context.Context, userID, id uint) error {
+	k, err := s.getAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if k.UserID != userID || k.Kind != APIKeyPersonal {
+		return response.NotFound("API key not found")
+	}
+	if _, err := s.repo.RevokeAPIKey(ctx, id, userID); err != nil {
+		return response.Internal(err)
+	}
+	return nil
+}
+
+// RevokeAPIKey revokes any key (admin). Returns the key as it was before.
+func (s *Service) RevokeAPIKey(ctx context.Context, adminID, id uint) (*APIKey, error) {
+	k, err := s.getAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if k.RevokedAt != nil {
+		return nil, response.Conflict("API key is already revoked")
+	}
+	if _, err := s.repo.RevokeAPIKey(ctx, id, adminID); err != nil {
+		return nil, response.Internal(err)
+	}
+	return k, nil
+}
+
+// AuthenticateAPIKey implements middleware.APIKeyStore. Returns nil for unknown, revoked or
+// expired keys.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key, ip string) (*authx.APIKey, error) {
+	k, err := s.repo.GetAPIKeyByHash(ctx, hashTokenID(key))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if k == nil || !k.IsActive(now) {
+		return nil, nil
+	}
+	if err := s.repo.TouchAPIKey(ctx, k.ID, truncate(ip, 64), now, apiKeyTouchInterval); err != nil {
+		s.logger.Warn("failed to record api key use", zap.Uint("keyID", k.ID), zap.Error(err))
+	}
+	return &authx.APIKey{
+		ID:        k.ID,
+		UserID:    k.UserID,
+		Service:   k.Kind == APIKeyService,
+		Scopes:    k.ScopeList(),
+		TwoFactor: k.TwoFactor,
+	}, nil
+}
+
+func (s *Service) getAPIKey(ctx context.Context, id uint) (*APIKey, error) {
+	k, err := s.repo.GetAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFound("API key not found")
+		}
+		return nil, response.Internal(err)
+	}
+	return k, nil
+}
+
+// normalizeScopes validates, dedupes and sorts scopes
+func normalizeScopes(in []string) ([]string, error) {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(in))
+	for _, sc := range in {
+		sc = strings.TrimSpace(sc)
+		if !authx.IsScope(sc) {
+			return nil, response.Validation("unknown scope: "+sc, nil)
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			out = append(out, sc)
+		}
+	}
+	if len(out) == 0 {
+		return nil, response.Validation("at least one scope is required", nil)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// newAPIKeyValue returns "tak_" followed by 40 random base64url characters (240 bits)
+func newAPIKeyValue() (string, error) {
+	b := make([]byte, 30)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return authx.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
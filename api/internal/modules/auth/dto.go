@@ -1,5 +1,10 @@
 package auth
 
+import (
+	"strings"
+	"time"
+)
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email,max=190"`
 	Password string `json:"password" validate:"required"`
@@ -12,6 +17,299 @@ type LoginUser struct {
 	Role  string `json:"role"`
 }
 
+// LoginResponse carries the user, or a challenge when a TOTP code is still due
 type LoginResponse struct {
-	User LoginUser `json:"user"`
+	User              *LoginUser `json:"user,omitempty"`
+	TwoFactorRequired bool       `json:"twoFactorRequired,omitempty"`
+	Challenge         string     `json:"challenge,omitempty"`
+}
+
+type LoginTwoFactorRequest struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+// TwoFactorStatusResponse is the current user's 2FA state
+type TwoFactorStatusResponse struct {
+	Enabled           bool    `json:"enabled"`
+	EnabledAt         *string `json:"enabledAt"`
+	RecoveryCodesLeft int64   `json:"recoveryCodesLeft"`
+	Required          bool    `json:"required"`        // required by the security policy for the user's role
+	SessionVerified   bool    `json:"sessionVerified"` // the current session passed the TOTP step
+}
+
+func toTwoFactorStatusResponse(st *TwoFactorStatus) TwoFactorStatusResponse {
+	res := TwoFactorStatusResponse{
+		Enabled:           st.Enabled,
+		RecoveryCodesLeft: st.RecoveryCodesLeft,
+		Required:          st.Required,
+		SessionVerified:   st.SessionTwoFactored,
+	}
+	if st.EnabledAt != nil {
+		t := formatTime(*st.EnabledAt)
+		res.EnabledAt = &t
+	}
+	return res
+}
+
+type TwoFactorSetupRequest struct {
+	Password string `json:"password"`
+}
+
+// TwoFactorSetupResponse is shown once; the URI is usually rendered as a QR code
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// RecoveryCodesResponse is shown once; only hashes are stored
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type SecurityPolicyRequest struct {
+	RequireAdminTwoFactor *bool `json:"requireAdminTwoFactor"`
+}
+
+type SecurityPolicyResponse struct {
+	RequireAdminTwoFactor bool    `json:"requireAdminTwoFactor"`
+	UpdatedBy             *uint   `json:"updatedBy"`
+	UpdatedAt             *string `json:"updatedAt"`
+}
+
+func toSecurityPolicyResponse(p *SecurityPolicy) SecurityPolicyResponse {
+	res := SecurityPolicyResponse{RequireAdminTwoFactor: p.RequireAdminTwoFactor, UpdatedBy: p.UpdatedBy}
+	if !p.UpdatedAt.IsZero() {
+		t := formatTime(p.UpdatedAt)
+		res.UpdatedAt = &t
+	}
+	return res
+}
+
+type OIDCConfigResponse struct {
+	Enabled  bool   `json:"enabled"`
+	LoginURL string `json:"loginUrl,omitempty"`
+}
+
+// SessionResponse is one active login session
+type SessionResponse struct {
+	ID         uint   `json:"id"`
+	Device     string `json:"device"`
+	UserAgent  string `json:"userAgent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"createdAt"`
+	LastSeenAt string `json:"lastSeenAt"`
+	ExpiresAt  string `json:"expiresAt"`
+	Current    bool   `json:"current"`
+}
+
+func toSessionResponse(s *Session, currentID uint) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		Device:     deviceLabel(s.UserAgent),
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  formatTime(s.CreatedAt),
+		LastSeenAt: formatTime(s.LastSeenAt),
+		ExpiresAt:  formatTime(s.ExpiresAt),
+		Current:    s.ID == currentID,
+	}
+}
+
+// deviceLabel is a short "Browser on OS" description of a User-Agent
+func deviceLabel(ua string) string {
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	case ua != "":
+		browser = strings.SplitN(ua, "/", 2)[0]
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		os = "iOS"
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+	if os == "" {
+		return browser
+	}
+	return browser + " on " + os
+}
+
+// LoginAttemptResponse is one entry of the login history
+type LoginAttemptResponse struct {
+	ID        uint   `json:"id"`
+	UserID    *uint  `json:"userId,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Success   bool   `json:"success"`
+	Result    string `json:"result"`
+	IP        string `json:"ip"`
+	Device    string `json:"device"`
+	UserAgent string `json:"userAgent"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// toLoginAttemptResponse maps an attempt; the email is only shown to admins (withEmail)
+func toLoginAttemptResponse(a *LoginAttempt, withEmail bool) LoginAttemptResponse {
+	res := LoginAttemptResponse{
+		ID:        a.ID,
+		Success:   a.Success,
+		Result:    a.Result,
+		IP:        a.IP,
+		Device:    deviceLabel(a.UserAgent),
+		UserAgent: a.UserAgent,
+		CreatedAt: formatTime(a.CreatedAt),
+	}
+	if withEmail {
+		res.UserID = a.UserID
+		res.Email = a.Email
+	}
+	return res
+}
+
+type LockedAccountResponse struct {
+	UserID      uint   `json:"userId"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Lockouts    int    `json:"lockouts"`
+	LockedUntil string `json:"lockedUntil"`
+}
+
+type AttemptCountResponse struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// SecurityOverviewResponse is the admin security dashboard (last 24 hours)
+type SecurityOverviewResponse struct {
+	Since                 string                  `json:"since"`
+	SucceededLogins       int64                   `json:"succeededLogins"`
+	FailedLogins          int64                   `json:"failedLogins"`
+	LockedAccounts        []LockedAccountResponse `json:"lockedAccounts"`
+	TopFailingIPs         []AttemptCountResponse  `json:"topFailingIps"`
+	TopTargetedEmails     []AttemptCountResponse  `json:"topTargetedEmails"`
+	Admins                int64                   `json:"admins"`
+	AdminsWithTwoFactor   int64                   `json:"adminsWithTwoFactor"`
+	RequireAdminTwoFactor bool                    `json:"requireAdminTwoFactor"`
+}
+
+func toSecurityOverviewResponse(ov *SecurityOverview) SecurityOverviewResponse {
+	res := SecurityOverviewResponse{
+		Since:                 formatTime(ov.Since),
+		SucceededLogins:       ov.Succeeded,
+		FailedLogins:          ov.Failed,
+		LockedAccounts:        make([]LockedAccountResponse, len(ov.LockedAccounts)),
+		TopFailingIPs:         toAttemptCountResponses(ov.TopFailingIPs),
+		TopTargetedEmails:     toAttemptCountResponses(ov.TopTargetedEmails),
+		Admins:                ov.Admins,
+		AdminsWithTwoFactor:   ov.AdminsWithTwoFactor,
+		RequireAdminTwoFactor: ov.RequireAdminTwoFactor,
+	}
+	for i, l := range ov.LockedAccounts {
+		res.LockedAccounts[i] = LockedAccountResponse{
+			UserID:      l.UserID,
+			Name:        l.Name,
+			Email:       l.Email,
+			Lockouts:    l.Lockouts,
+			LockedUntil: formatTime(l.LockedUntil),
+		}
+	}
+	return res
+}
+
+func toAttemptCountResponses(rows []AttemptCount) []AttemptCountResponse {
+	res := make([]AttemptCountResponse, len(rows))
+	for i, r := range rows {
+		res[i] = AttemptCountResponse{Key: r.Key, Count: r.Count}
+	}
+	return res
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expiresInDays"` // 0 = no expiry
+}
+
+// APIKeyResponse describes a key; Key is only set in the creation response
+type APIKeyResponse struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	UserID     uint     `json:"userId"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *string  `json:"expiresAt"`
+	LastUsedAt *string  `json:"lastUsedAt"`
+	LastUsedIP string   `json:"lastUsedIp,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+	RevokedAt  *string  `json:"revokedAt,omitempty"`
+	Key        string   `json:"key,omitempty"`
+}
+
+func toAPIKeyResponse(k *APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Kind:       k.Kind,
+		UserID:     k.UserID,
+		Prefix:     k.Prefix,
+		Scopes:     k.ScopeList(),
+		ExpiresAt:  formatTimePtr(k.ExpiresAt),
+		LastUsedAt: formatTimePtr(k.LastUsedAt),
+		LastUsedIP: k.LastUsedIP,
+		CreatedAt:  formatTime(k.CreatedAt),
+		RevokedAt:  formatTimePtr(k.RevokedAt),
+	}
+}
+
+type APIScopeResponse struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+func formatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := formatTime(*t)
+	return &s
 }
@@ -1,6 +1,12 @@
 package auth
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
 	"time-attendance-be/internal/pkg/response"
 	platformauth "time-attendance-be/internal/platform/auth"
 
@@ -8,12 +14,13 @@ import (
 )
 
 type Handler struct {
-	svc     *Service
-	cookies *platformauth.CookieManager
+	svc      *Service
+	cookies  *platformauth.CookieManager
+	auditSvc *audit.Service
 }
 
-func NewHandler(svc *Service, cookies *platformauth.CookieManager) *Handler {
-	return &Handler{svc: svc, cookies: cookies}
+func NewHandler(svc *Service, cookies *platformauth.CookieManager, auditSvc *audit.Service) *Handler {
+	return &Handler{svc: svc, cookies: cookies, auditSvc: auditSvc}
 }
 
 // POST /api/v1/auth/login
@@ -23,19 +30,50 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		return response.Validation("Invalid body", nil)
 	}
 
-	u, accessToken, refreshToken, err := h.svc.Login(c.Context(), req.Email, req.Password)
+	res, err := h.svc.Login(c.Context(), req.Email, req.Password, clientInfo(c))
 	if err != nil {
 		return err
 	}
+	if res.Challenge != "" {
+		// Second step: POST /auth/login/2fa with the challenge and a code
+		return response.OK(c, LoginResponse{TwoFactorRequired: true, Challenge: res.Challenge})
+	}
 
-	h.cookies.SetAccessToken(c, accessToken)
-	h.cookies.SetRefreshToken(c, refreshToken)
+	return h.completeLogin(c, res)
+}
+
+// POST /api/v1/auth/login/2fa
+// Body: { "challenge": "...", "code": "123456" } (a recovery code is accepted as code)
+func (h *Handler) LoginTwoFactor(c *fiber.Ctx) error {
+	var req LoginTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+	if req.Challenge == "" || req.Code == "" {
+		return response.Validation("challenge and code are required", nil)
+	}
+
+	res, err := h.svc.VerifyLoginTwoFactor(c.Context(), req.Challenge, req.Code, clientInfo(c))
+	if err != nil {
+		return err
+	}
+	return h.completeLogin(c, res)
+}
+
+func (h *Handler) completeLogin(c *fiber.Ctx, res *LoginResult) error {
+	h.cookies.SetAccessToken(c, res.AccessToken)
+	h.cookies.SetRefreshToken(c, res.RefreshToken)
 
-	return response.OK(c, LoginResponse{User: LoginUser{ID: u.ID, Name: u.Name, Email: u.Email, Role: u.Role}})
+	u := res.User
+	return response.OK(c, LoginResponse{User: &LoginUser{ID: u.ID, Name: u.Name, Email: u.Email, Role: u.Role}})
 }
 
 // POST /api/v1/auth/logout
+// Revokes the current session and clears the cookies
 func (h *Handler) Logout(c *fiber.Ctx) error {
+	if rt := c.Cookies("refresh_token"); rt != "" {
+		h.svc.Logout(c.Context(), rt)
+	}
 	h.cookies.ClearTokens(c)
 	return response.OK(c, true)
 }
@@ -47,8 +85,9 @@ func (h *Handler) Refresh(c *fiber.Ctx) error {
 		return response.Unauthorized("Unauthorized")
 	}
 
-	_, accessToken, newRefreshToken, err := h.svc.RefreshToken(c.Context(), rt)
+	_, accessToken, newRefreshToken, err := h.svc.RefreshToken(c.Context(), rt, clientInfo(c))
 	if err != nil {
+		h.cookies.ClearTokens(c)
 		return err
 	}
 
@@ -58,3 +97,121 @@ func (h *Handler) Refresh(c *fiber.Ctx) error {
 	return response.OK(c, true)
 }
 
+// GET /api/v1/me/sessions
+// Active sessions of the current user (device, IP, last seen); the current one is flagged
+func (h *Handler) ListMySessions(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	sessions, err := h.svc.ListSessions(c.Context(), a.ID)
+	if err != nil {
+		return response.Internal(err)
+	}
+	res := make([]SessionResponse, len(sessions))
+	for i := range sessions {
+		res[i] = toSessionResponse(&sessions[i], a.SessionID)
+	}
+	return response.OK(c, res)
+}
+
+// DELETE /api/v1/me/sessions/:id
+func (h *Handler) RevokeMySession(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	if err := h.svc.RevokeSession(c.Context(), a.ID, uint(id)); err != nil {
+		return err
+	}
+	if uint(id) == a.SessionID {
+		h.cookies.ClearTokens(c)
+	}
+	return response.OK(c, true)
+}
+
+// DELETE /api/v1/me/sessions
+// Signs out everywhere else: revokes all sessions except the current one
+func (h *Handler) RevokeMyOtherSessions(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	n, err := h.svc.RevokeOtherSessions(c.Context(), a.ID, a.SessionID)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, map[string]interface{}{"revoked": n})
+}
+
+// POST /api/v1/me/password
+// Changes the password; other sessions are signed out, the current one stays
+func (h *Handler) ChangeMyPassword(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return response.Validation("currentPassword and newPassword are required", nil)
+	}
+
+	if err := h.svc.ChangePassword(c.Context(), a.ID, a.SessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		return err
+	}
+	return response.OK(c, true)
+}
+
+// POST /api/v1/auth/password/forgot
+// Always succeeds so the response doesn't reveal which emails have accounts
+func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+	email := strings.TrimSpace(strings.ToLower(req.Email))
+	if email == "" {
+		return response.Validation("email is required", nil)
+	}
+
+	if err := h.svc.ForgotPassword(c.Context(), email, clientInfo(c)); err != nil {
+		return err
+	}
+	return response.OK(c, true)
+}
+
+// POST /api/v1/auth/password/reset
+func (h *Handler) ResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+	if req.Token == "" || req.NewPassword == "" {
+		return response.Validation("token and newPassword are required", nil)
+	}
+
+	if err := h.svc.ResetPassword(c.Context(), req.Token, req.NewPassword); err != nil {
+		return err
+	}
+	h.cookies.ClearTokens(c)
+	return response.OK(c, true)
+}
+
+func clientInfo(c *fiber.Ctx) ClientInfo {
+	return ClientInfo{UserAgent: c.Get(fiber.HeaderUserAgent), IP: c.IP()}
+}
+
+// formatTime formats t in RFC3339
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"time-attendance-be/internal/platform/scheduler"
+)
+
+// sessionRetention is how long ended sessions are kept (for the user's and admins' reference)
+const sessionRetention = 30 * 24 * time.Hour
+
+// RegisterJobs puts the auth module's periodic work on the scheduler (cron in APP_TZ)
+func (s *Service) RegisterJobs(sch *scheduler.Scheduler) {
+	sch.MustRegister(scheduler.Job{
+		Name:        "auth.purge-sessions",
+		Description: "Delete sessions and refresh tokens that ended more than 30 days ago, and abandoned SSO logins",
+		Schedule:    "30 3 * * *",
+		Run:         s.purgeSessions,
+	})
+	sch.MustRegister(scheduler.Job{
+		Name:        "auth.purge-login-history",
+		Description: "Delete login attempts older than AUTH_LOGIN_HISTORY_DAYS",
+		Schedule:    "45 3 * * *",
+		Run:         s.purgeLoginHistory,
+	})
+}
+
+func (s *Service) purgeSessions(ctx context.Context) (scheduler.Result, error) {
+	n, err := s.repo.PurgeExpired(ctx, time.Now().Add(-sessionRetention))
+	if err != nil {
+		return scheduler.Result{}, err
+	}
+	// Abandoned SSO logins
+	if _, err := s.repo.PurgeOIDCStates(ctx, time.Now()); err != nil {
+		return scheduler.Result{}, err
+	}
+	return scheduler.Result{Processed: int(n), Message: fmt.Sprintf("purged %d sessions", n)}, nil
+}
+
+func (s *Service) purgeLoginHistory(ctx context.Context) (scheduler.Result, error) {
+	if s.cfg.Auth.LoginHistoryDays <= 0 {
+		return scheduler.Result{Message: "retention disabled"}, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -s.cfg.Auth.LoginHistoryDays)
+	n, err := s.repo.PurgeLoginAttempts(ctx, cutoff)
+	if err != nil {
+		return scheduler.Result{}, err
+	}
+	return scheduler.Result{Processed: int(n), Message: fmt.Sprintf("purged %d login attempts", n)}, nil
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /api/v1/me/login-history?limit=&offset=
+func (h *Handler) GetMyLoginHistory(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	f := LoginAttemptFilter{UserID: &a.ID}
+	parsePaging(c, &f)
+	return h.listLoginAttempts(c, f, false)
+}
+
+// GET /api/v1/admin/auth/login-attempts?userId=&ip=&success=&from=&to=&limit=&offset=
+func (h *Handler) AdminListLoginAttempts(c *fiber.Ctx) error {
+	f := LoginAttemptFilter{IP: c.Query("ip")}
+	if s := c.Query("userId"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return response.Validation("invalid userId", nil)
+		}
+		uid := uint(id)
+		f.UserID = &uid
+	}
+	if s := c.Query("success"); s != "" {
+		ok, err := strconv.ParseBool(s)
+		if err != nil {
+			return response.Validation("invalid success", nil)
+		}
+		f.Success = &ok
+	}
+	if s := c.Query("from"); s != "" {
+		from, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return response.Validation("invalid from, expected YYYY-MM-DD", nil)
+		}
+		f.From = &from
+	}
+	if s := c.Query("to"); s != "" {
+		to, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return response.Validation("invalid to, expected YYYY-MM-DD", nil)
+		}
+		to = to.Add(24 * time.Hour)
+		f.To = &to
+	}
+	parsePaging(c, &f)
+	return h.listLoginAttempts(c, f, true)
+}
+
+// GET /api/v1/admin/auth/security-overview
+// Last 24 hours: login counts, locked accounts, most failing IPs / targeted emails, admin 2FA coverage
+func (h *Handler) AdminSecurityOverview(c *fiber.Ctx) error {
+	ov, err := h.svc.SecurityOverview(c.Context())
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toSecurityOverviewResponse(ov))
+}
+
+// POST /api/v1/admin/auth/users/:id/unlock
+func (h *Handler) AdminUnlockAccount(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	unlocked, err := h.svc.UnlockAccount(c.Context(), uint(id))
+	if err != nil {
+		return err
+	}
+
+	if unlocked && h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"UNLOCK",
+			"user",
+			c.Params("id"),
+			nil,
+			nil,
+			"",
+		)
+	}
+
+	return response.OK(c, map[string]interface{}{"unlocked": unlocked})
+}
+
+func (h *Handler) listLoginAttempts(c *fiber.Ctx, f LoginAttemptFilter, admin bool) error {
+	rows, total, err := h.svc.ListLoginHistory(c.Context(), f)
+	if err != nil {
+		return err
+	}
+	items := make([]LoginAttemptResponse, len(rows))
+	for i := range rows {
+		items[i] = toLoginAttemptResponse(&rows[i], admin)
+	}
+	return response.OK(c, map[string]interface{}{
+		"items":  items,
+		"total":  total,
+		"limit":  f.Limit,
+		"offset": f.Offset,
+	})
+}
+
+// parsePaging reads limit (default 50, max 200) and offset
+func parsePaging(c *fiber.Ctx, f *LoginAttemptFilter) {
+	f.Limit = 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		f.Limit = l
+		if f.Limit > 200 {
+			f.Limit = 200
+		}
+	}
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o >= 0 {
+		f.Offset = o
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import "time"
+
+// LoginAttempt is one login attempt, successful or not (table auth_login_attempts).
+// Failed attempts drive the per-account lockout and the per-IP block.
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    *uint     `gorm:"index"` // nil when the email matched no account
+	Email     string    `gorm:"type:varchar(190);not null;default:''"`
+	IP        string    `gorm:"type:varchar(64);not null;default:'';index:idx_login_attempts_ip_time,priority:1"`
+	UserAgent string    `gorm:"type:varchar(255);not null;default:''"`
+	Success   bool      `gorm:"type:tinyint(1);not null;default:0"`
+	Result    string    `gorm:"type:varchar(32);not null"`
+	CreatedAt time.Time `gorm:"not null;index;index:idx_login_attempts_ip_time,priority:2"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "auth_login_attempts"
+}
+
+// Login attempt results
+const (
+	LoginSucceeded      = "SUCCESS"
+	LoginBadPassword    = "INVALID_PASSWORD"
+	LoginUnknownUser    = "UNKNOWN_USER"
+	LoginBadTwoFactor   = "INVALID_2FA"
+	LoginAccountLocked  = "LOCKED"     // rejected without checking the password
+	LoginAccountBlocked = "DISABLED"   // account disabled
+	LoginIPBlocked      = "IP_BLOCKED" // too many failures from the IP
+)
+
+// countedFailures are the results that count towards the per-IP block. Rejections caused by
+// a lock or block are not counted, so a block expires even if the client keeps trying.
+var countedFailures = []string{LoginBadPassword, LoginUnknownUser, LoginBadTwoFactor}
+
+// AccountLockout tracks consecutive failed logins of an account (table auth_account_lockouts).
+// Reaching the threshold locks the account; each further lock before a successful login
+// lasts twice as long.
+type AccountLockout struct {
+	ID           uint `gorm:"primaryKey"`
+	UserID       uint `gorm:"not null;uniqueIndex"`
+	FailedCount  int  `gorm:"not null;default:0"` // failures since the last lock or success
+	Lockouts     int  `gorm:"not null;default:0"` // locks since the last success
+	LockedUntil  *time.Time
+	LastFailedAt *time.Time
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (AccountLockout) TableName() string {
+	return "auth_account_lockouts"
+}
+
+// IsLocked reports whether the account is locked at now
+func (l *AccountLockout) IsLocked(now time.Time) bool {
+	return l != nil && l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Login attempt and lockout methods
+
+// CreateLoginAttempt stores a login attempt
+func (r *Repo) CreateLoginAttempt(ctx context.Context, a *LoginAttempt) error {
+	return r.db.WithContext(ctx).Create(a).Error
+}
+
+// CountIPFailures counts failed attempts from an IP since a time
+func (r *Repo) CountIPFailures(ctx context.Context, ip string, since time.Time) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&LoginAttempt{}).
+		Where("ip = ? AND created_at >= ? AND result IN ?", ip, since, countedFailures).
+		Count(&n).Error
+	return n, err
+}
+
+// LoginAttemptFilter selects login attempts
+type LoginAttemptFilter struct {
+	UserID  *uint
+	IP      string
+	Success *bool
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	Offset  int
+}
+
+// ListLoginAttempts returns attempts matching the filter, newest first, and the total count
+func (r *Repo) ListLoginAttempts(ctx context.Context, f LoginAttemptFilter) ([]LoginAttempt, int64, error) {
+	q := r.db.WithContext(ctx).Model(&LoginAttempt{})
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.IP != "" {
+		q = q.Where("ip = ?", f.IP)
+	}
+	if f.Success != nil {
+		q = q.Where("success = ?", *f.Success)
+	}
+	if f.From != nil {
+		q = q.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", *f.To)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []LoginAttempt
+	err := q.Order("created_at DESC, id DESC").Limit(f.Limit).Offset(f.Offset).Find(&rows).Error
+	return rows, total, err
+}
+
+// PurgeLoginAttempts deletes attempts older than cutoff
+func (r *Repo) PurgeLoginAttempts(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("created_at < ?", cutoff).Delete(&LoginAttempt{})
+	return res.RowsAffected, res.Error
+}
+
+// GetLockout returns the user's lockout state, or nil if there were no failures
+func (r *Repo) GetLockout(ctx context.Context, userID uint) (*AccountLockout, error) {
+	var rows []AccountLockout
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// RecordLoginFailure counts a failed login. When the count reaches threshold the account is
+// locked for lockFor(n), n being the number of locks since the last success (1 for the first).
+// The row is locked while updating so concurrent failures on several replicas all count.
+func (r *Repo) RecordLoginFailure(ctx context.Context, userID uint, threshold int, lockFor func(n int) time.Duration) (*AccountLockout, error) {
+	var l AccountLockout
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// Make sure the row exists so it can be locked
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&AccountLockout{UserID: userID, UpdatedAt: now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).First(&l).Error; err != nil {
+			return err
+		}
+
+		l.FailedCount++
+		l.LastFailedAt = &now
+		if l.FailedCount >= threshold {
+			l.Lockouts++
+			until := now.Add(lockFor(l.Lockouts))
+			l.LockedUntil = &until
+			l.FailedCount = 0
+		}
+		l.UpdatedAt = now
+		return tx.Save(&l).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// ClearLockout resets the user's failure count and lock (successful login or admin unlock).
+// Returns false if the user had nothing to clear.
+func (r *Repo) ClearLockout(ctx context.Context, userID uint) (bool, error) {
+	res := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&AccountLockout{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// LockedAccount is a currently locked account, for the security overview
+type LockedAccount struct {
+	UserID      uint
+	Name        string
+	Email       string
+	Lockouts    int
+	LockedUntil time.Time
+}
+
+// ListLockedAccounts returns the accounts locked at now, longest lock first
+func (r *Repo) ListLockedAccounts(ctx context.Context, now time.Time) ([]LockedAccount, error) {
+	var rows []LockedAccount
+	err := r.db.WithContext(ctx).
+		Table("auth_account_lockouts AS l").
+		Select("l.user_id, u.name, u.email, l.lockouts, l.locked_until").
+		Joins("JOIN users u ON u.id = l.user_id").
+		Where("l.locked_until > ?", now).
+		Order("l.locked_until DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// AttemptCount is a number of attempts grouped by a key (IP or email)
+type AttemptCount struct {
+	Key   string
+	Count int64
+}
+
+// CountLoginAttempts returns successful and failed attempts since a time
+func (r *Repo) CountLoginAttempts(ctx context.Context, since time.Time) (succeeded, failed int64, err error) {
+	var row struct {
+		Succeeded int64
+		Failed    int64
+	}
+	err = r.db.WithContext(ctx).Model(&LoginAttempt{}).
+		Select("COALESCE(SUM(success = 1), 0) AS succeeded, COALESCE(SUM(success = 0), 0) AS failed").
+		Where("created_at >= ?", since).
+		Scan(&row).Error
+	return row.Succeeded, row.Failed, err
+}
+
+// TopFailures returns the IPs or emails (column "ip" or "email") with the most counted
+// failures since a time
+func (r *Repo) TopFailures(ctx context.Context, column string, since time.Time, limit int) ([]AttemptCount, error) {
+	var rows []AttemptCount
+	err := r.db.WithContext(ctx).Model(&LoginAttempt{}).
+		Select(column+" AS `key`, COUNT(*) AS count").
+		Where("created_at >= ? AND result IN ?", since, countedFailures).
+		Group(column).
+		Order("count DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CountAdminTwoFactor returns the number of active admins and how many have 2FA enabled
+func (r *Repo) CountAdminTwoFactor(ctx context.Context) (admins, enrolled int64, err error) {
+	var row struct {
+		Admins   int64
+		Enrolled int64
+	}
+	err = r.db.WithContext(ctx).
+		Table("users AS u").
+		Select("COUNT(*) AS admins, COUNT(tf.enabled_at) AS enrolled").
+		Joins("LEFT JOIN auth_two_factor tf ON tf.user_id = u.id").
+		Where("u.role = 'admin' AND u.status = 'active' AND u.deleted_at IS NULL").
+		Scan(&row).Error
+	return row.Admins, row.Enrolled, err
+}
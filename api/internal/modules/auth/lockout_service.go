@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/pkg/response"
+
+	"go.uber.org/zap"
+)
+
+// securityOverviewTop is how many IPs / emails the overview lists
+const securityOverviewTop = 10
+
+// SecurityOverview summarises login activity for admins
+type SecurityOverview struct {
+	Since                 time.Time
+	Succeeded             int64
+	Failed                int64
+	LockedAccounts        []LockedAccount
+	TopFailingIPs         []AttemptCount
+	TopTargetedEmails     []AttemptCount
+	Admins                int64
+	AdminsWithTwoFactor   int64
+	RequireAdminTwoFactor bool
+}
+
+// checkIPBlocked rejects the attempt when its IP has too many recent failures
+func (s *Service) checkIPBlocked(ctx context.Context, email string, client ClientInfo) error {
+	if s.cfg.Auth.IPFailureMax <= 0 || client.IP == "" {
+		return nil
+	}
+	n, err := s.repo.CountIPFailures(ctx, client.IP, time.Now().Add(-s.cfg.Auth.IPFailureWindow))
+	if err != nil {
+		return response.Internal(err)
+	}
+	if n < int64(s.cfg.Auth.IPFailureMax) {
+		return nil
+	}
+	s.recordAttempt(ctx, nil, email, client, LoginIPBlocked)
+	return response.New("rate_limited", "Too many failed sign-in attempts, try again later", http.StatusTooManyRequests)
+}
+
+// checkLocked rejects the attempt while the account is locked, without checking the password
+func (s *Service) checkLocked(ctx context.Context, u *user.User, email string, client ClientInfo) error {
+	l, err := s.repo.GetLockout(ctx, u.ID)
+	if err != nil {
+		return response.Internal(err)
+	}
+	if !l.IsLocked(time.Now()) {
+		return nil
+	}
+	s.recordAttempt(ctx, &u.ID, email, client, LoginAccountLocked)
+	return lockedError(*l.LockedUntil)
+}
+
+// loginFailed records a failed attempt on a known account and locks it when the threshold
+// is reached. Returns the error to send back.
+func (s *Service) loginFailed(ctx context.Context, u *user.User, email string, client ClientInfo, result string, cause error) error {
+	s.recordAttempt(ctx, &u.ID, email, client, result)
+	if s.cfg.Auth.LockoutThreshold <= 0 {
+		return cause
+	}
+	l, err := s.repo.RecordLoginFailure(ctx, u.ID, s.cfg.Auth.LockoutThreshold, s.lockDuration)
+	if err != nil {
+		s.logger.Error("failed to record login failure", zap.Uint("userID", u.ID), zap.Error(err))
+		return cause
+	}
+	if l.FailedCount == 0 && l.IsLocked(time.Now()) {
+		s.logger.Warn("account locked after failed logins",
+			zap.Uint("userID", u.ID),
+			zap.Int("lockouts", l.Lockouts),
+			zap.Time("lockedUntil", *l.LockedUntil))
+		return lockedError(*l.LockedUntil)
+	}
+	return cause
+}
+
+// loginSucceeded records the successful attempt and clears the failure count
+func (s *Service) loginSucceeded(ctx context.Context, u *user.User, client ClientInfo) {
+	s.recordAttempt(ctx, &u.ID, u.Email, client, LoginSucceeded)
+	if _, err := s.repo.ClearLockout(ctx, u.ID); err != nil {
+		s.logger.Error("failed to clear lockout", zap.Uint("userID", u.ID), zap.Error(err))
+	}
+}
+
+// recordAttempt stores a login attempt; failing to store it does not fail the login
+func (s *Service) recordAttempt(ctx context.Context, userID *uint, email string, client ClientInfo, result string) {
+	a := &LoginAttempt{
+		UserID:    userID,
+		Email:     truncate(email, 190),
+		IP:        truncate(client.IP, 64),
+		UserAgent: truncate(client.UserAgent, 255),
+		Success:   result == LoginSucceeded,
+		Result:    result,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.CreateLoginAttempt(ctx, a); err != nil {
+		s.logger.Error("failed to record login attempt", zap.String("result", result), zap.Error(err))
+	}
+}
+
+// lockDuration is LockoutBase doubled for each lock after the first, capped at LockoutMax
+func (s *Service) lockDuration(n int) time.Duration {
+	d := float64(s.cfg.Auth.LockoutBase) * math.Pow(2, float64(n-1))
+	if limit := float64(s.cfg.Auth.LockoutMax); limit > 0 && d > limit {
+		return s.cfg.Auth.LockoutMax
+	}
+	return time.Duration(d)
+}
+
+func lockedError(until time.Time) error {
+	minutes := int(math.Ceil(time.Until(until).Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+	return response.New("account_locked",
+		fmt.Sprintf("Too many failed sign-in attempts. The account is locked for %d more minute(s)", minutes),
+		http.StatusLocked)
+}
+
+// ListLoginHistory returns login attempts matching the filter
+func (s *Service) ListLoginHistory(ctx context.Context, f LoginAttemptFilter) ([]LoginAttempt, int64, error) {
+	rows, total, err := s.repo.ListLoginAttempts(ctx, f)
+	if err != nil {
+		return nil, 0, response.Internal(err)
+	}
+	return rows, total, nil
+}
+
+// UnlockAccount clears a user's lock and failure count. Returns false if the account was not locked.
+func (s *Service) UnlockAccount(ctx context.Context, userID uint) (bool, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return false, response.NotFound("User not found")
+	}
+	l, err := s.repo.GetLockout(ctx, userID)
+	if err != nil {
+		return false, response.Internal(err)
+	}
+	if _, err := s.repo.ClearLockout(ctx, userID); err != nil {
+		return false, response.Internal(err)
+	}
+	return l.IsLocked(time.Now()), nil
+}
+
+// SecurityOverview summarises the last 24 hours of login activity
+func (s *Service) SecurityOverview(ctx context.Context) (*SecurityOverview, error) {
+	now := time.Now()
+	ov := &SecurityOverview{Since: now.Add(-24 * time.Hour)}
+
+	var err error
+	if ov.Succeeded, ov.Failed, err = s.repo.CountLoginAttempts(ctx, ov.Since); err != nil {
+		return nil, response.Internal(err)
+	}
+	if ov.LockedAccounts, err = s.repo.ListLockedAccounts(ctx, now); err != nil {
+		return nil, response.Internal(err)
+	}
+	if ov.TopFailingIPs, err = s.repo.TopFailures(ctx, "ip", ov.Since, securityOverviewTop); err != nil {
+		return nil, response.Internal(err)
+	}
+	if ov.TopTargetedEmails, err = s.repo.TopFailures(ctx, "email", ov.Since, securityOverviewTop); err != nil {
+		return nil, response.Internal(err)
+	}
+	if ov.Admins, ov.AdminsWithTwoFactor, err = s.repo.CountAdminTwoFactor(ctx); err != nil {
+		return nil, response.Internal(err)
+	}
+	p, err := s.GetSecurityPolicy(ctx)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	ov.RequireAdminTwoFactor = p.RequireAdminTwoFactor
+	return ov, nil
+}
@@ -0,0 +1,71 @@
+package auth
+
+import "time"
+
+// Session is one login of a user: the family of refresh tokens rotated from the token issued
+// at login (table auth_sessions). Revoking the session invalidates every token of the family.
+type Session struct {
+	ID            uint       `gorm:"primaryKey"`
+	UserID        uint       `gorm:"not null;index"`
+	UserAgent     string     `gorm:"type:varchar(255);not null;default:''"`
+	IP            string     `gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt     time.Time  `gorm:"not null"`
+	LastSeenAt    time.Time  `gorm:"not null"`
+	ExpiresAt     time.Time  `gorm:"not null;index"` // expiry of the latest refresh token
+	RevokedAt     *time.Time `gorm:"index"`
+	RevokedReason *string    `gorm:"type:varchar(32)"`
+	TwoFactorAt   *time.Time // when the session passed the TOTP step (nil = password only)
+}
+
+func (Session) TableName() string {
+	return "auth_sessions"
+}
+
+// Why a session was revoked
+const (
+	RevokeLogout         = "LOGOUT"
+	RevokeUser           = "USER_REVOKED"   // revoked by the user from the session list
+	RevokeReuseDetected  = "REUSE_DETECTED" // an already rotated refresh token was presented again
+	RevokePasswordReset  = "PASSWORD_RESET"
+	RevokePasswordChange = "PASSWORD_CHANGE" // other sessions, after the user changed the password
+	RevokeTwoFactorReset = "2FA_RESET"       // an admin reset the user's two-factor authentication
+	RevokeOffboarded     = "OFFBOARDED"      // the user left the company
+)
+
+// IsActive reports whether the session can still be refreshed
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken is one issued refresh token of a session (table auth_refresh_tokens).
+// Only the SHA-256 of its jti is stored. A token is used once: refreshing marks it used
+// and issues its replacement.
+type RefreshToken struct {
+	ID           uint      `gorm:"primaryKey"`
+	SessionID    uint      `gorm:"not null;index"`
+	TokenHash    string    `gorm:"type:char(64);not null;uniqueIndex"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	UsedAt       *time.Time
+	ReplacedByID *uint
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+func (RefreshToken) TableName() string {
+	return "auth_refresh_tokens"
+}
+
+// PasswordReset is an emailed, single-use password reset token (table auth_password_resets).
+// Only the SHA-256 of the token is stored.
+type PasswordReset struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      uint      `gorm:"not null;index"`
+	TokenHash   string    `gorm:"type:char(64);not null;uniqueIndex"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	UsedAt      *time.Time
+	RequestedIP string    `gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt   time.Time `gorm:"not null;index"`
+}
+
+func (PasswordReset) TableName() string {
+	return "auth_password_resets"
+}
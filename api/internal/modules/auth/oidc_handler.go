@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"net/url"
+
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /api/v1/auth/oidc/config
+// Tells the login page whether to show the SSO button
+func (h *Handler) OIDCConfig(c *fiber.Ctx) error {
+	res := OIDCConfigResponse{Enabled: h.svc.OIDCEnabled()}
+	if res.Enabled {
+		res.LoginURL = "/api/v1/auth/oidc/login"
+	}
+	return response.OK(c, res)
+}
+
+// GET /api/v1/auth/oidc/login?returnTo=/path
+// Redirects the browser to the identity provider
+func (h *Handler) OIDCLogin(c *fiber.Ctx) error {
+	authURL, state, err := h.svc.BeginOIDCLogin(c.Context(), c.Query("returnTo"))
+	if err != nil {
+		return err
+	}
+	h.cookies.SetOIDCState(c, state, h.svc.OIDCStateTTL())
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// GET /api/v1/auth/oidc/callback?code=&state=
+// The identity provider redirects here. On success the session cookies are set and the browser
+// lands on the frontend; on failure it lands there with ?ssoError=<code>. Accounts with local
+// 2FA land with #twoFactorChallenge=<challenge> for POST /auth/login/2fa.
+func (h *Handler) OIDCCallback(c *fiber.Ctx) error {
+	state := c.Query("state")
+	cookieState := h.cookies.OIDCState(c)
+	h.cookies.ClearOIDCState(c)
+
+	if c.Query("error") != "" {
+		return h.ssoFailed(c, "", "idp_error")
+	}
+	if state == "" || c.Query("code") == "" || state != cookieState {
+		return h.ssoFailed(c, "", "state_mismatch")
+	}
+
+	res, returnTo, err := h.svc.CompleteOIDCLogin(c.Context(), state, c.Query("code"), clientInfo(c))
+	if err != nil {
+		code := response.CodeInternal
+		if ae, ok := response.IsAppError(err); ok {
+			code = ae.Code
+		}
+		return h.ssoFailed(c, returnTo, code)
+	}
+	if res.Challenge != "" {
+		return c.Redirect(h.svc.FrontendURL(returnTo, nil, url.Values{"twoFactorChallenge": {res.Challenge}}), fiber.StatusFound)
+	}
+
+	h.cookies.SetAccessToken(c, res.AccessToken)
+	h.cookies.SetRefreshToken(c, res.RefreshToken)
+	return c.Redirect(h.svc.FrontendURL(returnTo, nil, nil), fiber.StatusFound)
+}
+
+func (h *Handler) ssoFailed(c *fiber.Ctx, returnTo, code string) error {
+	return c.Redirect(h.svc.FrontendURL(returnTo, url.Values{"ssoError": {code}}, nil), fiber.StatusFound)
+}
@@ -0,0 +1,36 @@
+package auth
+
+import "time"
+
+// OIDCLoginState is an SSO login in progress, between the redirect to the identity provider
+// and its callback (table auth_oidc_states). Keyed by the SHA-256 of the state parameter.
+type OIDCLoginState struct {
+	ID           uint      `gorm:"primaryKey"`
+	StateHash    string    `gorm:"type:char(64);not null;uniqueIndex"`
+	Nonce        string    `gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null"`
+	ReturnTo     string    `gorm:"type:varchar(255);not null;default:''"` // frontend path to land on
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+func (OIDCLoginState) TableName() string {
+	return "auth_oidc_states"
+}
+
+// UserIdentity links a user to an identity provider account (table auth_identities).
+// The first SSO login matches by email; later ones by issuer + subject, so a changed
+// email at the provider still reaches the same user.
+type UserIdentity struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      uint      `gorm:"not null;index"`
+	Issuer      string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_identity_issuer_subject,priority:1"`
+	Subject     string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_identity_issuer_subject,priority:2"`
+	Email       string    `gorm:"type:varchar(190);not null;default:''"`
+	CreatedAt   time.Time `gorm:"not null"`
+	LastLoginAt time.Time `gorm:"not null"`
+}
+
+func (UserIdentity) TableName() string {
+	return "auth_identities"
+}
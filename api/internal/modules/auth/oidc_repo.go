@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SSO methods
+
+// CreateOIDCState stores an SSO login in progress
+func (r *Repo) CreateOIDCState(ctx context.Context, s *OIDCLoginState) error {
+	return r.db.WithContext(ctx).Create(s).Error
+}
+
+// ConsumeOIDCState deletes and returns the unexpired login state with this hash.
+// Returns nil if it is unknown, already used or expired.
+func (r *Repo) ConsumeOIDCState(ctx context.Context, hash string) (*OIDCLoginState, error) {
+	var found *OIDCLoginState
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []OIDCLoginState
+		if err := tx.Where("state_hash = ? AND expires_at > ?", hash, time.Now()).Limit(1).Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		res := tx.Delete(&OIDCLoginState{}, rows[0].ID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			found = &rows[0]
+		}
+		return nil
+	})
+	return found, err
+}
+
+// PurgeOIDCStates deletes login states that expired before cutoff (abandoned logins)
+func (r *Repo) PurgeOIDCStates(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at < ?", cutoff).Delete(&OIDCLoginState{})
+	return res.RowsAffected, res.Error
+}
+
+// GetIdentity returns the identity for issuer + subject, or nil if it is not linked
+func (r *Repo) GetIdentity(ctx context.Context, issuer, subject string) (*UserIdentity, error) {
+	var rows []UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		Limit(1).
+		Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// SaveIdentity creates or updates an identity link
+func (r *Repo) SaveIdentity(ctx context.Context, id *UserIdentity) error {
+	return r.db.WithContext(ctx).Save(id).Error
+}
@@ -0,0 +1,284 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/pkg/response"
+	"time-attendance-be/internal/pkg/security"
+	"time-attendance-be/internal/platform/oidc"
+
+	"go.uber.org/zap"
+)
+
+// oidcStateTTL is how long the user has to finish signing in at the identity provider
+const oidcStateTTL = 10 * time.Minute
+
+// LoginSSOFailed is recorded when the identity provider round trip fails (bad code, invalid token)
+const LoginSSOFailed = "SSO_FAILED"
+
+// SetOIDCProvider enables single sign-on through an OpenID provider
+func (s *Service) SetOIDCProvider(p *oidc.Provider) {
+	s.oidc = p
+}
+
+// OIDCEnabled reports whether single sign-on is configured
+func (s *Service) OIDCEnabled() bool {
+	return s.oidc != nil
+}
+
+// BeginOIDCLogin starts an SSO login: it stores the state, nonce and PKCE verifier and returns
+// the identity provider URL to redirect to, plus the state to bind to the browser.
+func (s *Service) BeginOIDCLogin(ctx context.Context, returnTo string) (authURL, state string, err error) {
+	if s.oidc == nil {
+		return "", "", response.NotFound("Single sign-on is not enabled")
+	}
+
+	state, err = oidc.RandomString(32)
+	if err != nil {
+		return "", "", response.Internal(err)
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", response.Internal(err)
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return "", "", response.Internal(err)
+	}
+
+	authURL, err = s.oidc.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		s.logger.Error("oidc discovery failed", zap.Error(err))
+		return "", "", response.New("sso_unavailable", "Single sign-on is unavailable", http.StatusBadGateway)
+	}
+
+	now := time.Now()
+	if err := s.repo.CreateOIDCState(ctx, &OIDCLoginState{
+		StateHash:    hashTokenID(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ReturnTo:     safeReturnTo(returnTo),
+		ExpiresAt:    now.Add(oidcStateTTL),
+		CreatedAt:    now,
+	}); err != nil {
+		return "", "", response.Internal(err)
+	}
+	return authURL, state, nil
+}
+
+// OIDCStateTTL is how long the state cookie must live
+func (s *Service) OIDCStateTTL() time.Duration {
+	return oidcStateTTL
+}
+
+// CompleteOIDCLogin handles the identity provider callback: it exchanges the code, validates
+// the ID token, maps the identity to a user (provisioning one if enabled) and starts a session.
+// Returns the login result and the frontend path to land on.
+func (s *Service) CompleteOIDCLogin(ctx context.Context, state, code string, client ClientInfo) (*LoginResult, string, error) {
+	if s.oidc == nil {
+		return nil, "", response.NotFound("Single sign-on is not enabled")
+	}
+	st, err := s.repo.ConsumeOIDCState(ctx, hashTokenID(state))
+	if err != nil {
+		return nil, "", response.Internal(err)
+	}
+	if st == nil {
+		return nil, "", response.Unauthorized("Sign-in expired, try again")
+	}
+	if err := s.checkIPBlocked(ctx, "", client); err != nil {
+		return nil, st.ReturnTo, err
+	}
+
+	raw, err := s.oidc.Exchange(ctx, code, st.CodeVerifier)
+	if err == nil {
+		var claims *oidc.Claims
+		if claims, err = s.oidc.VerifyIDToken(ctx, raw, st.Nonce); err == nil {
+			res, err := s.loginWithIdentity(ctx, claims, client)
+			return res, st.ReturnTo, err
+		}
+	}
+	s.logger.Warn("oidc login failed", zap.String("ip", client.IP), zap.Error(err))
+	s.recordAttempt(ctx, nil, "", client, LoginSSOFailed)
+	return nil, st.ReturnTo, response.Unauthorized("Single sign-on failed")
+}
+
+func (s *Service) loginWithIdentity(ctx context.Context, claims *oidc.Claims, client ClientInfo) (*LoginResult, error) {
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" {
+		return nil, response.Forbidden("The identity provider did not share an email address")
+	}
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return nil, response.Forbidden("Email address is not verified at the identity provider")
+	}
+	if !s.emailDomainAllowed(email) {
+		s.recordAttempt(ctx, nil, email, client, LoginUnknownUser)
+		return nil, response.Forbidden("This email domain cannot sign in")
+	}
+
+	u, err := s.resolveIdentityUser(ctx, claims, email)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		s.recordAttempt(ctx, nil, email, client, LoginUnknownUser)
+		return nil, response.Forbidden("No account exists for " + email)
+	}
+	if !u.IsActive() {
+		s.recordAttempt(ctx, &u.ID, email, client, LoginAccountBlocked)
+		return nil, response.Forbidden("Account is disabled")
+	}
+	// A locked account stays locked for single sign-on too
+	if err := s.checkLocked(ctx, u, email, client); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	identity, err := s.repo.GetIdentity(ctx, s.oidc.Issuer(), claims.Subject)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if identity == nil {
+		identity = &UserIdentity{UserID: u.ID, Issuer: s.oidc.Issuer(), Subject: claims.Subject, CreatedAt: now}
+	}
+	identity.Email = truncate(email, 190)
+	identity.LastLoginAt = now
+	if err := s.repo.SaveIdentity(ctx, identity); err != nil {
+		return nil, response.Internal(err)
+	}
+
+	// Multi-factor at the provider counts as our second factor; otherwise users with local
+	// 2FA still have to enter a code
+	idpMFA := hasMFA(claims.AMR)
+	if !idpMFA {
+		tf, err := s.repo.GetTwoFactor(ctx, u.ID)
+		if err != nil {
+			return nil, response.Internal(err)
+		}
+		if tf.Enabled() {
+			challenge, err := s.jwtMgr.GenerateTwoFactorChallenge(u.ID, u.Role)
+			if err != nil {
+				return nil, response.Internal(err)
+			}
+			return &LoginResult{User: u, Challenge: challenge}, nil
+		}
+	}
+
+	accessToken, refreshToken, err := s.startSession(ctx, u, client, idpMFA)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	s.loginSucceeded(ctx, u, client)
+	return &LoginResult{User: u, AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// resolveIdentityUser finds the user linked to the identity, else the user with its email,
+// else provisions one when enabled. Returns nil if there is no user.
+func (s *Service) resolveIdentityUser(ctx context.Context, claims *oidc.Claims, email string) (*user.User, error) {
+	identity, err := s.repo.GetIdentity(ctx, s.oidc.Issuer(), claims.Subject)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if identity != nil {
+		if u, err := s.userRepo.GetByID(ctx, identity.UserID); err == nil {
+			return u, nil
+		}
+	}
+
+	if u, err := s.userRepo.GetByEmail(ctx, email); err == nil {
+		return u, nil
+	}
+	if !s.cfg.OIDC.AutoProvision {
+		return nil, nil
+	}
+	return s.provisionUser(ctx, claims, email)
+}
+
+// provisionUser creates a user for a first SSO login. The password is random and unknown,
+// so the account can only sign in through SSO until a password is reset.
+func (s *Service) provisionUser(ctx context.Context, claims *oidc.Claims, email string) (*user.User, error) {
+	secret, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	hash, err := security.HashPassword(secret)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+	if name == "" {
+		name = strings.SplitN(email, "@", 2)[0]
+	}
+	role := "user"
+	if s.cfg.OIDC.DefaultRole == "admin" {
+		role = "admin"
+	}
+	u := &user.User{
+		Name:         truncate(name, 120),
+		Email:        email,
+		PasswordHash: hash,
+		Role:         role,
+		Status:       "active",
+	}
+	if s.cfg.OIDC.DefaultDepartmentID > 0 {
+		deptID := uint(s.cfg.OIDC.DefaultDepartmentID)
+		u.DepartmentID = &deptID
+	}
+	if err := s.userRepo.Create(ctx, u); err != nil {
+		return nil, response.Internal(err)
+	}
+	s.logger.Info("user provisioned from single sign-on", zap.Uint("userID", u.ID), zap.String("email", email))
+	return u, nil
+}
+
+func (s *Service) emailDomainAllowed(email string) bool {
+	if strings.TrimSpace(s.cfg.OIDC.AllowedDomains) == "" {
+		return true
+	}
+	domain := email[strings.LastIndex(email, "@")+1:]
+	for _, d := range strings.Split(s.cfg.OIDC.AllowedDomains, ",") {
+		if strings.EqualFold(strings.TrimSpace(d), domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// FrontendURL is the frontend page to send the browser to after the callback
+func (s *Service) FrontendURL(returnTo string, query url.Values, fragment url.Values) string {
+	u := strings.TrimRight(s.cfg.OIDC.PostLoginURL, "/") + safeReturnTo(returnTo)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	if len(fragment) > 0 {
+		u += "#" + fragment.Encode()
+	}
+	return u
+}
+
+// safeReturnTo keeps only same-site relative paths, so the callback can't redirect elsewhere
+func safeReturnTo(p string) string {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.ContainsAny(p, "\\\r\n") || len(p) > 255 {
+		return "/"
+	}
+	return p
+}
+
+// hasMFA reports whether the provider says the user signed in with multiple factors
+// (amr "mfa", RFC 8176)
+func hasMFA(amr []string) bool {
+	for _, m := range amr {
+		if m == "mfa" {
+			return true
+		}
+	}
+	return false
+}
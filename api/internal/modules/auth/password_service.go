@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/pkg/response"
+	"time-attendance-be/internal/pkg/security"
+	"time-attendance-be/internal/platform/mailer"
+
+	"go.uber.org/zap"
+)
+
+// Password rules, same as the admin user form (user.CreateUserRequest)
+const (
+	passwordMinLength = 8
+	passwordMaxLength = 100
+)
+
+// At most this many reset emails per user per hour; further requests are silently ignored
+const passwordResetsPerHour = 3
+
+// ChangePassword changes the user's password after verifying the current one.
+// The user's other sessions are signed out; the current one stays.
+func (s *Service) ChangePassword(ctx context.Context, userID, sessionID uint, current, next string) error {
+	if err := validatePassword(next); err != nil {
+		return err
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return response.Internal(err)
+	}
+	if err := security.ComparePassword(u.PasswordHash, current); err != nil {
+		return response.Validation("Current password is incorrect", nil)
+	}
+	if current == next {
+		return response.Validation("New password must differ from the current one", nil)
+	}
+
+	hash, err := security.HashPassword(next)
+	if err != nil {
+		return response.Internal(err)
+	}
+	if err := s.userRepo.UpdatePassword(ctx, u.ID, hash); err != nil {
+		return response.Internal(err)
+	}
+	if _, err := s.repo.RevokeUserSessions(ctx, u.ID, sessionID, RevokePasswordChange); err != nil {
+		s.logger.Error("failed to revoke sessions after password change", zap.Uint("userID", u.ID), zap.Error(err))
+	}
+	return nil
+}
+
+// passwordResetSendTimeout bounds the background work of one forgot-password request
+const passwordResetSendTimeout = 30 * time.Second
+
+// ForgotPassword emails a reset link to the account with this email, if there is an active one.
+// It never reveals whether the account exists: the lookup and the email happen in the background,
+// so the response and its timing are the same either way, and failures are only logged.
+func (s *Service) ForgotPassword(ctx context.Context, email string, client ClientInfo) error {
+	// Request values may point into buffers the HTTP server reuses once the handler returns
+	email = strings.Clone(email)
+	client = ClientInfo{UserAgent: strings.Clone(client.UserAgent), IP: strings.Clone(client.IP)}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), passwordResetSendTimeout)
+		defer cancel()
+		if err := s.sendPasswordReset(ctx, email, client); err != nil {
+			s.logger.Error("failed to send password reset email", zap.String("ip", client.IP), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// sendPasswordReset creates a reset token for the active account with this email and mails the link
+func (s *Service) sendPasswordReset(ctx context.Context, email string, client ClientInfo) error {
+	u, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil || !u.IsActive() {
+		return nil
+	}
+
+	recent, err := s.repo.CountPasswordResets(ctx, u.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if recent >= passwordResetsPerHour {
+		s.logger.Warn("password reset throttled", zap.Uint("userID", u.ID), zap.String("ip", client.IP))
+		return nil
+	}
+
+	token, err := newResetToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	reset := &PasswordReset{
+		UserID:      u.ID,
+		TokenHash:   hashTokenID(token),
+		ExpiresAt:   now.Add(s.cfg.Auth.PasswordResetTTL),
+		RequestedIP: truncate(client.IP, 64),
+		CreatedAt:   now,
+	}
+	if err := s.repo.CreatePasswordReset(ctx, reset); err != nil {
+		return err
+	}
+
+	link := s.cfg.Auth.PasswordResetURL + "?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      []string{u.Email},
+		Subject: "Reset your password",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"We received a request to reset your password. Open this link to choose a new one:\n\n%s\n\n"+
+			"The link expires in %d minutes and can be used once. "+
+			"If you did not request this, you can ignore this email.\n",
+			u.Name, link, int(s.cfg.Auth.PasswordResetTTL.Minutes())),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send reset email to user %d: %w", u.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password with an emailed reset token and signs out every session
+func (s *Service) ResetPassword(ctx context.Context, token, next string) error {
+	if err := validatePassword(next); err != nil {
+		return err
+	}
+	reset, err := s.repo.ConsumePasswordReset(ctx, hashTokenID(token))
+	if err != nil {
+		return response.Internal(err)
+	}
+	if reset == nil {
+		return response.Validation("Reset link is invalid or has expired", nil)
+	}
+
+	hash, err := security.HashPassword(next)
+	if err != nil {
+		return response.Internal(err)
+	}
+	if err := s.userRepo.UpdatePassword(ctx, reset.UserID, hash); err != nil {
+		return response.Internal(err)
+	}
+	n, err := s.repo.RevokeUserSessions(ctx, reset.UserID, 0, RevokePasswordReset)
+	if err != nil {
+		s.logger.Error("failed to revoke sessions after password reset", zap.Uint("userID", reset.UserID), zap.Error(err))
+	}
+	s.logger.Info("password reset", zap.Uint("userID", reset.UserID), zap.Int64("revokedSessions", n))
+	return nil
+}
+
+func validatePassword(p string) error {
+	if len(p) < passwordMinLength || len(p) > passwordMaxLength {
+		return response.Validation(fmt.Sprintf("Password must be %d to %d characters", passwordMinLength, passwordMaxLength), nil)
+	}
+	return nil
+}
+
+func newResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
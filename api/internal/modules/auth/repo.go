@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrTokenAlreadyUsed is returned by Rotate when the refresh token was rotated concurrently
+var ErrTokenAlreadyUsed = errors.New("refresh token already used")
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo { return &Repo{db: db} }
+
+// CreateSession stores a new session
+func (r *Repo) CreateSession(ctx context.Context, s *Session) error {
+	return r.db.WithContext(ctx).Create(s).Error
+}
+
+// GetSession returns a session by ID
+func (r *Repo) GetSession(ctx context.Context, id uint) (*Session, error) {
+	var s Session
+	if err := r.db.WithContext(ctx).First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SessionState reports whether a session exists, is not revoked and has not expired,
+// and whether it passed the TOTP step
+func (r *Repo) SessionState(ctx context.Context, id uint) (active, twoFactor bool, err error) {
+	var rows []Session
+	err = r.db.WithContext(ctx).
+		Select("id", "two_factor_at").
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", id, time.Now()).
+		Limit(1).
+		Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return false, false, err
+	}
+	return true, rows[0].TwoFactorAt != nil, nil
+}
+
+// ListActiveSessions returns a user's active sessions, most recently seen first
+func (r *Repo) ListActiveSessions(ctx context.Context, userID uint) ([]Session, error) {
+	var rows []Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// RevokeSession revokes one active session; returns false if it was not active
+func (r *Repo) RevokeSession(ctx context.Context, id uint, reason string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return res.RowsAffected > 0, res.Error
+}
+
+// RevokeUserSessions revokes all active sessions of a user except exceptID (0 = none)
+func (r *Repo) RevokeUserSessions(ctx context.Context, userID, exceptID uint, reason string) (int64, error) {
+	q := r.db.WithContext(ctx).Model(&Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptID != 0 {
+		q = q.Where("id <> ?", exceptID)
+	}
+	res := q.Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return res.RowsAffected, res.Error
+}
+
+// CreateRefreshToken stores the first refresh token of a session
+func (r *Repo) CreateRefreshToken(ctx context.Context, t *RefreshToken) error {
+	return r.db.WithContext(ctx).Create(t).Error
+}
+
+// GetRefreshToken returns a refresh token by the hash of its jti (nil if unknown)
+func (r *Repo) GetRefreshToken(ctx context.Context, hash string) (*RefreshToken, error) {
+	var rows []RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// Rotate marks old as used and stores its replacement in one transaction, touching the session.
+// Returns ErrTokenAlreadyUsed if another request rotated old first.
+func (r *Repo) Rotate(ctx context.Context, old, next *RefreshToken, userAgent, ip string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", old.ID).
+			Updates(map[string]interface{}{"used_at": now, "replaced_by_id": next.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrTokenAlreadyUsed
+		}
+		return tx.Model(&Session{}).Where("id = ?", old.SessionID).
+			Updates(map[string]interface{}{
+				"last_seen_at": now,
+				"expires_at":   next.ExpiresAt,
+				"user_agent":   userAgent,
+				"ip":           ip,
+			}).Error
+	})
+}
+
+// PurgeExpired deletes sessions (and their tokens) that expired or were revoked before cutoff
+func (r *Repo) PurgeExpired(ctx context.Context, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stale := tx.Model(&Session{}).Select("id").
+			Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff)
+		if err := tx.Where("session_id IN (?)", stale).Delete(&RefreshToken{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff).Delete(&Session{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, err
+}
+
+// CreatePasswordReset stores a reset token
+func (r *Repo) CreatePasswordReset(ctx context.Context, p *PasswordReset) error {
+	return r.db.WithContext(ctx).Create(p).Error
+}
+
+// CountPasswordResets counts reset tokens issued to a user since a time (throttling)
+func (r *Repo) CountPasswordResets(ctx context.Context, userID uint, since time.Time) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&PasswordReset{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&n).Error
+	return n, err
+}
+
+// ConsumePasswordReset marks an unused, unexpired reset token as used and invalidates the
+// user's other outstanding tokens. Returns nil if the token is unknown, used or expired.
+func (r *Repo) ConsumePasswordReset(ctx context.Context, hash string) (*PasswordReset, error) {
+	var consumed *PasswordReset
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var p PasswordReset
+		res := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hash, time.Now()).Limit(1).Find(&p)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		now := time.Now()
+		upd := tx.Model(&PasswordReset{}).
+			Where("id = ? AND used_at IS NULL", p.ID).
+			Update("used_at", now)
+		if upd.Error != nil || upd.RowsAffected == 0 {
+			return upd.Error
+		}
+		if err := tx.Model(&PasswordReset{}).
+			Where("user_id = ? AND used_at IS NULL", p.UserID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		p.UsedAt = &now
+		consumed = &p
+		return nil
+	})
+	return consumed, err
+}
@@ -1,23 +1,79 @@
 package auth
 
 import (
+	"time-attendance-be/internal/authx"
 	"time-attendance-be/internal/config"
+	"time-attendance-be/internal/middleware"
+	"time-attendance-be/internal/modules/audit"
 	platformauth "time-attendance-be/internal/platform/auth"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-type Module struct{ h *Handler }
+type Module struct {
+	h *Handler
+	// Per-IP limiter (in memory, per replica) shared by the login and password endpoints;
+	// the persistent per-account and per-IP checks are in Service.Login
+	passwordLimit fiber.Handler
+}
 
-func NewModule(svc *Service, cfg *config.Config) *Module {
+func NewModule(svc *Service, cfg *config.Config, auditSvc *audit.Service) *Module {
 	cookies := platformauth.NewCookieManager(cfg)
-	return &Module{h: NewHandler(svc, cookies)}
+	limit := func(c *fiber.Ctx) error { return c.Next() }
+	if cfg.Auth.AuthRateLimitEnabled {
+		limit = middleware.AuthRateLimit(cfg)
+	}
+	return &Module{h: NewHandler(svc, cookies, auditSvc), passwordLimit: limit}
 }
 
 func (m *Module) Register(v1 fiber.Router) {
 	g := v1.Group("/auth")
-	g.Post("/login", m.h.Login)
+	g.Post("/login", m.passwordLimit, m.h.Login)
+	g.Post("/login/2fa", m.passwordLimit, m.h.LoginTwoFactor)
 	g.Post("/logout", m.h.Logout)
 	g.Post("/refresh", m.h.Refresh)
+	g.Post("/password/forgot", m.passwordLimit, m.h.ForgotPassword)
+	g.Post("/password/reset", m.passwordLimit, m.h.ResetPassword)
+	g.Get("/oidc/config", m.h.OIDCConfig)
+	g.Get("/oidc/login", m.passwordLimit, m.h.OIDCLogin)
+	g.Get("/oidc/callback", m.passwordLimit, m.h.OIDCCallback)
+}
+
+func (m *Module) RegisterMe(v1 fiber.Router, auth fiber.Handler) {
+	g := v1.Group("/me/sessions", auth)
+	g.Get("/", m.h.ListMySessions)
+	g.Delete("/", m.h.RevokeMyOtherSessions)
+	g.Delete("/:id", m.h.RevokeMySession)
+
+	v1.Post("/me/password", auth, m.passwordLimit, m.h.ChangeMyPassword)
+	v1.Get("/me/login-history", auth, m.h.GetMyLoginHistory)
+
+	tf := v1.Group("/me/2fa", auth)
+	tf.Get("/", m.h.GetMyTwoFactor)
+	tf.Post("/setup", m.passwordLimit, m.h.SetupMyTwoFactor)
+	tf.Post("/enable", m.passwordLimit, m.h.EnableMyTwoFactor)
+	tf.Post("/disable", m.passwordLimit, m.h.DisableMyTwoFactor)
+	tf.Post("/recovery-codes", m.passwordLimit, m.h.RegenerateMyRecoveryCodes)
+
+	keys := v1.Group("/me/api-keys", auth)
+	keys.Get("/", m.h.ListMyAPIKeys)
+	keys.Get("/scopes", m.h.ListAPIKeyScopes)
+	keys.Post("/", m.h.CreateMyAPIKey)
+	keys.Delete("/:id", m.h.RevokeMyAPIKey)
+}
+
+func (m *Module) RegisterAdmin(admin fiber.Router) {
+	g := admin.Group("/auth", authx.Require(authx.PermSecurityManage))
+	g.Get("/security-policy", m.h.AdminGetSecurityPolicy)
+	g.Put("/security-policy", m.h.AdminUpdateSecurityPolicy)
+	g.Post("/users/:id/2fa/reset", m.h.AdminResetTwoFactor)
+	g.Post("/users/:id/unlock", m.h.AdminUnlockAccount)
+	g.Get("/login-attempts", m.h.AdminListLoginAttempts)
+	g.Get("/security-overview", m.h.AdminSecurityOverview)
+
+	keys := admin.Group("/api-keys", authx.Require(authx.PermSecurityManage))
+	keys.Get("/", m.h.AdminListAPIKeys)
+	keys.Post("/", m.h.AdminCreateServiceKey)
+	keys.Delete("/:id", m.h.AdminRevokeAPIKey)
 }
 
@@ -2,62 +2,183 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
 
 	"time-attendance-be/internal/config"
 	"time-attendance-be/internal/modules/user"
 	"time-attendance-be/internal/pkg/response"
 	"time-attendance-be/internal/pkg/security"
 	platformauth "time-attendance-be/internal/platform/auth"
+	"time-attendance-be/internal/platform/mailer"
+	"time-attendance-be/internal/platform/oidc"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// refreshReuseGrace tolerates a rotated refresh token presented again shortly after rotation
+// (parallel refreshes from two tabs) without treating it as theft. The request is still rejected.
+const refreshReuseGrace = 10 * time.Second
+
 type Service struct {
+	cfg      *config.Config
 	userRepo *user.Repo
+	repo     *Repo
 	jwtMgr   *platformauth.Manager
 	cookies  *platformauth.CookieManager
+	mailer   mailer.Mailer
+	oidc     *oidc.Provider // nil when single sign-on is disabled
+	perms    PermissionStore
+	logger   *zap.Logger
 }
 
-func NewService(cfg *config.Config, userRepo *user.Repo, jwtMgr *platformauth.Manager) *Service {
+func NewService(cfg *config.Config, userRepo *user.Repo, repo *Repo, jwtMgr *platformauth.Manager, mail mailer.Mailer, logger *zap.Logger) *Service {
 	return &Service{
+		cfg:      cfg,
 		userRepo: userRepo,
+		repo:     repo,
 		jwtMgr:   jwtMgr,
 		cookies:  platformauth.NewCookieManager(cfg),
+		mailer:   mail,
+		logger:   logger,
 	}
 }
 
-func (s *Service) Login(ctx context.Context, email, password string) (*user.User, string, string, error) {
+// ClientInfo identifies the device a session is used from
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// LoginResult is the outcome of a login step: either the session tokens, or a challenge
+// to send back with a TOTP code when the account has two-factor authentication enabled
+type LoginResult struct {
+	User         *user.User
+	AccessToken  string
+	RefreshToken string
+	Challenge    string
+}
+
+// Login checks the password. Failures are recorded per account and per IP; too many lock the
+// account or block the IP for a while.
+func (s *Service) Login(ctx context.Context, email, password string, client ClientInfo) (*LoginResult, error) {
+	if err := s.checkIPBlocked(ctx, email, client); err != nil {
+		return nil, err
+	}
+
 	u, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, "", "", response.Unauthorized("Invalid credentials")
+			s.recordAttempt(ctx, nil, email, client, LoginUnknownUser)
+			return nil, response.Unauthorized("Invalid credentials")
 		}
-		return nil, "", "", response.Internal(err)
+		return nil, response.Internal(err)
 	}
 
 	if !u.IsActive() {
-		return nil, "", "", response.Forbidden("Account is disabled")
+		s.recordAttempt(ctx, &u.ID, email, client, LoginAccountBlocked)
+		return nil, response.Forbidden("Account is disabled")
+	}
+
+	if err := s.checkLocked(ctx, u, email, client); err != nil {
+		return nil, err
 	}
 
 	if err := security.ComparePassword(u.PasswordHash, password); err != nil {
-		return nil, "", "", response.Unauthorized("Invalid credentials")
+		return nil, s.loginFailed(ctx, u, email, client, LoginBadPassword, response.Unauthorized("Invalid credentials"))
 	}
 
-	accessToken, refreshToken, err := s.jwtMgr.GenerateTokens(u.ID, u.Role)
+	tf, err := s.repo.GetTwoFactor(ctx, u.ID)
 	if err != nil {
-		return nil, "", "", response.Internal(err)
+		return nil, response.Internal(err)
+	}
+	if tf.Enabled() {
+		challenge, err := s.jwtMgr.GenerateTwoFactorChallenge(u.ID, u.Role)
+		if err != nil {
+			return nil, response.Internal(err)
+		}
+		return &LoginResult{User: u, Challenge: challenge}, nil
+	}
+
+	accessToken, refreshToken, err := s.startSession(ctx, u, client, false)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	s.loginSucceeded(ctx, u, client)
+
+	return &LoginResult{User: u, AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// startSession creates a session for a successful login and issues its first tokens
+func (s *Service) startSession(ctx context.Context, u *user.User, client ClientInfo, twoFactor bool) (string, string, error) {
+	now := time.Now()
+	var twoFactorAt *time.Time
+	if twoFactor {
+		twoFactorAt = &now
+	}
+	session := &Session{
+		UserID:      u.ID,
+		UserAgent:   truncate(client.UserAgent, 255),
+		IP:          truncate(client.IP, 64),
+		CreatedAt:   now,
+		LastSeenAt:  now,
+		ExpiresAt:   now.Add(s.jwtMgr.RefreshTokenTTL()),
+		TwoFactorAt: twoFactorAt,
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return "", "", err
 	}
 
-	return u, accessToken, refreshToken, nil
+	accessToken, refreshToken, refreshID, err := s.jwtMgr.GenerateTokens(u.ID, u.Role, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.repo.CreateRefreshToken(ctx, &RefreshToken{
+		SessionID: session.ID,
+		TokenHash: hashTokenID(refreshID),
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
 }
 
-func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (uint, string, string, error) {
+// RefreshToken rotates a refresh token: the presented token is used up and a new pair is issued.
+// Presenting a token that was already rotated means it leaked, so its whole session is revoked.
+func (s *Service) RefreshToken(ctx context.Context, refreshToken string, client ClientInfo) (uint, string, string, error) {
 	claims, err := s.jwtMgr.VerifyToken(refreshToken)
+	if err != nil || claims.TokenType != platformauth.TokenTypeRefresh {
+		return 0, "", "", response.Unauthorized("Invalid refresh token")
+	}
+
+	stored, err := s.repo.GetRefreshToken(ctx, hashTokenID(claims.ID))
+	if err != nil {
+		return 0, "", "", response.Internal(err)
+	}
+	if stored == nil {
+		return 0, "", "", response.Unauthorized("Invalid refresh token")
+	}
+
+	session, err := s.repo.GetSession(ctx, stored.SessionID)
 	if err != nil {
 		return 0, "", "", response.Unauthorized("Invalid refresh token")
 	}
+	now := time.Now()
+	if !session.IsActive(now) {
+		return 0, "", "", response.Unauthorized("Session has ended")
+	}
+	if stored.UsedAt != nil {
+		if now.Sub(*stored.UsedAt) > refreshReuseGrace {
+			s.revokeForReuse(ctx, session)
+		}
+		return 0, "", "", response.Unauthorized("Invalid refresh token")
+	}
 
-	u, err := s.userRepo.GetByID(ctx, uint(claims.UserID))
+	u, err := s.userRepo.GetByID(ctx, session.UserID)
 	if err != nil {
 		return 0, "", "", response.Unauthorized("User not found")
 	}
@@ -66,10 +187,102 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (uint,
 		return 0, "", "", response.Forbidden("Account is disabled")
 	}
 
-	accessToken, newRefreshToken, err := s.jwtMgr.GenerateTokens(u.ID, u.Role)
+	accessToken, newRefreshToken, refreshID, err := s.jwtMgr.GenerateTokens(u.ID, u.Role, session.ID)
 	if err != nil {
 		return 0, "", "", response.Internal(err)
 	}
+	next := &RefreshToken{
+		SessionID: session.ID,
+		TokenHash: hashTokenID(refreshID),
+		ExpiresAt: now.Add(s.jwtMgr.RefreshTokenTTL()),
+		CreatedAt: now,
+	}
+	if err := s.repo.Rotate(ctx, stored, next, truncate(client.UserAgent, 255), truncate(client.IP, 64)); err != nil {
+		if errors.Is(err, ErrTokenAlreadyUsed) {
+			return 0, "", "", response.Unauthorized("Invalid refresh token")
+		}
+		return 0, "", "", response.Internal(err)
+	}
 
 	return u.ID, accessToken, newRefreshToken, nil
 }
+
+func (s *Service) revokeForReuse(ctx context.Context, session *Session) {
+	if _, err := s.repo.RevokeSession(ctx, session.ID, RevokeReuseDetected); err != nil {
+		s.logger.Error("failed to revoke session after refresh token reuse", zap.Uint("sessionID", session.ID), zap.Error(err))
+		return
+	}
+	s.logger.Warn("refresh token reuse detected, session revoked",
+		zap.Uint("sessionID", session.ID),
+		zap.Uint("userID", session.UserID))
+}
+
+// Logout revokes the session of the presented refresh token. Invalid tokens are ignored:
+// logout always succeeds and clears the cookies.
+func (s *Service) Logout(ctx context.Context, refreshToken string) {
+	claims, err := s.jwtMgr.VerifyToken(refreshToken)
+	if err != nil || claims.SessionID == 0 {
+		return
+	}
+	if _, err := s.repo.RevokeSession(ctx, claims.SessionID, RevokeLogout); err != nil {
+		s.logger.Error("failed to revoke session on logout", zap.Uint("sessionID", claims.SessionID), zap.Error(err))
+	}
+}
+
+// ListSessions returns the user's active sessions
+func (s *Service) ListSessions(ctx context.Context, userID uint) ([]Session, error) {
+	return s.repo.ListActiveSessions(ctx, userID)
+}
+
+// RevokeSession revokes one of the user's own sessions
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	session, err := s.repo.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound("Session not found")
+		}
+		return response.Internal(err)
+	}
+	if session.UserID != userID {
+		return response.NotFound("Session not found")
+	}
+	if _, err := s.repo.RevokeSession(ctx, sessionID, RevokeUser); err != nil {
+		return response.Internal(err)
+	}
+	return nil
+}
+
+// RevokeOtherSessions revokes all of the user's sessions except the current one
+func (s *Service) RevokeOtherSessions(ctx context.Context, userID, currentID uint) (int64, error) {
+	n, err := s.repo.RevokeUserSessions(ctx, userID, currentID, RevokeUser)
+	if err != nil {
+		return 0, response.Internal(err)
+	}
+	return n, nil
+}
+
+// RevokeUserSessions revokes all of a user's sessions, e.g. when they are offboarded
+func (s *Service) RevokeUserSessions(ctx context.Context, userID uint, reason string) (int64, error) {
+	n, err := s.repo.RevokeUserSessions(ctx, userID, 0, reason)
+	if err != nil {
+		return 0, response.Internal(err)
+	}
+	return n, nil
+}
+
+// SessionState implements middleware.SessionStore
+func (s *Service) SessionState(ctx context.Context, sessionID uint) (active, twoFactor bool, err error) {
+	return s.repo.SessionState(ctx, sessionID)
+}
+
+func hashTokenID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"strconv"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /api/v1/me/2fa
+func (h *Handler) GetMyTwoFactor(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	st, err := h.svc.TwoFactorStatus(c.Context(), a.ID, a.Role, a.SessionID)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toTwoFactorStatusResponse(st))
+}
+
+// POST /api/v1/me/2fa/setup
+// Body: { "password": "..." }. Returns a new secret and otpauth URI to scan; confirm with /enable.
+func (h *Handler) SetupMyTwoFactor(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req TwoFactorSetupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	secret, uri, err := h.svc.BeginTwoFactorSetup(c.Context(), a.ID, req.Password)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, TwoFactorSetupResponse{Secret: secret, OTPAuthURI: uri})
+}
+
+// POST /api/v1/me/2fa/enable
+// Body: { "code": "123456" }. Returns the recovery codes, shown only this once.
+func (h *Handler) EnableMyTwoFactor(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+	if req.Code == "" {
+		return response.Validation("code is required", nil)
+	}
+
+	codes, err := h.svc.EnableTwoFactor(c.Context(), a.ID, a.SessionID, req.Code)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// POST /api/v1/me/2fa/disable
+// Body: { "password": "...", "code": "123456" }
+func (h *Handler) DisableMyTwoFactor(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req TwoFactorDisableRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+	if req.Code == "" {
+		return response.Validation("code is required", nil)
+	}
+
+	if err := h.svc.DisableTwoFactor(c.Context(), a.ID, a.Role, req.Password, req.Code); err != nil {
+		return err
+	}
+	return response.OK(c, true)
+}
+
+// POST /api/v1/me/2fa/recovery-codes
+// Body: { "code": "123456" }. Replaces all recovery codes.
+func (h *Handler) RegenerateMyRecoveryCodes(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+	if req.Code == "" {
+		return response.Validation("code is required", nil)
+	}
+
+	codes, err := h.svc.RegenerateRecoveryCodes(c.Context(), a.ID, req.Code)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// GET /api/v1/admin/auth/security-policy
+func (h *Handler) AdminGetSecurityPolicy(c *fiber.Ctx) error {
+	p, err := h.svc.GetSecurityPolicy(c.Context())
+	if err != nil {
+		return response.Internal(err)
+	}
+	return response.OK(c, toSecurityPolicyResponse(p))
+}
+
+// PUT /api/v1/admin/auth/security-policy
+// Body: { "requireAdminTwoFactor": true }
+func (h *Handler) AdminUpdateSecurityPolicy(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req SecurityPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+	if req.RequireAdminTwoFactor == nil {
+		return response.Validation("requireAdminTwoFactor is required", nil)
+	}
+
+	// Requiring 2FA from a session without it would lock the acting admin out
+	if *req.RequireAdminTwoFactor && !adminUser.TwoFactor {
+		return response.Validation("Sign in with two-factor authentication before requiring it for admins", nil)
+	}
+
+	before, err := h.svc.GetSecurityPolicy(c.Context())
+	if err != nil {
+		return response.Internal(err)
+	}
+	beforeRes := toSecurityPolicyResponse(before)
+
+	p, err := h.svc.UpdateSecurityPolicy(c.Context(), *req.RequireAdminTwoFactor, adminUser.ID)
+	if err != nil {
+		return err
+	}
+	res := toSecurityPolicyResponse(p)
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"UPDATE",
+			"auth_security_policy",
+			"1",
+			beforeRes,
+			res,
+			"",
+		)
+	}
+
+	return response.OK(c, res)
+}
+
+// POST /api/v1/admin/auth/users/:id/2fa/reset
+// For a lost authenticator: removes the user's 2FA and signs out all of their sessions
+func (h *Handler) AdminResetTwoFactor(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	reset, err := h.svc.ResetTwoFactor(c.Context(), uint(id))
+	if err != nil {
+		return err
+	}
+
+	if reset && h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"RESET_2FA",
+			"user",
+			c.Params("id"),
+			nil,
+			nil,
+			"",
+		)
+	}
+
+	return response.OK(c, map[string]interface{}{"reset": reset})
+}
@@ -0,0 +1,50 @@
+package auth
+
+import "time"
+
+// TwoFactor is a user's TOTP enrolment (table auth_two_factor). A row with EnabledAt nil
+// is a pending setup: the secret was shown but no code has confirmed it yet.
+type TwoFactor struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"not null;uniqueIndex"`
+	Secret       string `gorm:"type:varchar(64);not null"` // base32 TOTP secret
+	EnabledAt    *time.Time
+	LastUsedStep int64     `gorm:"not null;default:0"` // time step of the last accepted code; older or equal steps are replays
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (TwoFactor) TableName() string {
+	return "auth_two_factor"
+}
+
+// Enabled reports whether the enrolment was confirmed
+func (t *TwoFactor) Enabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the device is lost
+// (table auth_recovery_codes). Only the SHA-256 of the code is stored.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"type:char(64);not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "auth_recovery_codes"
+}
+
+// SecurityPolicy holds the admin-editable login rules (table auth_security_policy, single row ID 1)
+type SecurityPolicy struct {
+	ID                    uint `gorm:"primaryKey"`
+	RequireAdminTwoFactor bool `gorm:"type:tinyint(1);not null;default:0"` // admin routes need a session that passed 2FA
+	UpdatedBy             *uint
+	UpdatedAt             time.Time `gorm:"not null"`
+}
+
+func (SecurityPolicy) TableName() string {
+	return "auth_security_policy"
+}
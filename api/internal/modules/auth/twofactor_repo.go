@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Two-factor methods
+
+// GetTwoFactor returns the user's TOTP enrolment, or nil if there is none
+func (r *Repo) GetTwoFactor(ctx context.Context, userID uint) (*TwoFactor, error) {
+	var rows []TwoFactor
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// SaveTwoFactor creates or updates the user's enrolment
+func (r *Repo) SaveTwoFactor(ctx context.Context, t *TwoFactor) error {
+	return r.db.WithContext(ctx).Save(t).Error
+}
+
+// DeleteTwoFactor removes the user's enrolment and recovery codes
+func (r *Repo) DeleteTwoFactor(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&TwoFactor{}).Error
+	})
+}
+
+// UseTwoFactorStep records an accepted TOTP time step. It returns false if that step
+// (or a later one) was already used, so each code works once.
+func (r *Repo) UseTwoFactorStep(ctx context.Context, id uint, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&TwoFactor{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Updates(map[string]interface{}{"last_used_step": step, "updated_at": time.Now()})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores new ones
+func (r *Repo) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	now := time.Now()
+	rows := make([]RecoveryCode, len(hashes))
+	for i, h := range hashes {
+		rows[i] = RecoveryCode{UserID: userID, CodeHash: h, CreatedAt: now}
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code as used; returns false if none matched
+func (r *Repo) UseRecoveryCode(ctx context.Context, userID uint, hash string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Limit(1).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+// CountRecoveryCodes returns how many unused recovery codes the user has left
+func (r *Repo) CountRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&n).Error
+	return n, err
+}
+
+// MarkSessionTwoFactor records that a session passed the TOTP step
+func (r *Repo) MarkSessionTwoFactor(ctx context.Context, sessionID uint) error {
+	return r.db.WithContext(ctx).Model(&Session{}).
+		Where("id = ? AND two_factor_at IS NULL", sessionID).
+		Update("two_factor_at", time.Now()).Error
+}
+
+// GetSecurityPolicy returns the stored policy, or nil if it was never configured
+func (r *Repo) GetSecurityPolicy(ctx context.Context) (*SecurityPolicy, error) {
+	var p SecurityPolicy
+	err := r.db.WithContext(ctx).First(&p, "id = ?", 1).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SaveSecurityPolicy stores the policy (always row ID 1)
+func (r *Repo) SaveSecurityPolicy(ctx context.Context, p *SecurityPolicy) error {
+	p.ID = 1
+	return r.db.WithContext(ctx).Save(p).Error
+}
@@ -0,0 +1,375 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/pkg/response"
+	"time-attendance-be/internal/pkg/security"
+	platformauth "time-attendance-be/internal/platform/auth"
+
+	"go.uber.org/zap"
+)
+
+// recoveryCodeCount recovery codes are issued at enrolment and on regeneration
+const recoveryCodeCount = 10
+
+// recoveryAlphabet avoids look-alike characters (0/O, 1/I); 32 symbols so random bytes map without bias
+const recoveryAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// TwoFactorStatus is the user's 2FA state as shown on the security page
+type TwoFactorStatus struct {
+	Enabled            bool
+	EnabledAt          *time.Time
+	RecoveryCodesLeft  int64
+	Required           bool // the security policy requires 2FA for this user's role
+	SessionTwoFactored bool // the current session passed the TOTP step
+}
+
+// VerifyLoginTwoFactor completes a login started by Login: the challenge proves the password
+// step, the code is a TOTP code or a recovery code.
+func (s *Service) VerifyLoginTwoFactor(ctx context.Context, challenge, code string, client ClientInfo) (*LoginResult, error) {
+	claims, err := s.jwtMgr.VerifyToken(challenge)
+	if err != nil || claims.TokenType != platformauth.TokenTypeTwoFactor {
+		return nil, response.Unauthorized("Login expired, sign in again")
+	}
+
+	u, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, response.Unauthorized("Invalid credentials")
+	}
+	if err := s.checkIPBlocked(ctx, u.Email, client); err != nil {
+		return nil, err
+	}
+	if !u.IsActive() {
+		return nil, response.Forbidden("Account is disabled")
+	}
+	if err := s.checkLocked(ctx, u, u.Email, client); err != nil {
+		return nil, err
+	}
+
+	tf, err := s.repo.GetTwoFactor(ctx, u.ID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if !tf.Enabled() {
+		return nil, response.Unauthorized("Login expired, sign in again")
+	}
+	if err := s.checkSecondFactor(ctx, tf, code); err != nil {
+		if ae, ok := response.IsAppError(err); ok && ae.Code == response.CodeUnauthorized {
+			return nil, s.loginFailed(ctx, u, u.Email, client, LoginBadTwoFactor, err)
+		}
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := s.startSession(ctx, u, client, true)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	s.loginSucceeded(ctx, u, client)
+	return &LoginResult{User: u, AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// TwoFactorStatus returns the user's 2FA state
+func (s *Service) TwoFactorStatus(ctx context.Context, userID uint, role string, sessionID uint) (*TwoFactorStatus, error) {
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	required, err := s.twoFactorRequired(ctx, userID, role)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+
+	st := &TwoFactorStatus{Enabled: tf.Enabled(), Required: required}
+	if st.Enabled {
+		st.EnabledAt = tf.EnabledAt
+		if st.RecoveryCodesLeft, err = s.repo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, response.Internal(err)
+		}
+	}
+	if sessionID != 0 {
+		if _, st.SessionTwoFactored, err = s.repo.SessionState(ctx, sessionID); err != nil {
+			return nil, response.Internal(err)
+		}
+	}
+	return st, nil
+}
+
+// BeginTwoFactorSetup generates a new secret for the user to scan. 2FA is not enabled until
+// EnableTwoFactor confirms a code generated from it.
+func (s *Service) BeginTwoFactorSetup(ctx context.Context, userID uint, password string) (secret, uri string, err error) {
+	u, err := s.verifyPassword(ctx, userID, password)
+	if err != nil {
+		return "", "", err
+	}
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return "", "", response.Internal(err)
+	}
+	if tf.Enabled() {
+		return "", "", response.Conflict("Two-factor authentication is already enabled")
+	}
+
+	secret, err = security.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", response.Internal(err)
+	}
+	if tf == nil {
+		tf = &TwoFactor{UserID: userID}
+	}
+	tf.Secret = secret
+	tf.LastUsedStep = 0
+	if err := s.repo.SaveTwoFactor(ctx, tf); err != nil {
+		return "", "", response.Internal(err)
+	}
+	return secret, security.TOTPURI(s.cfg.Auth.TOTPIssuer, u.Email, secret), nil
+}
+
+// EnableTwoFactor confirms a pending setup with a code from the authenticator app and returns
+// the recovery codes (shown once). The current session counts as having passed 2FA.
+func (s *Service) EnableTwoFactor(ctx context.Context, userID, sessionID uint, code string) ([]string, error) {
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if tf == nil {
+		return nil, response.Validation("Start the two-factor setup first", nil)
+	}
+	if tf.Enabled() {
+		return nil, response.Conflict("Two-factor authentication is already enabled")
+	}
+
+	step, ok := security.ValidateTOTP(tf.Secret, code, time.Now())
+	if !ok {
+		return nil, response.Validation("Invalid code", nil)
+	}
+	now := time.Now()
+	tf.EnabledAt = &now
+	tf.LastUsedStep = step
+	if err := s.repo.SaveTwoFactor(ctx, tf); err != nil {
+		return nil, response.Internal(err)
+	}
+
+	codes, err := s.issueRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if sessionID != 0 {
+		if err := s.repo.MarkSessionTwoFactor(ctx, sessionID); err != nil {
+			return nil, response.Internal(err)
+		}
+	}
+	s.logger.Info("two-factor authentication enabled", zap.Uint("userID", userID))
+	return codes, nil
+}
+
+// DisableTwoFactor turns 2FA off after checking the password and a current code.
+// Not allowed while the security policy requires 2FA for the user's role.
+func (s *Service) DisableTwoFactor(ctx context.Context, userID uint, role, password, code string) error {
+	if _, err := s.verifyPassword(ctx, userID, password); err != nil {
+		return err
+	}
+	required, err := s.twoFactorRequired(ctx, userID, role)
+	if err != nil {
+		return response.Internal(err)
+	}
+	if required {
+		return response.Validation("Two-factor authentication is required for your role", nil)
+	}
+
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return response.Internal(err)
+	}
+	if !tf.Enabled() {
+		return response.Validation("Two-factor authentication is not enabled", nil)
+	}
+	if err := s.checkSecondFactor(ctx, tf, code); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTwoFactor(ctx, userID); err != nil {
+		return response.Internal(err)
+	}
+	s.logger.Info("two-factor authentication disabled", zap.Uint("userID", userID))
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a current code
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if !tf.Enabled() {
+		return nil, response.Validation("Two-factor authentication is not enabled", nil)
+	}
+	if err := s.checkSecondFactor(ctx, tf, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.issueRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	return codes, nil
+}
+
+// ResetTwoFactor removes a user's 2FA (lost device) and signs out all of their sessions.
+// Returns false if the user had no enrolment.
+func (s *Service) ResetTwoFactor(ctx context.Context, userID uint) (bool, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return false, response.NotFound("User not found")
+	}
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return false, response.Internal(err)
+	}
+	if tf == nil {
+		return false, nil
+	}
+
+	if err := s.repo.DeleteTwoFactor(ctx, userID); err != nil {
+		return false, response.Internal(err)
+	}
+	if _, err := s.repo.RevokeUserSessions(ctx, userID, 0, RevokeTwoFactorReset); err != nil {
+		return false, response.Internal(err)
+	}
+	return true, nil
+}
+
+// GetSecurityPolicy returns the login policy; defaults (nothing required) if never configured
+func (s *Service) GetSecurityPolicy(ctx context.Context) (*SecurityPolicy, error) {
+	p, err := s.repo.GetSecurityPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		p = &SecurityPolicy{ID: 1}
+	}
+	return p, nil
+}
+
+// UpdateSecurityPolicy stores the login policy
+func (s *Service) UpdateSecurityPolicy(ctx context.Context, requireAdminTwoFactor bool, adminID uint) (*SecurityPolicy, error) {
+	p, err := s.GetSecurityPolicy(ctx)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	p.RequireAdminTwoFactor = requireAdminTwoFactor
+	p.UpdatedBy = &adminID
+	p.UpdatedAt = time.Now()
+	if err := s.repo.SaveSecurityPolicy(ctx, p); err != nil {
+		return nil, response.Internal(err)
+	}
+	return p, nil
+}
+
+// PermissionStore loads a user's permissions (implemented by rbac.Service)
+type PermissionStore interface {
+	Grants(ctx context.Context, userID uint, role string) (*authx.Grants, error)
+}
+
+// SetPermissionStore lets the admin 2FA policy cover everyone with admin area permissions,
+// not only the admin account role
+func (s *Service) SetPermissionStore(p PermissionStore) {
+	s.perms = p
+}
+
+// AdminTwoFactorRequired implements middleware.TwoFactorPolicy
+func (s *Service) AdminTwoFactorRequired(ctx context.Context) (bool, error) {
+	p, err := s.GetSecurityPolicy(ctx)
+	if err != nil {
+		return false, err
+	}
+	return p.RequireAdminTwoFactor, nil
+}
+
+// twoFactorRequired reports whether the policy requires 2FA for the user, i.e. admin 2FA is
+// required and the user can reach the admin area
+func (s *Service) twoFactorRequired(ctx context.Context, userID uint, role string) (bool, error) {
+	if role != "admin" {
+		if s.perms == nil {
+			return false, nil
+		}
+		g, err := s.perms.Grants(ctx, userID, role)
+		if err != nil {
+			return false, err
+		}
+		if g.Empty() {
+			return false, nil
+		}
+	}
+	p, err := s.GetSecurityPolicy(ctx)
+	if err != nil {
+		return false, err
+	}
+	return p.RequireAdminTwoFactor, nil
+}
+
+// checkSecondFactor accepts a TOTP code (each time step once) or an unused recovery code
+func (s *Service) checkSecondFactor(ctx context.Context, tf *TwoFactor, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := security.ValidateTOTP(tf.Secret, code, time.Now()); ok {
+		fresh, err := s.repo.UseTwoFactorStep(ctx, tf.ID, step)
+		if err != nil {
+			return response.Internal(err)
+		}
+		if !fresh {
+			return response.Unauthorized("Code already used, wait for the next one")
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, tf.UserID, hashTokenID(normalizeRecoveryCode(code)))
+	if err != nil {
+		return response.Internal(err)
+	}
+	if !used {
+		return response.Unauthorized("Invalid code")
+	}
+	s.logger.Info("recovery code used", zap.Uint("userID", tf.UserID))
+	return nil
+}
+
+func (s *Service) verifyPassword(ctx context.Context, userID uint, password string) (*user.User, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if err := security.ComparePassword(u.PasswordHash, password); err != nil {
+		return nil, response.Validation("Password is incorrect", nil)
+	}
+	return u, nil
+}
+
+// issueRecoveryCodes replaces the user's recovery codes and returns them in XXXXX-XXXXX form
+func (s *Service) issueRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		for j := range b {
+			b[j] = recoveryAlphabet[int(b[j])%len(recoveryAlphabet)]
+		}
+		codes[i] = string(b[:5]) + "-" + string(b[5:])
+		hashes[i] = hashTokenID(normalizeRecoveryCode(codes[i]))
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode makes recovery codes case- and dash-insensitive
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -3,6 +3,7 @@ package department
 import (
 	"strconv"
 
+	"time-attendance-be/internal/modules/audit"
 	"time-attendance-be/internal/pkg/response"
 	"time-attendance-be/internal/pkg/validator"
 
@@ -10,9 +11,14 @@ import (
 	"gorm.io/gorm"
 )
 
-type Handler struct{ svc *Service }
+type Handler struct {
+	svc      *Service
+	auditSvc *audit.Service
+}
 
-func NewHandler(svc *Service) *Handler { return &Handler{svc: svc} }
+func NewHandler(svc *Service, auditSvc *audit.Service) *Handler {
+	return &Handler{svc: svc, auditSvc: auditSvc}
+}
 
 func (h *Handler) List(c *fiber.Ctx) error {
 	rows, err := h.svc.List(c.Context())
@@ -0,0 +1,142 @@
+package department
+
+import (
+	"strconv"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AddManagerReq struct {
+	UserID uint `json:"userId"`
+}
+
+type ManagerRes struct {
+	DepartmentID uint   `json:"departmentId"`
+	UserID       uint   `json:"userId"`
+	Name         string `json:"name,omitempty"`
+	Email        string `json:"email,omitempty"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+// GET /api/v1/admin/departments/:id/managers
+func (h *Handler) ListManagers(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+	rows, err := h.svc.ListManagers(c.Context(), uint(id))
+	if err != nil {
+		return err
+	}
+	items := make([]ManagerRes, len(rows))
+	for i, r := range rows {
+		items[i] = ManagerRes{
+			DepartmentID: r.DepartmentID,
+			UserID:       r.UserID,
+			Name:         r.Name,
+			Email:        r.Email,
+			CreatedAt:    r.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return response.OK(c, items)
+}
+
+// POST /api/v1/admin/departments/:id/managers
+// Body: { "userId": 12 }
+func (h *Handler) AddManager(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+	var req AddManagerReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	m, err := h.svc.AddManager(c.Context(), uint(id), req.UserID, adminUser.ID)
+	if err != nil {
+		return err
+	}
+
+	res := toManagerRes(m)
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"ADD_MANAGER",
+			"department",
+			c.Params("id"),
+			nil,
+			res,
+			c.Query("reason"),
+		)
+	}
+
+	return response.Created(c, res)
+}
+
+// DELETE /api/v1/admin/departments/:id/managers/:userId
+func (h *Handler) RemoveManager(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+	userID, err := strconv.ParseUint(c.Params("userId"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid userId", nil)
+	}
+
+	before, err := h.svc.RemoveManager(c.Context(), uint(id), uint(userID))
+	if err != nil {
+		return err
+	}
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"REMOVE_MANAGER",
+			"department",
+			c.Params("id"),
+			toManagerRes(before),
+			nil,
+			c.Query("reason"),
+		)
+	}
+
+	return response.OK(c, true)
+}
+
+// GET /api/v1/manager/departments
+// The departments the current user manages
+func (h *Handler) ManagedDepartments(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	rows, err := h.svc.ListByIDs(c.Context(), a.ManagedDepartments)
+	if err != nil {
+		return err
+	}
+	items := make([]DepartmentRes, len(rows))
+	for i := range rows {
+		items[i] = ToRes(&rows[i])
+	}
+	return response.OK(c, items)
+}
+
+func toManagerRes(m *Manager) ManagerRes {
+	return ManagerRes{DepartmentID: m.DepartmentID, UserID: m.UserID, CreatedAt: m.CreatedAt.Format(time.RFC3339)}
+}
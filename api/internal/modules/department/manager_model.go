@@ -0,0 +1,23 @@
+package department
+
+import "time"
+
+// Manager makes a user a manager of a department (table department_managers). Managers can
+// view their department's attendance, leave, notes and today-ops under /manager.
+type Manager struct {
+	DepartmentID uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"primaryKey;index"`
+	AssignedBy   uint      `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+func (Manager) TableName() string { return "department_managers" }
+
+// ManagerRow is a manager with their user details
+type ManagerRow struct {
+	DepartmentID uint
+	UserID       uint
+	Name         string
+	Email        string
+	CreatedAt    time.Time
+}
@@ -0,0 +1,71 @@
+package department
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// ListManagers returns the managers of a department, by name
+func (r *Repo) ListManagers(ctx context.Context, departmentID uint) ([]ManagerRow, error) {
+	var rows []ManagerRow
+	err := r.db.WithContext(ctx).
+		Table("department_managers AS m").
+		Select("m.department_id, m.user_id, u.name, u.email, m.created_at").
+		Joins("INNER JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL").
+		Where("m.department_id = ?", departmentID).
+		Order("u.name ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetManager returns the manager entry, or nil if the user doesn't manage the department
+func (r *Repo) GetManager(ctx context.Context, departmentID, userID uint) (*Manager, error) {
+	var rows []Manager
+	if err := r.db.WithContext(ctx).Where("department_id = ? AND user_id = ?", departmentID, userID).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// AddManager stores a manager entry
+func (r *Repo) AddManager(ctx context.Context, m *Manager) error {
+	return r.db.WithContext(ctx).Create(m).Error
+}
+
+// RemoveManager deletes a manager entry
+func (r *Repo) RemoveManager(ctx context.Context, departmentID, userID uint) error {
+	return r.db.WithContext(ctx).Where("department_id = ? AND user_id = ?", departmentID, userID).Delete(&Manager{}).Error
+}
+
+// ManagedDepartmentIDs returns the departments the user manages
+func (r *Repo) ManagedDepartmentIDs(ctx context.Context, userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&Manager{}).Where("user_id = ?", userID).Order("department_id ASC").Pluck("department_id", &ids).Error
+	return ids, err
+}
+
+// ListByIDs returns the departments with the given IDs, by name
+func (r *Repo) ListByIDs(ctx context.Context, ids []uint) ([]Department, error) {
+	var rows []Department
+	if len(ids) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("name ASC").Find(&rows).Error
+	return rows, err
+}
+
+// activeUserExists reports whether an active, not deleted user has the ID
+func (r *Repo) activeUserExists(ctx context.Context, userID uint) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Table("users").Where("id = ? AND status = ? AND deleted_at IS NULL", userID, "active").Count(&n).Error
+	return n > 0, err
+}
+
+// deleteManagers removes every manager entry of a department
+func deleteManagers(tx *gorm.DB, departmentID uint) error {
+	return tx.Where("department_id = ?", departmentID).Delete(&Manager{}).Error
+}
@@ -0,0 +1,91 @@
+package department
+
+import (
+	"context"
+	"time"
+
+	"time-attendance-be/internal/pkg/response"
+
+	"gorm.io/gorm"
+)
+
+// ListManagers returns the managers of a department
+func (s *Service) ListManagers(ctx context.Context, departmentID uint) ([]ManagerRow, error) {
+	if _, err := s.getDepartment(ctx, departmentID); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListManagers(ctx, departmentID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	return rows, nil
+}
+
+// AddManager makes an active user a manager of the department. The user doesn't have to be
+// a member of it, so one person can lead several teams.
+func (s *Service) AddManager(ctx context.Context, departmentID, userID, actorID uint) (*Manager, error) {
+	if _, err := s.getDepartment(ctx, departmentID); err != nil {
+		return nil, err
+	}
+	ok, err := s.repo.activeUserExists(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if !ok {
+		return nil, response.Validation("User not found or disabled", nil)
+	}
+	existing, err := s.repo.GetManager(ctx, departmentID, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if existing != nil {
+		return nil, response.Conflict("The user already manages this department")
+	}
+
+	m := &Manager{DepartmentID: departmentID, UserID: userID, AssignedBy: actorID, CreatedAt: time.Now()}
+	if err := s.repo.AddManager(ctx, m); err != nil {
+		return nil, response.Internal(err)
+	}
+	return m, nil
+}
+
+// RemoveManager removes a manager from the department
+func (s *Service) RemoveManager(ctx context.Context, departmentID, userID uint) (*Manager, error) {
+	m, err := s.repo.GetManager(ctx, departmentID, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if m == nil {
+		return nil, response.NotFound("The user does not manage this department")
+	}
+	if err := s.repo.RemoveManager(ctx, departmentID, userID); err != nil {
+		return nil, response.Internal(err)
+	}
+	return m, nil
+}
+
+// ManagedDepartments returns the departments the user manages (implements
+// middleware.ManagerStore)
+func (s *Service) ManagedDepartments(ctx context.Context, userID uint) ([]uint, error) {
+	return s.repo.ManagedDepartmentIDs(ctx, userID)
+}
+
+// ListByIDs returns the given departments
+func (s *Service) ListByIDs(ctx context.Context, ids []uint) ([]Department, error) {
+	rows, err := s.repo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	return rows, nil
+}
+
+func (s *Service) getDepartment(ctx context.Context, id uint) (*Department, error) {
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, response.NotFound("Department not found")
+		}
+		return nil, response.Internal(err)
+	}
+	return d, nil
+}
@@ -35,7 +35,12 @@ func (r *Repo) Update(ctx context.Context, d *Department) error {
 }
 
 func (r *Repo) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&Department{}, id).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteManagers(tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(&Department{}, id).Error
+	})
 }
 
 
@@ -1,17 +1,32 @@
 package department
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 type Module struct{ h *Handler }
 
-func NewModule(svc *Service) *Module { return &Module{h: NewHandler(svc)} }
+func NewModule(svc *Service, auditSvc *audit.Service) *Module {
+	return &Module{h: NewHandler(svc, auditSvc)}
+}
 
 func (m *Module) RegisterAdmin(admin fiber.Router) {
 	g := admin.Group("/departments")
+	// Department names back the filters of every admin screen, so any admin area user may list them
 	g.Get("/", m.h.List)
-	g.Post("/", m.h.Create)
-	g.Patch("/:id", m.h.Update)
-	g.Delete("/:id", m.h.Delete)
+	g.Post("/", authx.Require(authx.PermDepartmentsManage), m.h.Create)
+	g.Patch("/:id", authx.Require(authx.PermDepartmentsManage), m.h.Update)
+	g.Delete("/:id", authx.Require(authx.PermDepartmentsManage), m.h.Delete)
+	g.Get("/:id/managers", authx.Require(authx.PermDepartmentsManage), m.h.ListManagers)
+	g.Post("/:id/managers", authx.Require(authx.PermDepartmentsManage), m.h.AddManager)
+	g.Delete("/:id/managers/:userId", authx.Require(authx.PermDepartmentsManage), m.h.RemoveManager)
+}
+
+func (m *Module) RegisterManager(mgr fiber.Router) {
+	mgr.Get("/departments", m.h.ManagedDepartments)
 }
 
 
@@ -0,0 +1,58 @@
+package leave
+
+import (
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /api/v1/admin/leave/birthday-policy
+func (h *Handler) AdminGetBirthdayPolicy(c *fiber.Ctx) error {
+	p, err := h.svc.GetBirthdayPolicy(c.Context())
+	if err != nil {
+		return response.Internal(err)
+	}
+	return response.OK(c, toBirthdayPolicyResponse(p))
+}
+
+// PUT /api/v1/admin/leave/birthday-policy
+// Body: { "days": 1, "window": "DAYS", "windowDays": 7, "carryOver": false, "includeProbation": false, "probationMonths": 2 }
+func (h *Handler) AdminUpdateBirthdayPolicy(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	var req BirthdayPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	before, err := h.svc.GetBirthdayPolicy(c.Context())
+	if err != nil {
+		return response.Internal(err)
+	}
+	beforeRes := toBirthdayPolicyResponse(before)
+
+	p, err := h.svc.UpdateBirthdayPolicy(c.Context(), req, adminUser.ID)
+	if err != nil {
+		return err
+	}
+	res := toBirthdayPolicyResponse(p)
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"UPDATE",
+			"leave_birthday_policy",
+			"1",
+			beforeRes,
+			res,
+			"",
+		)
+	}
+
+	return response.OK(c, res)
+}
@@ -0,0 +1,37 @@
+package leave
+
+import "time"
+
+// BirthdayPolicy configures the free birthday leave (single row, ID = 1).
+// Birthday leave never touches users.paid_leave; it covers missing units on eligible days
+// before comp-off and paid leave.
+type BirthdayPolicy struct {
+	ID               uint    `gorm:"primaryKey"`
+	Days             float64 `gorm:"type:decimal(3,1);not null;default:1.0"`
+	Window           string  `gorm:"type:enum('MONTH','DAYS','QUARTER');not null;default:'MONTH'"`
+	WindowDays       int     `gorm:"not null;default:0"`                 // ±N days around the birthday (Window = DAYS)
+	CarryOver        bool    `gorm:"type:tinyint(1);not null;default:0"` // unused days stay usable until the end of the year
+	IncludeProbation bool    `gorm:"type:tinyint(1);not null;default:1"` // false = no birthday leave during probation
+	ProbationMonths  int     `gorm:"not null;default:2"`                 // probation length counted from users.hire_date
+	UpdatedBy        *uint
+	UpdatedAt        time.Time `gorm:"not null"`
+}
+
+func (BirthdayPolicy) TableName() string {
+	return "leave_birthday_policy"
+}
+
+const (
+	BirthdayWindowMonth   = "MONTH"   // the calendar month of the birthday
+	BirthdayWindowDays    = "DAYS"    // birthday ± WindowDays
+	BirthdayWindowQuarter = "QUARTER" // the quarter containing the birthday
+)
+
+// defaultBirthdayPolicy matches the original behaviour: 1 day, birthday month only, everyone eligible
+var defaultBirthdayPolicy = BirthdayPolicy{
+	ID:               1,
+	Days:             1.0,
+	Window:           BirthdayWindowMonth,
+	IncludeProbation: true,
+	ProbationMonths:  2,
+}
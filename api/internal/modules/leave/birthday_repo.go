@@ -0,0 +1,49 @@
+package leave
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Birthday policy methods
+
+// GetBirthdayPolicy returns the stored policy, or nil if it was never configured
+func (r *Repo) GetBirthdayPolicy(ctx context.Context) (*BirthdayPolicy, error) {
+	var p BirthdayPolicy
+	err := r.db.WithContext(ctx).First(&p, "id = ?", 1).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SaveBirthdayPolicy stores the policy (always row ID 1)
+func (r *Repo) SaveBirthdayPolicy(ctx context.Context, p *BirthdayPolicy) error {
+	p.ID = 1
+	return r.db.WithContext(ctx).Save(p).Error
+}
+
+// SumBirthdayUsed returns birthday units used by a user in months [fromYM, toYM) (YM = year*100+month)
+func (r *Repo) SumBirthdayUsed(ctx context.Context, userID uint, fromYM, toYM int) (float64, error) {
+	var total float64
+	err := r.db.WithContext(ctx).
+		Model(&MonthlySummary{}).
+		Select("COALESCE(SUM(birthday_used_units), 0)").
+		Where("user_id = ? AND year * 100 + month >= ? AND year * 100 + month < ?", userID, fromYM, toYM).
+		Scan(&total).Error
+	return total, err
+}
+
+// ListBirthdayUsed returns summaries with birthday units used in months [fromYM, toYM), all users
+func (r *Repo) ListBirthdayUsed(ctx context.Context, fromYM, toYM int) ([]MonthlySummary, error) {
+	var rows []MonthlySummary
+	err := r.db.WithContext(ctx).
+		Select("user_id, year, month, birthday_used_units").
+		Where("birthday_used_units > 0 AND year * 100 + month >= ? AND year * 100 + month < ?", fromYM, toYM).
+		Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,127 @@
+package leave
+
+import (
+	"context"
+	"time"
+
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/pkg/response"
+)
+
+// birthdayWindow is the span in which one birthday's leave can be used
+type birthdayWindow struct {
+	Start     time.Time
+	End       time.Time
+	Available float64 // days left at the start of the month being computed
+}
+
+// Contains reports whether a date falls inside the window
+func (w *birthdayWindow) Contains(d time.Time) bool {
+	day := d.Format("2006-01-02")
+	return day >= w.Start.Format("2006-01-02") && day <= w.End.Format("2006-01-02")
+}
+
+// GetBirthdayPolicy returns the configured birthday policy (defaults if never configured)
+func (s *Service) GetBirthdayPolicy(ctx context.Context) (*BirthdayPolicy, error) {
+	p, err := s.summaryRepo.GetBirthdayPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		def := defaultBirthdayPolicy
+		return &def, nil
+	}
+	return p, nil
+}
+
+// UpdateBirthdayPolicy replaces the birthday policy settings
+func (s *Service) UpdateBirthdayPolicy(ctx context.Context, req BirthdayPolicyRequest, adminID uint) (*BirthdayPolicy, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	p, err := s.GetBirthdayPolicy(ctx)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	req.applyTo(p)
+	p.UpdatedBy = &adminID
+	p.UpdatedAt = time.Now()
+	if err := s.repo.SaveBirthdayPolicy(ctx, p); err != nil {
+		return nil, response.Internal(err)
+	}
+	return p, nil
+}
+
+// windowFor returns the eligible window of the birthday falling in `year`
+func (p *BirthdayPolicy) windowFor(birthday time.Time, year int, loc *time.Location) (time.Time, time.Time) {
+	bd := time.Date(year, birthday.Month(), birthday.Day(), 0, 0, 0, 0, loc)
+
+	var start, end time.Time
+	switch p.Window {
+	case BirthdayWindowDays:
+		start = bd.AddDate(0, 0, -p.WindowDays)
+		end = bd.AddDate(0, 0, p.WindowDays)
+	case BirthdayWindowQuarter:
+		q := (int(bd.Month()) - 1) / 3
+		start = time.Date(year, time.Month(q*3+1), 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 3, -1)
+	default:
+		start = time.Date(year, bd.Month(), 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, -1)
+	}
+
+	if p.CarryOver {
+		if yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, loc); end.Before(yearEnd) {
+			end = yearEnd
+		}
+	}
+	return start, end
+}
+
+// birthdayWindowFor finds the birthday window overlapping year/month for a user, with the days
+// still available (policy days minus what earlier months of the same window used).
+// Returns nil if the user has no birthday, no window overlaps the month, or they are on probation.
+func (s *Service) birthdayWindowFor(ctx context.Context, p *BirthdayPolicy, u *user.User, year, month int) (*birthdayWindow, error) {
+	return s.resolveBirthdayWindow(p, u, year, month, func(fromYM int) (float64, error) {
+		return s.summaryRepo.SumBirthdayUsed(ctx, u.ID, fromYM, year*100+month)
+	})
+}
+
+// resolveBirthdayWindow is birthdayWindowFor with the usage lookup supplied by the caller:
+// usedSince(fromYM) returns the birthday units used from month fromYM up to (excluding) year/month.
+func (s *Service) resolveBirthdayWindow(p *BirthdayPolicy, u *user.User, year, month int, usedSince func(fromYM int) (float64, error)) (*birthdayWindow, error) {
+	if u.Birthday == nil || p.Days <= 0 {
+		return nil, nil
+	}
+
+	loc := s.cfg.TimeLocation()
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	// A ±N days window can spill over the year boundary, so check the neighbouring years too
+	for y := year - 1; y <= year+1; y++ {
+		start, end := p.windowFor(*u.Birthday, y, loc)
+		if start.After(monthEnd) || end.Before(monthStart) {
+			continue
+		}
+
+		if !p.IncludeProbation && u.HireDate != nil {
+			bd := time.Date(y, u.Birthday.Month(), u.Birthday.Day(), 0, 0, 0, 0, loc)
+			if bd.Before(u.HireDate.AddDate(0, p.ProbationMonths, 0)) {
+				return nil, nil
+			}
+		}
+
+		used, err := usedSince(start.Year()*100 + int(start.Month()))
+		if err != nil {
+			return nil, err
+		}
+		available := p.Days - used
+		if available < 0 {
+			available = 0
+		}
+		return &birthdayWindow{Start: start, End: end, Available: available}, nil
+	}
+
+	return nil, nil
+}
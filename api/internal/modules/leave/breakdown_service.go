@@ -0,0 +1,271 @@
+package leave
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"time-attendance-be/internal/modules/attendance"
+	"time-attendance-be/internal/modules/user"
+)
+
+// Day coverage labels used in the per-day breakdown
+const (
+	CoverageWorked   = "WORKED"   // worked the full expected units
+	CoverageBirthday = "BIRTHDAY" // missing units covered by birthday leave
+	CoverageCompOff  = "COMP_OFF" // missing units covered by comp-off credits
+	CoveragePaid     = "PAID"     // missing units covered by paid leave
+	CoverageUnpaid   = "UNPAID"   // missing units not covered (unpaid)
+	CoverageMixed    = "MIXED"    // missing units covered by more than one balance
+	CoverageOffset   = "OFFSET"   // missing units offset by extra units worked on other days
+	CoverageOff      = "OFF"      // non-working day
+	CoverageFuture   = "FUTURE"   // after today (current month only), not counted yet
+)
+
+// DayAllocation explains how one calendar day contributes to the monthly summary
+type DayAllocation struct {
+	Date          time.Time
+	IsWorkingDay  bool
+	ExpectedUnits float64
+	WorkedUnits   float64
+	SessionStatus string // "", OPEN or CLOSED
+	MissingUnits  float64
+	BirthdayUnits float64
+	CompOffUnits  float64
+	PaidUnits     float64
+	UnpaidUnits   float64
+	Coverage      string
+}
+
+// MonthlyBreakdown is a monthly summary together with its day-by-day allocation
+type MonthlyBreakdown struct {
+	Summary *MonthlySummary
+	Days    []DayAllocation
+}
+
+// ProjectMonthlyBreakdown projects the summary for a user/month and allocates its totals to days.
+// Missing units of each working day (expected - worked by CLOSED sessions) are covered in date order
+// by birthday leave (inside the birthday window only), then comp-off, then paid leave; what is left is unpaid. Because the summary nets
+// the month as a whole, days worked beyond their expected units can offset missing units elsewhere;
+// such days are labelled OFFSET. Nothing is persisted: summaries are stored by the jobs and recompute tasks.
+func (s *Service) ProjectMonthlyBreakdown(ctx context.Context, userID uint, year, month int) (*MonthlyBreakdown, error) {
+	summary, err := s.projectMonthlySummary(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	return s.breakdownFromSummary(ctx, summary)
+}
+
+// ProjectMonthlyBreakdowns is ProjectMonthlyBreakdown for many users at once (team views):
+// summaries come from projectMonthlySummaries and the calendar and sessions are loaded once.
+func (s *Service) ProjectMonthlyBreakdowns(ctx context.Context, users []user.User, year, month int) (map[uint]*MonthlyBreakdown, error) {
+	summaries, err := s.projectMonthlySummaries(ctx, users, year, month)
+	if err != nil {
+		return nil, err
+	}
+	startDate, endDate := s.monthRange(year, month)
+	calDays, err := s.workCalRepo.ListRange(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("list calendar: %w", err)
+	}
+	sessions, err := s.attendanceRepo.ListByRange(ctx, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	sessionsByUser := make(map[uint][]attendance.Session)
+	for i := range sessions {
+		sessionsByUser[sessions[i].UserID] = append(sessionsByUser[sessions[i].UserID], sessions[i])
+	}
+	usersByID := make(map[uint]*user.User, len(users))
+	for i := range users {
+		usersByID[users[i].ID] = &users[i]
+	}
+
+	res := make(map[uint]*MonthlyBreakdown, len(summaries))
+	for i := range summaries {
+		summary := &summaries[i]
+		bw, err := s.breakdownWindow(ctx, usersByID[summary.UserID], summary)
+		if err != nil {
+			return nil, err
+		}
+		res[summary.UserID] = s.allocateDays(summary, calDays, sessionsByUser[summary.UserID], bw)
+	}
+	return res, nil
+}
+
+// monthRange returns the first and last day of a month
+func (s *Service) monthRange(year, month int) (time.Time, time.Time) {
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.cfg.TimeLocation())
+	return startDate, startDate.AddDate(0, 1, -1)
+}
+
+func (s *Service) breakdownFromSummary(ctx context.Context, summary *MonthlySummary) (*MonthlyBreakdown, error) {
+	startDate, endDate := s.monthRange(summary.Year, summary.Month)
+	calDays, err := s.workCalRepo.ListRange(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("list calendar: %w", err)
+	}
+	sessions, err := s.attendanceRepo.ListByUserDateRange(summary.UserID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	u, err := s.userRepo.GetByID(ctx, summary.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	bw, err := s.breakdownWindow(ctx, u, summary)
+	if err != nil {
+		return nil, err
+	}
+	return s.allocateDays(summary, calDays, sessions, bw), nil
+}
+
+// breakdownWindow returns the birthday window birthday leave can land in (nil when none was used)
+func (s *Service) breakdownWindow(ctx context.Context, u *user.User, summary *MonthlySummary) (*birthdayWindow, error) {
+	if summary.BirthdayUsedUnits <= 0 || u == nil {
+		return nil, nil
+	}
+	policy, err := s.GetBirthdayPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("birthday policy: %w", err)
+	}
+	bw, err := s.birthdayWindowFor(ctx, policy, u, summary.Year, summary.Month)
+	if err != nil {
+		return nil, fmt.Errorf("birthday window: %w", err)
+	}
+	return bw, nil
+}
+
+// allocateDays spreads a summary's totals over the days of its month
+func (s *Service) allocateDays(summary *MonthlySummary, calDays []WorkCalendarDay, sessions []attendance.Session, bw *birthdayWindow) *MonthlyBreakdown {
+	year, month := summary.Year, summary.Month
+	sessionByDate := make(map[string]*attendance.Session, len(sessions))
+	for i := range sessions {
+		sessionByDate[sessions[i].WorkDate.Format("2006-01-02")] = &sessions[i]
+	}
+
+	now := time.Now().In(s.cfg.TimeLocation())
+	todayStr := now.Format("2006-01-02")
+	isCurrentMonth := year == now.Year() && month == int(now.Month())
+
+	// Birthday leave can only land on days inside the birthday window
+	birthdayPool := summary.BirthdayUsedUnits
+	compOffPool := summary.CompOffUsedUnits
+	paidPool := summary.PaidUsedUnits
+	unpaidPool := summary.UnpaidUnits
+
+	take := func(pool *float64, want float64) float64 {
+		if *pool <= 0 || want <= 0 {
+			return 0
+		}
+		got := want
+		if got > *pool {
+			got = *pool
+		}
+		*pool -= got
+		return got
+	}
+
+	days := make([]DayAllocation, 0, len(calDays))
+	for _, cd := range calDays {
+		dateStr := cd.WorkDate.Format("2006-01-02")
+		day := DayAllocation{
+			Date:         cd.WorkDate,
+			IsWorkingDay: cd.IsWorkingDay,
+		}
+		if sess, ok := sessionByDate[dateStr]; ok {
+			day.SessionStatus = sess.Status
+			if sess.Status == "CLOSED" {
+				day.WorkedUnits = float64(sess.DayUnit)
+			}
+		}
+
+		switch {
+		case isCurrentMonth && dateStr > todayStr:
+			day.Coverage = CoverageFuture
+		case !cd.IsWorkingDay || cd.WorkUnit <= 0:
+			day.Coverage = CoverageOff
+		default:
+			day.ExpectedUnits = cd.WorkUnit
+			// Only sessions on working days count toward worked units (see SumDayUnitByRange)
+			missing := cd.WorkUnit - day.WorkedUnits
+			if missing <= 0 {
+				day.Coverage = CoverageWorked
+				break
+			}
+			day.MissingUnits = missing
+			remaining := missing
+			if bw != nil && bw.Contains(cd.WorkDate) {
+				day.BirthdayUnits = take(&birthdayPool, remaining)
+				remaining -= day.BirthdayUnits
+			}
+			day.CompOffUnits = take(&compOffPool, remaining)
+			remaining -= day.CompOffUnits
+			day.PaidUnits = take(&paidPool, remaining)
+			remaining -= day.PaidUnits
+			day.UnpaidUnits = take(&unpaidPool, remaining)
+			remaining -= day.UnpaidUnits
+			day.Coverage = dayCoverage(&day, remaining)
+		}
+
+		days = append(days, day)
+	}
+
+	return &MonthlyBreakdown{Summary: summary, Days: days}
+}
+
+func dayCoverage(d *DayAllocation, offset float64) string {
+	used := 0
+	label := CoverageOffset
+	for _, c := range []struct {
+		units float64
+		label string
+	}{
+		{d.BirthdayUnits, CoverageBirthday},
+		{d.CompOffUnits, CoverageCompOff},
+		{d.PaidUnits, CoveragePaid},
+		{d.UnpaidUnits, CoverageUnpaid},
+		{offset, CoverageOffset},
+	} {
+		if c.units > 0 {
+			used++
+			label = c.label
+		}
+	}
+	if used > 1 {
+		return CoverageMixed
+	}
+	return label
+}
+
+// usageFromBreakdown converts day allocations into (non-persisted) LeaveUsage rows
+func usageFromBreakdown(userID uint, b *MonthlyBreakdown) []LeaveUsage {
+	var usages []LeaveUsage
+	for _, d := range b.Days {
+		for _, part := range []struct {
+			units     float64
+			leaveType string
+			unpaid    bool
+		}{
+			{d.BirthdayUnits, LeaveTypeBirthday, false},
+			{d.CompOffUnits, LeaveTypeCompOff, false},
+			{d.PaidUnits, LeaveTypeRegular, false},
+			{d.UnpaidUnits, LeaveTypeRegular, true},
+		} {
+			if part.units <= 0 {
+				continue
+			}
+			usages = append(usages, LeaveUsage{
+				UserID:       userID,
+				UsageDate:    d.Date,
+				DaysUsed:     part.units,
+				LeaveType:    part.leaveType,
+				IsUnpaid:     part.unpaid,
+				Source:       SourceAutoAbsence,
+				RecordStatus: "ACTIVE",
+				UpdatedAt:    b.Summary.UpdatedAt,
+			})
+		}
+	}
+	return usages
+}
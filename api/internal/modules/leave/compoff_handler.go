@@ -0,0 +1,152 @@
+package leave
+
+import (
+	"strconv"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /api/v1/me/leave/comp-off
+func (h *Handler) GetMyCompOff(c *fiber.Ctx) error {
+	user := authx.GetUser(c)
+	if user == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	approved := CompOffStatusApproved
+	credits, err := h.svc.ListCompOffCredits(c.Context(), CompOffFilter{UserID: &user.ID, Status: &approved})
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	today := time.Now().In(h.svc.cfg.TimeLocation()).Format("2006-01-02")
+	balance := 0.0
+	items := make([]CompOffCreditResponse, len(credits))
+	for i := range credits {
+		items[i] = toCompOffCreditResponse(&credits[i], today)
+		if !items[i].Expired {
+			balance += items[i].Remaining
+		}
+	}
+
+	return response.OK(c, map[string]interface{}{
+		"balance": balance,
+		"items":   items,
+	})
+}
+
+// GET /api/v1/admin/leave/comp-off?status=&userId=&from=&to=
+func (h *Handler) AdminListCompOff(c *fiber.Ctx) error {
+	filter := CompOffFilter{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	}
+	if status := c.Query("status"); status != "" {
+		filter.Status = &status
+	}
+	if userIDStr := c.Query("userId"); userIDStr != "" {
+		if userID64, err := strconv.ParseUint(userIDStr, 10, 64); err == nil {
+			uid := uint(userID64)
+			filter.UserID = &uid
+		}
+	}
+
+	credits, err := h.svc.ListCompOffCredits(c.Context(), filter)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	today := time.Now().In(h.svc.cfg.TimeLocation()).Format("2006-01-02")
+	items := make([]CompOffCreditResponse, len(credits))
+	for i := range credits {
+		items[i] = toCompOffCreditResponse(&credits[i], today)
+	}
+	return response.OK(c, items)
+}
+
+// POST /api/v1/admin/leave/comp-off/detect
+// Body: { "from": "YYYY-MM-DD", "to": "YYYY-MM-DD" } (optional, defaults to the last 31 days)
+func (h *Handler) AdminDetectCompOff(c *fiber.Ctx) error {
+	type DetectRequest struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	var req DetectRequest
+	if err := c.BodyParser(&req); err != nil {
+		req = DetectRequest{}
+	}
+
+	loc := h.svc.cfg.TimeLocation()
+	to := time.Now().In(loc)
+	from := to.AddDate(0, 0, -31)
+	if req.From != "" {
+		d, err := time.ParseInLocation("2006-01-02", req.From, loc)
+		if err != nil {
+			return response.Validation("Invalid from date format (YYYY-MM-DD)", nil)
+		}
+		from = d
+	}
+	if req.To != "" {
+		d, err := time.ParseInLocation("2006-01-02", req.To, loc)
+		if err != nil {
+			return response.Validation("Invalid to date format (YYYY-MM-DD)", nil)
+		}
+		to = d
+	}
+	if to.Before(from) {
+		return response.Validation("from must be before to", nil)
+	}
+
+	created, err := h.svc.DetectCompOffCredits(c.Context(), from, to)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	return response.OK(c, map[string]interface{}{
+		"from":    from.Format("2006-01-02"),
+		"to":      to.Format("2006-01-02"),
+		"created": created,
+	})
+}
+
+type reviewCompOffRequest struct {
+	Note *string `json:"note"`
+}
+
+// POST /api/v1/admin/leave/comp-off/:id/approve
+func (h *Handler) AdminApproveCompOff(c *fiber.Ctx) error {
+	return h.reviewCompOff(c, true)
+}
+
+// POST /api/v1/admin/leave/comp-off/:id/reject
+func (h *Handler) AdminRejectCompOff(c *fiber.Ctx) error {
+	return h.reviewCompOff(c, false)
+}
+
+func (h *Handler) reviewCompOff(c *fiber.Ctx, approve bool) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	var req reviewCompOffRequest
+	_ = c.BodyParser(&req)
+
+	credit, err := h.svc.ReviewCompOffCredit(c.Context(), uint(id), adminUser.ID, approve, req.Note)
+	if err != nil {
+		return err
+	}
+
+	today := time.Now().In(h.svc.cfg.TimeLocation()).Format("2006-01-02")
+	return response.OK(c, toCompOffCreditResponse(credit, today))
+}
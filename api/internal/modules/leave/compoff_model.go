@@ -0,0 +1,58 @@
+package leave
+
+import "time"
+
+// CompOffCredit is a compensatory time-off credit earned by working on a non-working day.
+// Credits are detected as PENDING from closed sessions on days where work_calendar.is_working_day = false,
+// confirmed by an admin, and then consumed (before regular paid leave) until they expire.
+type CompOffCredit struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"not null;index"`
+	SessionID  uint       `gorm:"not null;uniqueIndex"` // attendance_sessions.id - one credit per session
+	WorkDate   time.Time  `gorm:"type:date;not null;index"`
+	Units      float64    `gorm:"type:decimal(3,1);not null;default:0.0"`
+	UsedUnits  float64    `gorm:"type:decimal(3,1);not null;default:0.0"`
+	Status     string     `gorm:"type:enum('PENDING','APPROVED','REJECTED');not null;default:'PENDING';index"`
+	ExpiresAt  *time.Time `gorm:"type:date"` // set on approval
+	ReviewedBy *uint
+	ReviewedAt *time.Time
+	Note       *string   `gorm:"type:varchar(255)"`
+	CreatedAt  time.Time `gorm:"not null"`
+	UpdatedAt  time.Time `gorm:"not null"`
+}
+
+func (CompOffCredit) TableName() string {
+	return "leave_comp_off_credits"
+}
+
+// CompOffUsage records how many units of a credit a month's absences consumed (table
+// leave_comp_off_usages). Summaries of a month count the units it consumed as available, so
+// recomputing a closed month gives the same split after its credits were used up.
+type CompOffUsage struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreditID  uint      `gorm:"not null;uniqueIndex:idx_comp_off_usage_month,priority:1"`
+	UserID    uint      `gorm:"not null;index:idx_comp_off_usage_user_month,priority:1"`
+	Year      int       `gorm:"not null;uniqueIndex:idx_comp_off_usage_month,priority:2;index:idx_comp_off_usage_user_month,priority:2"`
+	Month     int       `gorm:"not null;uniqueIndex:idx_comp_off_usage_month,priority:3;index:idx_comp_off_usage_user_month,priority:3"`
+	Units     float64   `gorm:"type:decimal(3,1);not null;default:0.0"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (CompOffUsage) TableName() string {
+	return "leave_comp_off_usages"
+}
+
+const (
+	CompOffStatusPending  = "PENDING"
+	CompOffStatusApproved = "APPROVED"
+	CompOffStatusRejected = "REJECTED"
+)
+
+// Remaining returns the units still available on the credit
+func (c *CompOffCredit) Remaining() float64 {
+	r := c.Units - c.UsedUnits
+	if r < 0 {
+		return 0
+	}
+	return r
+}
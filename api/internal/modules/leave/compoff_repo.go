@@ -0,0 +1,161 @@
+package leave
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Comp-off credit methods
+
+// CreateCompOffCredits inserts PENDING credits; sessions that already have a credit are skipped
+func (r *Repo) CreateCompOffCredits(ctx context.Context, credits []CompOffCredit) (int64, error) {
+	if len(credits) == 0 {
+		return 0, nil
+	}
+	res := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "session_id"}},
+			DoNothing: true,
+		}).Create(&credits)
+	return res.RowsAffected, res.Error
+}
+
+// CompOffFilter filters comp-off credit listings
+type CompOffFilter struct {
+	UserID *uint
+	Status *string
+	From   string
+	To     string
+}
+
+// ListCompOffCredits returns credits matching the filter, newest first
+func (r *Repo) ListCompOffCredits(ctx context.Context, filter CompOffFilter) ([]CompOffCredit, error) {
+	query := r.db.WithContext(ctx).Model(&CompOffCredit{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.From != "" {
+		query = query.Where("work_date >= ?", filter.From)
+	}
+	if filter.To != "" {
+		query = query.Where("work_date <= ?", filter.To)
+	}
+	var credits []CompOffCredit
+	err := query.Order("work_date DESC").Find(&credits).Error
+	return credits, err
+}
+
+// GetCompOffCredit returns a credit by ID
+func (r *Repo) GetCompOffCredit(ctx context.Context, id uint) (*CompOffCredit, error) {
+	var c CompOffCredit
+	if err := r.db.WithContext(ctx).First(&c, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SaveCompOffCredit updates a credit
+func (r *Repo) SaveCompOffCredit(ctx context.Context, c *CompOffCredit) error {
+	return r.db.WithContext(ctx).Save(c).Error
+}
+
+// ListUsableCompOffCredits returns APPROVED credits with remaining units that were earned
+// on or before `until` and are not expired on `asOf`, ordered by expiry (FIFO)
+func (r *Repo) ListUsableCompOffCredits(ctx context.Context, userID uint, asOf, until time.Time) ([]CompOffCredit, error) {
+	var credits []CompOffCredit
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ? AND used_units < units", userID, CompOffStatusApproved).
+		Where("work_date <= ? AND expires_at >= ?", until.Format("2006-01-02"), asOf.Format("2006-01-02")).
+		Order("expires_at ASC, id ASC").
+		Find(&credits).Error
+	return credits, err
+}
+
+// ListCompOffAvailable returns the comp-off units each user can use for absences of a month:
+// APPROVED credits earned on or before `until` and not expired on `asOf`, with what is left on
+// them plus what this month already consumed (so a closed month keeps its own usage).
+// userID nil = all users.
+func (r *Repo) ListCompOffAvailable(ctx context.Context, userID *uint, year, month int, asOf, until time.Time) (map[uint]float64, error) {
+	q := r.db.WithContext(ctx).
+		Where("status = ?", CompOffStatusApproved).
+		Where("work_date <= ? AND expires_at >= ?", until.Format("2006-01-02"), asOf.Format("2006-01-02"))
+	if userID != nil {
+		q = q.Where("user_id = ?", *userID)
+	}
+	var credits []CompOffCredit
+	if err := q.Find(&credits).Error; err != nil {
+		return nil, err
+	}
+
+	uq := r.db.WithContext(ctx).Model(&CompOffUsage{}).Where("year = ? AND month = ?", year, month)
+	if userID != nil {
+		uq = uq.Where("user_id = ?", *userID)
+	}
+	var used []struct {
+		CreditID uint
+		Units    float64
+	}
+	if err := uq.Select("credit_id, SUM(units) AS units").Group("credit_id").Scan(&used).Error; err != nil {
+		return nil, err
+	}
+	usedByCredit := make(map[uint]float64, len(used))
+	for _, u := range used {
+		usedByCredit[u.CreditID] = u.Units
+	}
+
+	available := make(map[uint]float64)
+	for i := range credits {
+		c := &credits[i]
+		units := c.Remaining() + usedByCredit[c.ID]
+		if units > c.Units {
+			units = c.Units
+		}
+		if units > 0 {
+			available[c.UserID] += units
+		}
+	}
+	return available, nil
+}
+
+// HasCompOffUsage reports whether a user's comp-off for a month was already consumed
+func (r *Repo) HasCompOffUsage(ctx context.Context, userID uint, year, month int) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&CompOffUsage{}).
+		Where("user_id = ? AND year = ? AND month = ?", userID, year, month).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ConsumeCompOffCredits records the usage and increments used_units on the credits in one transaction
+func (r *Repo) ConsumeCompOffCredits(ctx context.Context, usage []CompOffUsage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return consumeCompOffCredits(tx, usage)
+	})
+}
+
+// consumeCompOffCredits writes comp-off usage inside a transaction
+func consumeCompOffCredits(tx *gorm.DB, usage []CompOffUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+	if err := tx.Create(&usage).Error; err != nil {
+		return err
+	}
+	for _, u := range usage {
+		if err := tx.Model(&CompOffCredit{}).
+			Where("id = ?", u.CreditID).
+			Updates(map[string]interface{}{
+				"used_units": gorm.Expr("LEAST(used_units + ?, units)", u.Units),
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
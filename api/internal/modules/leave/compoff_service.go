@@ -0,0 +1,155 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"time-attendance-be/internal/pkg/response"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DetectCompOffCredits scans closed sessions in [from, to] that fall on non-working days
+// and records a PENDING comp-off credit for each (units = session day_unit).
+// Sessions that already have a credit are skipped, so the scan is safe to re-run.
+func (s *Service) DetectCompOffCredits(ctx context.Context, from, to time.Time) (int64, error) {
+	if s.workCalRepo == nil || s.attendanceRepo == nil {
+		return 0, fmt.Errorf("work calendar or attendance repo not set")
+	}
+
+	for y := from.Year(); y <= to.Year(); y++ {
+		if err := s.workCalRepo.EnsureYear(ctx, y); err != nil {
+			return 0, fmt.Errorf("ensure calendar year: %w", err)
+		}
+	}
+
+	calDays, err := s.workCalRepo.ListRange(ctx, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("list calendar: %w", err)
+	}
+	nonWorking := make(map[string]bool)
+	for _, d := range calDays {
+		if !d.IsWorkingDay {
+			nonWorking[d.WorkDate.Format("2006-01-02")] = true
+		}
+	}
+	if len(nonWorking) == 0 {
+		return 0, nil
+	}
+
+	sessions, err := s.attendanceRepo.ListClosedByRange(ctx, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return 0, fmt.Errorf("list sessions: %w", err)
+	}
+
+	now := time.Now()
+	var credits []CompOffCredit
+	for _, sess := range sessions {
+		if sess.DayUnit <= 0 || !nonWorking[sess.WorkDate.Format("2006-01-02")] {
+			continue
+		}
+		credits = append(credits, CompOffCredit{
+			UserID:    sess.UserID,
+			SessionID: sess.ID,
+			WorkDate:  sess.WorkDate,
+			Units:     float64(sess.DayUnit),
+			Status:    CompOffStatusPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	created, err := s.repo.CreateCompOffCredits(ctx, credits)
+	if err != nil {
+		return 0, fmt.Errorf("create comp-off credits: %w", err)
+	}
+	s.logger.Info("detected comp-off credits",
+		zap.String("from", from.Format("2006-01-02")),
+		zap.String("to", to.Format("2006-01-02")),
+		zap.Int("candidates", len(credits)),
+		zap.Int64("created", created))
+	return created, nil
+}
+
+// ListCompOffCredits returns comp-off credits matching the filter
+func (s *Service) ListCompOffCredits(ctx context.Context, filter CompOffFilter) ([]CompOffCredit, error) {
+	return s.repo.ListCompOffCredits(ctx, filter)
+}
+
+// ReviewCompOffCredit approves or rejects a PENDING credit.
+// Approved credits expire cfg.Leave.CompOffExpiryDays after the day worked.
+func (s *Service) ReviewCompOffCredit(ctx context.Context, id, adminID uint, approve bool, note *string) (*CompOffCredit, error) {
+	credit, err := s.repo.GetCompOffCredit(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFound("Comp-off credit not found")
+		}
+		return nil, response.Internal(err)
+	}
+	if credit.Status != CompOffStatusPending {
+		return nil, response.Conflict("Comp-off credit has already been reviewed")
+	}
+
+	now := time.Now()
+	credit.ReviewedBy = &adminID
+	credit.ReviewedAt = &now
+	credit.Note = note
+	credit.UpdatedAt = now
+	if approve {
+		expires := credit.WorkDate.AddDate(0, 0, s.cfg.Leave.CompOffExpiryDays)
+		credit.Status = CompOffStatusApproved
+		credit.ExpiresAt = &expires
+	} else {
+		credit.Status = CompOffStatusRejected
+	}
+
+	if err := s.repo.SaveCompOffCredit(ctx, credit); err != nil {
+		return nil, response.Internal(err)
+	}
+	if approve {
+		s.enqueueBalanceRecompute(ctx, []uint{credit.UserID})
+	}
+	return credit, nil
+}
+
+// compOffAvailable returns the comp-off units usable for absences in [monthStart, until]
+func (s *Service) compOffAvailable(ctx context.Context, userID uint, monthStart, until time.Time) (float64, error) {
+	available, err := s.summaryRepo.ListCompOffAvailable(ctx, &userID, monthStart.Year(), int(monthStart.Month()), monthStart, until)
+	if err != nil {
+		return 0, err
+	}
+	return available[userID], nil
+}
+
+// planCompOffUsage splits `units` over a user's usable credits, earliest expiry first
+func (s *Service) planCompOffUsage(ctx context.Context, userID uint, units float64, monthStart, monthEnd time.Time) ([]CompOffUsage, error) {
+	credits, err := s.repo.ListUsableCompOffCredits(ctx, userID, monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var usage []CompOffUsage
+	remaining := units
+	for i := range credits {
+		if remaining <= 0 {
+			break
+		}
+		take := credits[i].Remaining()
+		if take > remaining {
+			take = remaining
+		}
+		usage = append(usage, CompOffUsage{
+			CreditID:  credits[i].ID,
+			UserID:    userID,
+			Year:      monthStart.Year(),
+			Month:     int(monthStart.Month()),
+			Units:     take,
+			CreatedAt: now,
+		})
+		remaining -= take
+	}
+	return usage, nil
+}
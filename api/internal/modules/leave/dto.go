@@ -1,17 +1,640 @@
 package leave
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/pkg/response"
+)
 
 // LeaveMonthlySummaryResponse represents monthly summary
 type LeaveMonthlySummaryResponse struct {
-	UserID        uint      `json:"userId"`
-	Year          int       `json:"year"`
-	Month         int       `json:"month"`
-	ExpectedUnits float64   `json:"expectedUnits"`
-	WorkedUnits   float64   `json:"workedUnits"`
-	MissingUnits  float64   `json:"missingUnits"`
-	PaidUsedUnits float64   `json:"paidUsedUnits"`
-	UnpaidUnits   float64   `json:"unpaidUnits"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	UserID            uint      `json:"userId"`
+	Year              int       `json:"year"`
+	Month             int       `json:"month"`
+	ExpectedUnits     float64   `json:"expectedUnits"`
+	WorkedUnits       float64   `json:"workedUnits"`
+	MissingUnits      float64   `json:"missingUnits"`
+	BirthdayUsedUnits float64   `json:"birthdayUsedUnits"`
+	IsBirthday        bool      `json:"isBirthday"`
+	PaidUsedUnits     float64   `json:"paidUsedUnits"`
+	CompOffUsedUnits  float64   `json:"compOffUsedUnits"`
+	UnpaidUnits       float64   `json:"unpaidUnits"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+	Stale             bool      `json:"stale,omitempty"` // a recompute is queued (attendance/calendar/balance changed)
+}
+
+func toLeaveMonthlySummaryResponse(s *MonthlySummary) LeaveMonthlySummaryResponse {
+	return LeaveMonthlySummaryResponse{
+		UserID:            s.UserID,
+		Year:              s.Year,
+		Month:             s.Month,
+		ExpectedUnits:     s.ExpectedUnits,
+		WorkedUnits:       s.WorkedUnits,
+		MissingUnits:      s.MissingUnits,
+		BirthdayUsedUnits: s.BirthdayUsedUnits,
+		IsBirthday:        s.IsBirthday,
+		PaidUsedUnits:     s.PaidUsedUnits,
+		CompOffUsedUnits:  s.CompOffUsedUnits,
+		UnpaidUnits:       s.UnpaidUnits,
+		UpdatedAt:         s.UpdatedAt,
+	}
+}
+
+// AccrualTierRequest represents a seniority band in an accrual policy request
+type AccrualTierRequest struct {
+	MinYears  int     `json:"minYears"`
+	BonusDays float64 `json:"bonusDays"`
+}
+
+// AccrualPolicyRequest is the body for creating/updating an accrual policy
+type AccrualPolicyRequest struct {
+	Name              string               `json:"name"`
+	DepartmentID      *uint                `json:"departmentId"`
+	MonthlyDays       float64              `json:"monthlyDays"`
+	ProRateFirstMonth *bool                `json:"proRateFirstMonth"` // default true
+	ProRateLastMonth  *bool                `json:"proRateLastMonth"`  // default true
+	IsDefault         bool                 `json:"isDefault"`
+	Tiers             []AccrualTierRequest `json:"tiers"`
+}
+
+func (r AccrualPolicyRequest) validate() error {
+	if len(r.Name) < 2 || len(r.Name) > 120 {
+		return response.Validation("name must be between 2 and 120 characters", nil)
+	}
+	if r.MonthlyDays < 0 || r.MonthlyDays > 31 {
+		return response.Validation("monthlyDays must be between 0 and 31", nil)
+	}
+	seen := make(map[int]bool)
+	for _, t := range r.Tiers {
+		if t.MinYears < 1 {
+			return response.Validation("tier minYears must be at least 1", nil)
+		}
+		if t.BonusDays < 0 {
+			return response.Validation("tier bonusDays must not be negative", nil)
+		}
+		if seen[t.MinYears] {
+			return response.Validation("duplicate tier minYears", nil)
+		}
+		seen[t.MinYears] = true
+	}
+	return nil
+}
+
+func (r AccrualPolicyRequest) applyTo(p *AccrualPolicy) {
+	p.Name = r.Name
+	p.DepartmentID = r.DepartmentID
+	if p.DepartmentID != nil && *p.DepartmentID == 0 {
+		p.DepartmentID = nil
+	}
+	p.MonthlyDays = r.MonthlyDays
+	p.ProRateFirstMonth = r.ProRateFirstMonth == nil || *r.ProRateFirstMonth
+	p.ProRateLastMonth = r.ProRateLastMonth == nil || *r.ProRateLastMonth
+	p.IsDefault = r.IsDefault
+	p.Tiers = make([]AccrualTier, len(r.Tiers))
+	for i, t := range r.Tiers {
+		p.Tiers[i] = AccrualTier{MinYears: t.MinYears, BonusDays: t.BonusDays}
+	}
+}
+
+// AccrualTierResponse represents a seniority band
+type AccrualTierResponse struct {
+	MinYears  int     `json:"minYears"`
+	BonusDays float64 `json:"bonusDays"`
+}
+
+// AccrualPolicyResponse represents an accrual policy
+type AccrualPolicyResponse struct {
+	ID                uint                  `json:"id"`
+	Name              string                `json:"name"`
+	DepartmentID      *uint                 `json:"departmentId"`
+	MonthlyDays       float64               `json:"monthlyDays"`
+	ProRateFirstMonth bool                  `json:"proRateFirstMonth"`
+	ProRateLastMonth  bool                  `json:"proRateLastMonth"`
+	IsDefault         bool                  `json:"isDefault"`
+	Tiers             []AccrualTierResponse `json:"tiers"`
+	UpdatedAt         time.Time             `json:"updatedAt"`
+}
+
+func toAccrualPolicyResponse(p *AccrualPolicy) AccrualPolicyResponse {
+	tiers := make([]AccrualTierResponse, len(p.Tiers))
+	for i, t := range p.Tiers {
+		tiers[i] = AccrualTierResponse{MinYears: t.MinYears, BonusDays: t.BonusDays}
+	}
+	return AccrualPolicyResponse{
+		ID:                p.ID,
+		Name:              p.Name,
+		DepartmentID:      p.DepartmentID,
+		MonthlyDays:       p.MonthlyDays,
+		ProRateFirstMonth: p.ProRateFirstMonth,
+		ProRateLastMonth:  p.ProRateLastMonth,
+		IsDefault:         p.IsDefault,
+		Tiers:             tiers,
+		UpdatedAt:         p.UpdatedAt,
+	}
+}
+
+// CompOffCreditResponse represents a comp-off credit
+type CompOffCreditResponse struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"userId"`
+	SessionID  uint       `json:"sessionId"`
+	WorkDate   string     `json:"workDate"`
+	Units      float64    `json:"units"`
+	UsedUnits  float64    `json:"usedUnits"`
+	Remaining  float64    `json:"remaining"`
+	Status     string     `json:"status"`
+	ExpiresAt  *string    `json:"expiresAt"`
+	Expired    bool       `json:"expired"`
+	ReviewedBy *uint      `json:"reviewedBy"`
+	ReviewedAt *time.Time `json:"reviewedAt"`
+	Note       *string    `json:"note"`
+}
+
+func toCompOffCreditResponse(c *CompOffCredit, today string) CompOffCreditResponse {
+	var expiresAt *string
+	expired := false
+	if c.ExpiresAt != nil {
+		e := c.ExpiresAt.Format("2006-01-02")
+		expiresAt = &e
+		expired = e < today
+	}
+	return CompOffCreditResponse{
+		ID:         c.ID,
+		UserID:     c.UserID,
+		SessionID:  c.SessionID,
+		WorkDate:   c.WorkDate.Format("2006-01-02"),
+		Units:      c.Units,
+		UsedUnits:  c.UsedUnits,
+		Remaining:  c.Remaining(),
+		Status:     c.Status,
+		ExpiresAt:  expiresAt,
+		Expired:    expired,
+		ReviewedBy: c.ReviewedBy,
+		ReviewedAt: c.ReviewedAt,
+		Note:       c.Note,
+	}
+}
+
+// DayAllocationResponse shows how one day of the month was covered
+type DayAllocationResponse struct {
+	Date          string  `json:"date"`
+	IsWorkingDay  bool    `json:"isWorkingDay"`
+	ExpectedUnits float64 `json:"expectedUnits"`
+	WorkedUnits   float64 `json:"workedUnits"`
+	SessionStatus string  `json:"sessionStatus,omitempty"`
+	MissingUnits  float64 `json:"missingUnits"`
+	BirthdayUnits float64 `json:"birthdayUnits"`
+	CompOffUnits  float64 `json:"compOffUnits"`
+	PaidUnits     float64 `json:"paidUnits"`
+	UnpaidUnits   float64 `json:"unpaidUnits"`
+	Coverage      string  `json:"coverage"`
+}
+
+// MonthlyBreakdownResponse is a monthly summary plus its per-day allocation
+type MonthlyBreakdownResponse struct {
+	Summary LeaveMonthlySummaryResponse `json:"summary"`
+	Days    []DayAllocationResponse     `json:"days"`
+}
+
+// ToMonthlyBreakdownResponse converts a breakdown for JSON (also used by the timesheet module)
+func ToMonthlyBreakdownResponse(b *MonthlyBreakdown) MonthlyBreakdownResponse {
+	days := make([]DayAllocationResponse, len(b.Days))
+	for i, d := range b.Days {
+		days[i] = DayAllocationResponse{
+			Date:          d.Date.Format("2006-01-02"),
+			IsWorkingDay:  d.IsWorkingDay,
+			ExpectedUnits: d.ExpectedUnits,
+			WorkedUnits:   d.WorkedUnits,
+			SessionStatus: d.SessionStatus,
+			MissingUnits:  d.MissingUnits,
+			BirthdayUnits: d.BirthdayUnits,
+			CompOffUnits:  d.CompOffUnits,
+			PaidUnits:     d.PaidUnits,
+			UnpaidUnits:   d.UnpaidUnits,
+			Coverage:      d.Coverage,
+		}
+	}
+	return MonthlyBreakdownResponse{
+		Summary: toLeaveMonthlySummaryResponse(b.Summary),
+		Days:    days,
+	}
+}
+
+// LeavePlanRowResponse is one user's line in a leave plan preview
+type LeavePlanRowResponse struct {
+	UserID           uint     `json:"userId"`
+	Name             string   `json:"name"`
+	Email            string   `json:"email"`
+	DepartmentID     *uint    `json:"departmentId,omitempty"`
+	CurrentBalance   float64  `json:"currentBalance"`
+	Policy           string   `json:"policy"`
+	GrantDays        float64  `json:"grantDays"`
+	DeductionDays    float64  `json:"deductionDays"`
+	CompOffDays      float64  `json:"compOffDays"`
+	UnpaidUnits      float64  `json:"unpaidUnits"`
+	ResultingBalance float64  `json:"resultingBalance"`
+	Warnings         []string `json:"warnings"`
+}
+
+// LeavePlanResponse is the grant/deduction preview for a month
+type LeavePlanResponse struct {
+	Year             int                    `json:"year"`
+	Month            int                    `json:"month"`
+	DeductionYear    int                    `json:"deductionYear"`
+	DeductionMonth   int                    `json:"deductionMonth"`
+	GrantPending     bool                   `json:"grantPending"`
+	DeductionPending bool                   `json:"deductionPending"`
+	TotalGrantDays   float64                `json:"totalGrantDays"`
+	TotalDeductDays  float64                `json:"totalDeductionDays"`
+	WarningCount     int                    `json:"warningCount"`
+	Checksum         string                 `json:"checksum"`
+	Rows             []LeavePlanRowResponse `json:"rows"`
+}
+
+func toLeavePlanResponse(p *LeavePlan) LeavePlanResponse {
+	res := LeavePlanResponse{
+		Year:             p.Year,
+		Month:            p.Month,
+		DeductionYear:    p.DeductionYear,
+		DeductionMonth:   p.DeductionMonth,
+		GrantPending:     p.GrantPending,
+		DeductionPending: p.DeductionPending,
+		Checksum:         p.Checksum(),
+		Rows:             make([]LeavePlanRowResponse, len(p.Rows)),
+	}
+	for i, r := range p.Rows {
+		warnings := r.Warnings
+		if warnings == nil {
+			warnings = []string{}
+		}
+		res.Rows[i] = LeavePlanRowResponse{
+			UserID:           r.UserID,
+			Name:             r.Name,
+			Email:            r.Email,
+			DepartmentID:     r.DepartmentID,
+			CurrentBalance:   r.CurrentBalance,
+			Policy:           r.Policy,
+			GrantDays:        r.GrantDays,
+			DeductionDays:    r.DeductionDays,
+			CompOffDays:      r.CompOffDays,
+			UnpaidUnits:      r.UnpaidUnits,
+			ResultingBalance: r.ResultingBalance,
+			Warnings:         warnings,
+		}
+		res.TotalGrantDays += r.GrantDays
+		res.TotalDeductDays += r.DeductionDays
+		if len(r.Warnings) > 0 {
+			res.WarningCount++
+		}
+	}
+	return res
+}
+
+// BirthdayPolicyRequest updates the birthday leave policy
+type BirthdayPolicyRequest struct {
+	Days             float64 `json:"days"`
+	Window           string  `json:"window"`     // MONTH | DAYS | QUARTER
+	WindowDays       int     `json:"windowDays"` // Window = DAYS: ±N days around the birthday
+	CarryOver        bool    `json:"carryOver"`
+	IncludeProbation bool    `json:"includeProbation"`
+	ProbationMonths  int     `json:"probationMonths"`
+}
+
+func (r BirthdayPolicyRequest) validate() error {
+	if r.Days < 0 || r.Days > 5 {
+		return response.Validation("days must be between 0 and 5", nil)
+	}
+	switch r.Window {
+	case BirthdayWindowMonth, BirthdayWindowQuarter:
+	case BirthdayWindowDays:
+		if r.WindowDays < 0 || r.WindowDays > 90 {
+			return response.Validation("windowDays must be between 0 and 90", nil)
+		}
+	default:
+		return response.Validation("window must be MONTH, DAYS or QUARTER", nil)
+	}
+	if r.ProbationMonths < 0 || r.ProbationMonths > 12 {
+		return response.Validation("probationMonths must be between 0 and 12", nil)
+	}
+	return nil
+}
+
+func (r BirthdayPolicyRequest) applyTo(p *BirthdayPolicy) {
+	p.Days = r.Days
+	p.Window = r.Window
+	p.WindowDays = 0
+	if r.Window == BirthdayWindowDays {
+		p.WindowDays = r.WindowDays
+	}
+	p.CarryOver = r.CarryOver
+	p.IncludeProbation = r.IncludeProbation
+	p.ProbationMonths = r.ProbationMonths
 }
 
+// BirthdayPolicyResponse represents the birthday leave policy
+type BirthdayPolicyResponse struct {
+	Days             float64    `json:"days"`
+	Window           string     `json:"window"`
+	WindowDays       int        `json:"windowDays"`
+	CarryOver        bool       `json:"carryOver"`
+	IncludeProbation bool       `json:"includeProbation"`
+	ProbationMonths  int        `json:"probationMonths"`
+	UpdatedBy        *uint      `json:"updatedBy,omitempty"`
+	UpdatedAt        *time.Time `json:"updatedAt,omitempty"`
+}
+
+func toBirthdayPolicyResponse(p *BirthdayPolicy) BirthdayPolicyResponse {
+	res := BirthdayPolicyResponse{
+		Days:             p.Days,
+		Window:           p.Window,
+		WindowDays:       p.WindowDays,
+		CarryOver:        p.CarryOver,
+		IncludeProbation: p.IncludeProbation,
+		ProbationMonths:  p.ProbationMonths,
+		UpdatedBy:        p.UpdatedBy,
+	}
+	if !p.UpdatedAt.IsZero() {
+		res.UpdatedAt = &p.UpdatedAt
+	}
+	return res
+}
+
+// RecomputeTaskResponse represents a queued summary recomputation
+type RecomputeTaskResponse struct {
+	ID            uint       `json:"id"`
+	UserID        uint       `json:"userId"`
+	Year          int        `json:"year"`
+	Month         int        `json:"month"`
+	Status        string     `json:"status"`
+	Reason        string     `json:"reason"`
+	BatchID       *uint      `json:"batchId,omitempty"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+	Error         *string    `json:"error,omitempty"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+func toRecomputeTaskResponse(t *RecomputeTask) RecomputeTaskResponse {
+	return RecomputeTaskResponse{
+		ID:            t.ID,
+		UserID:        t.UserID,
+		Year:          t.Year,
+		Month:         t.Month,
+		Status:        t.Status,
+		Reason:        t.Reason,
+		BatchID:       t.BatchID,
+		Attempts:      t.Attempts,
+		NextAttemptAt: t.NextAttemptAt,
+		StartedAt:     t.StartedAt,
+		FinishedAt:    t.FinishedAt,
+		Error:         t.Error,
+		UpdatedAt:     t.UpdatedAt,
+	}
+}
+
+// RecomputeBatchResponse represents a batch of queued recomputations and its progress
+type RecomputeBatchResponse struct {
+	ID          uint               `json:"id"`
+	Reason      string             `json:"reason"`
+	RequestedBy *uint              `json:"requestedBy,omitempty"`
+	Total       int                `json:"total"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	Progress    *RecomputeProgress `json:"progress"`
+}
+
+// SummaryTraceResponse is the step-by-step explanation of a monthly summary
+type SummaryTraceResponse struct {
+	UserID           uint                        `json:"userId"`
+	Year             int                         `json:"year"`
+	Month            int                         `json:"month"`
+	ComputedAt       string                      `json:"computedAt"`
+	Period           TracePeriodResponse         `json:"period"`
+	Days             []TraceDayResponse          `json:"days"`
+	Sessions         []TraceSessionResponse      `json:"sessions"`
+	ExpectedUnits    float64                     `json:"expectedUnits"`
+	WorkedUnits      float64                     `json:"workedUnits"`
+	Birthday         BirthdayTraceResponse       `json:"birthday"`
+	CompOffAvailable float64                     `json:"compOffAvailable"`
+	PaidLeaveBalance float64                     `json:"paidLeaveBalance"`
+	PaidAvailable    float64                     `json:"paidAvailable"`
+	Split            []SplitStepResponse         `json:"split"`
+	Summary          LeaveMonthlySummaryResponse `json:"summary"`
+}
+
+type TracePeriodResponse struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	CutOffAtToday bool   `json:"cutOffAtToday"`
+}
+
+type TraceDayResponse struct {
+	Date         string  `json:"date"`
+	IsWorkingDay bool    `json:"isWorkingDay"`
+	WorkUnit     float64 `json:"workUnit"`
+	Counted      bool    `json:"counted"`
+	Excluded     string  `json:"excluded,omitempty"`
+}
+
+type TraceSessionResponse struct {
+	SessionID     uint    `json:"sessionId"`
+	Date          string  `json:"date"`
+	Status        string  `json:"status"`
+	CheckInAt     string  `json:"checkInAt"`
+	CheckOutAt    *string `json:"checkOutAt"`
+	WorkedMinutes int     `json:"workedMinutes"`
+	DayUnit       float64 `json:"dayUnit"`
+	Counted       bool    `json:"counted"`
+	Excluded      string  `json:"excluded,omitempty"`
+}
+
+type BirthdayTraceResponse struct {
+	InWindow            bool    `json:"inWindow"`
+	WindowStart         *string `json:"windowStart"`
+	WindowEnd           *string `json:"windowEnd"`
+	Available           float64 `json:"available"`
+	CountedFrom         *string `json:"countedFrom"`
+	CountedTo           *string `json:"countedTo"`
+	WindowExpectedUnits float64 `json:"windowExpectedUnits"`
+	WindowWorkedUnits   float64 `json:"windowWorkedUnits"`
+	UsedUnits           float64 `json:"usedUnits"`
+}
+
+type SplitStepResponse struct {
+	Step      string  `json:"step"`
+	Units     float64 `json:"units"`
+	Remaining float64 `json:"remaining"`
+}
+
+func toSummaryTraceResponse(t *SummaryTrace, loc *time.Location) SummaryTraceResponse {
+	date := func(d *time.Time) *string {
+		if d == nil {
+			return nil
+		}
+		s := d.Format("2006-01-02")
+		return &s
+	}
+
+	days := make([]TraceDayResponse, len(t.Days))
+	for i, d := range t.Days {
+		days[i] = TraceDayResponse{
+			Date:         d.Date.Format("2006-01-02"),
+			IsWorkingDay: d.IsWorkingDay,
+			WorkUnit:     d.WorkUnit,
+			Counted:      d.Counted,
+			Excluded:     d.Excluded,
+		}
+	}
+
+	sessions := make([]TraceSessionResponse, len(t.Sessions))
+	for i, s := range t.Sessions {
+		var checkOut *string
+		if s.CheckOutAt != nil {
+			co := s.CheckOutAt.In(loc).Format(time.RFC3339)
+			checkOut = &co
+		}
+		sessions[i] = TraceSessionResponse{
+			SessionID:     s.SessionID,
+			Date:          s.Date.Format("2006-01-02"),
+			Status:        s.Status,
+			CheckInAt:     s.CheckInAt.In(loc).Format(time.RFC3339),
+			CheckOutAt:    checkOut,
+			WorkedMinutes: s.WorkedMinutes,
+			DayUnit:       s.DayUnit,
+			Counted:       s.Counted,
+			Excluded:      s.Excluded,
+		}
+	}
+
+	split := make([]SplitStepResponse, len(t.Split))
+	for i, s := range t.Split {
+		split[i] = SplitStepResponse{Step: s.Step, Units: s.Units, Remaining: s.Remaining}
+	}
+
+	b := t.Birthday
+	return SummaryTraceResponse{
+		UserID:     t.UserID,
+		Year:       t.Year,
+		Month:      t.Month,
+		ComputedAt: t.ComputedAt.Format(time.RFC3339),
+		Period: TracePeriodResponse{
+			From:          t.PeriodStart.Format("2006-01-02"),
+			To:            t.PeriodEnd.Format("2006-01-02"),
+			CutOffAtToday: t.CutOffAtToday,
+		},
+		Days:          days,
+		Sessions:      sessions,
+		ExpectedUnits: t.ExpectedUnits,
+		WorkedUnits:   t.WorkedUnits,
+		Birthday: BirthdayTraceResponse{
+			InWindow:            b.InWindow,
+			WindowStart:         date(b.WindowStart),
+			WindowEnd:           date(b.WindowEnd),
+			Available:           b.Available,
+			CountedFrom:         date(b.CountedFrom),
+			CountedTo:           date(b.CountedTo),
+			WindowExpectedUnits: b.WindowExpectedUnits,
+			WindowWorkedUnits:   b.WindowWorkedUnits,
+			UsedUnits:           b.UsedUnits,
+		},
+		CompOffAvailable: t.CompOffAvailable,
+		PaidLeaveBalance: t.PaidLeaveBalance,
+		PaidAvailable:    t.PaidAvailable,
+		Split:            split,
+		Summary:          toLeaveMonthlySummaryResponse(t.Summary),
+	}
+}
+
+// SummarySnapshotResponse is one version of a monthly summary
+type SummarySnapshotResponse struct {
+	ID                uint     `json:"id"`
+	UserID            uint     `json:"userId"`
+	Year              int      `json:"year"`
+	Month             int      `json:"month"`
+	Version           int      `json:"version"`
+	ExpectedUnits     float64  `json:"expectedUnits"`
+	WorkedUnits       float64  `json:"workedUnits"`
+	MissingUnits      float64  `json:"missingUnits"`
+	BirthdayUsedUnits float64  `json:"birthdayUsedUnits"`
+	PaidUsedUnits     float64  `json:"paidUsedUnits"`
+	CompOffUsedUnits  float64  `json:"compOffUsedUnits"`
+	UnpaidUnits       float64  `json:"unpaidUnits"`
+	IsBirthday        bool     `json:"isBirthday"`
+	Trigger           string   `json:"trigger"`
+	ChangedFields     []string `json:"changedFields"`
+	Alert             bool     `json:"alert"`
+	AcknowledgedBy    *uint    `json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt    *string  `json:"acknowledgedAt,omitempty"`
+	CreatedAt         string   `json:"createdAt"`
+}
+
+func toSummarySnapshotResponse(s *SummarySnapshot) SummarySnapshotResponse {
+	changed := []string{}
+	if s.ChangedFields != nil && *s.ChangedFields != "" {
+		changed = strings.Split(*s.ChangedFields, ",")
+	}
+	var ackAt *string
+	if s.AcknowledgedAt != nil {
+		t := s.AcknowledgedAt.Format(time.RFC3339)
+		ackAt = &t
+	}
+	return SummarySnapshotResponse{
+		ID:                s.ID,
+		UserID:            s.UserID,
+		Year:              s.Year,
+		Month:             s.Month,
+		Version:           s.Version,
+		ExpectedUnits:     s.ExpectedUnits,
+		WorkedUnits:       s.WorkedUnits,
+		MissingUnits:      s.MissingUnits,
+		BirthdayUsedUnits: s.BirthdayUsedUnits,
+		PaidUsedUnits:     s.PaidUsedUnits,
+		CompOffUsedUnits:  s.CompOffUsedUnits,
+		UnpaidUnits:       s.UnpaidUnits,
+		IsBirthday:        s.IsBirthday,
+		Trigger:           s.Trigger,
+		ChangedFields:     changed,
+		Alert:             s.Alert,
+		AcknowledgedBy:    s.AcknowledgedBy,
+		AcknowledgedAt:    ackAt,
+		CreatedAt:         s.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toSummarySnapshotResponses(snaps []SummarySnapshot) []SummarySnapshotResponse {
+	res := make([]SummarySnapshotResponse, len(snaps))
+	for i := range snaps {
+		res[i] = toSummarySnapshotResponse(&snaps[i])
+	}
+	return res
+}
+
+type SnapshotFieldChangeResponse struct {
+	Field string  `json:"field"`
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Delta float64 `json:"delta"`
+}
+
+// SnapshotDiffResponse compares two versions of a monthly summary
+type SnapshotDiffResponse struct {
+	From              SummarySnapshotResponse       `json:"from"`
+	To                SummarySnapshotResponse       `json:"to"`
+	Changes           []SnapshotFieldChangeResponse `json:"changes"`
+	IsBirthdayChanged bool                          `json:"isBirthdayChanged"`
+}
+
+func toSnapshotDiffResponse(d *SnapshotDiff) SnapshotDiffResponse {
+	changes := make([]SnapshotFieldChangeResponse, len(d.Changes))
+	for i, c := range d.Changes {
+		changes[i] = SnapshotFieldChangeResponse{Field: c.Field, From: c.From, To: c.To, Delta: c.Delta}
+	}
+	return SnapshotDiffResponse{
+		From:              toSummarySnapshotResponse(d.From),
+		To:                toSummarySnapshotResponse(d.To),
+		Changes:           changes,
+		IsBirthdayChanged: d.From.IsBirthday != d.To.IsBirthday,
+	}
+}
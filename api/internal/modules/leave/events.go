@@ -0,0 +1,26 @@
+package leave
+
+import (
+	"context"
+
+	"time-attendance-be/internal/modules/attendance"
+	"time-attendance-be/internal/platform/events"
+)
+
+// SubscribeEvents registers the leave module's reactions to other modules' domain events
+func (s *Service) SubscribeEvents(bus *events.Bus) {
+	bus.Subscribe(attendance.EventSessionChanged, s.onSessionChanged)
+}
+
+// onSessionChanged queues recomputation of the summary for the session's month.
+// A check-in only opens a session, which does not count until it is closed.
+func (s *Service) onSessionChanged(ctx context.Context, e events.Event) error {
+	ev, ok := e.(attendance.SessionChanged)
+	if !ok || ev.Action == attendance.SessionCheckedIn {
+		return nil
+	}
+
+	key := RecomputeKey{UserID: ev.UserID, Year: ev.WorkDate.Year(), Month: int(ev.WorkDate.Month())}
+	_, err := s.EnqueueRecompute(ctx, []RecomputeKey{key}, RecomputeReasonAttendance, nil)
+	return err
+}
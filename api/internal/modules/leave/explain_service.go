@@ -0,0 +1,183 @@
+package leave
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Reasons a calendar day or session is excluded from a monthly summary
+const (
+	ExcludedAfterCutOff   = "AFTER_CUT_OFF"   // after today in the current month
+	ExcludedNonWorkingDay = "NON_WORKING_DAY" // holiday/weekend: no expected units, sessions earn comp-off instead
+	ExcludedOpenSession   = "OPEN_SESSION"    // not checked out; only CLOSED sessions count as worked
+)
+
+// Steps of the final split of missing units, in allocation order
+const (
+	SplitMissing  = "MISSING"
+	SplitBirthday = "BIRTHDAY"
+	SplitCompOff  = "COMP_OFF"
+	SplitPaid     = "PAID"
+	SplitUnpaid   = "UNPAID"
+)
+
+// SummaryTrace explains how a monthly summary was computed, step by step
+type SummaryTrace struct {
+	UserID     uint
+	Year       int
+	Month      int
+	ComputedAt time.Time
+
+	// Counted period: the whole month, or up to today for the current month
+	PeriodStart   time.Time
+	PeriodEnd     time.Time
+	CutOffAtToday bool
+
+	Days          []TraceDay
+	Sessions      []TraceSession
+	ExpectedUnits float64 // sum of WorkUnit of counted working days
+	WorkedUnits   float64 // sum of DayUnit of counted sessions
+
+	Birthday         BirthdayTrace
+	CompOffAvailable float64 // usable comp-off credits (only looked up when units are still missing after birthday leave)
+	PaidLeaveBalance float64 // users.paid_leave at compute time
+	PaidAvailable    float64 // PaidLeaveBalance clamped at 0
+
+	Split   []SplitStep
+	Summary *MonthlySummary
+}
+
+// TraceDay is one calendar day of the month
+type TraceDay struct {
+	Date         time.Time
+	IsWorkingDay bool
+	WorkUnit     float64
+	Counted      bool   // contributes WorkUnit to expected units
+	Excluded     string // reason when not counted
+}
+
+// TraceSession is one attendance session of the month and its DayUnit contribution
+type TraceSession struct {
+	SessionID     uint
+	Date          time.Time
+	Status        string
+	CheckInAt     time.Time
+	CheckOutAt    *time.Time
+	WorkedMinutes int
+	DayUnit       float64
+	Counted       bool   // contributes DayUnit to worked units
+	Excluded      string // reason when not counted
+}
+
+// BirthdayTrace explains the birthday leave allocation
+type BirthdayTrace struct {
+	InWindow            bool       // the month overlaps the user's birthday window
+	WindowStart         *time.Time // per birthday policy
+	WindowEnd           *time.Time
+	Available           float64    // birthday days left at the start of the month
+	CountedFrom         *time.Time // window clipped to the counted period
+	CountedTo           *time.Time
+	WindowExpectedUnits float64 // expected units of working days inside the clipped window
+	WindowWorkedUnits   float64 // worked units inside the clipped window
+	UsedUnits           float64 // min(expected - worked, available, missing)
+}
+
+// SplitStep is one step of allocating missing units; Remaining is what is left uncovered after it
+type SplitStep struct {
+	Step      string
+	Units     float64
+	Remaining float64
+}
+
+// ExplainMonthlySummary computes a user's monthly summary exactly like ComputeMonthlySummary
+// (without persisting it) and returns the trace of how each figure was derived.
+func (s *Service) ExplainMonthlySummary(ctx context.Context, userID uint, year, month int) (*SummaryTrace, error) {
+	in, err := s.loadSummaryInputs(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.cfg.TimeLocation()
+	monthEnd := in.Start.AddDate(0, 1, -1)
+	trace := &SummaryTrace{
+		UserID:           userID,
+		Year:             year,
+		Month:            month,
+		ComputedAt:       time.Now().In(loc),
+		PeriodStart:      in.Start,
+		PeriodEnd:        in.End,
+		CutOffAtToday:    in.End.Before(monthEnd),
+		PaidLeaveBalance: in.PaidLeave,
+	}
+	if in.Window != nil {
+		trace.Birthday.InWindow = true
+		trace.Birthday.WindowStart = &in.Window.Start
+		trace.Birthday.WindowEnd = &in.Window.End
+		trace.Birthday.Available = in.Window.Available
+	}
+	in.Trace = trace
+
+	summary, err := buildMonthlySummary(in)
+	if err != nil {
+		return nil, err
+	}
+	trace.Summary = summary
+	trace.Birthday.UsedUnits = summary.BirthdayUsedUnits
+
+	// Calendar of the whole month, so days after the cut-off are listed too
+	calDays, err := s.workCalRepo.ListRange(ctx, in.Start, monthEnd)
+	if err != nil {
+		return nil, fmt.Errorf("list calendar: %w", err)
+	}
+	endStr := in.End.Format("2006-01-02")
+	workingDay := make(map[string]bool, len(calDays))
+	trace.Days = make([]TraceDay, 0, len(calDays))
+	for _, d := range calDays {
+		dateStr := d.WorkDate.Format("2006-01-02")
+		day := TraceDay{Date: d.WorkDate, IsWorkingDay: d.IsWorkingDay, WorkUnit: d.WorkUnit}
+		switch {
+		case dateStr > endStr:
+			day.Excluded = ExcludedAfterCutOff
+		case !d.IsWorkingDay || d.WorkUnit <= 0:
+			day.Excluded = ExcludedNonWorkingDay
+		default:
+			day.Counted = true
+		}
+		workingDay[dateStr] = d.IsWorkingDay
+		trace.Days = append(trace.Days, day)
+	}
+
+	// Sessions count like SumDayUnitByRange: CLOSED, inside the period, on a working day
+	sessions, err := s.attendanceRepo.ListByUserDateRange(userID, in.Start.Format("2006-01-02"), monthEnd.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	trace.Sessions = make([]TraceSession, 0, len(sessions))
+	for i := len(sessions) - 1; i >= 0; i-- { // repo returns newest first
+		sess := &sessions[i]
+		dateStr := sess.WorkDate.Format("2006-01-02")
+		ts := TraceSession{
+			SessionID:     sess.ID,
+			Date:          sess.WorkDate,
+			Status:        sess.Status,
+			CheckInAt:     sess.CheckInAt,
+			CheckOutAt:    sess.CheckOutAt,
+			WorkedMinutes: sess.WorkedMinutes,
+			DayUnit:       float64(sess.DayUnit),
+		}
+		switch {
+		case dateStr > endStr:
+			ts.Excluded = ExcludedAfterCutOff
+		case !workingDay[dateStr]:
+			ts.Excluded = ExcludedNonWorkingDay
+		case sess.Status != "CLOSED":
+			ts.Excluded = ExcludedOpenSession
+		default:
+			ts.Counted = true
+		}
+		trace.Sessions = append(trace.Sessions, ts)
+	}
+
+	return trace, nil
+}
@@ -5,17 +5,19 @@ import (
 	"time"
 
 	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
 	"time-attendance-be/internal/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type Handler struct {
-	svc *Service
+	svc      *Service
+	auditSvc *audit.Service
 }
 
-func NewHandler(svc *Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc *Service, auditSvc *audit.Service) *Handler {
+	return &Handler{svc: svc, auditSvc: auditSvc}
 }
 
 // POST /api/v1/admin/leave/grant
@@ -82,22 +84,12 @@ func (h *Handler) GetMyLeaveSummary(c *fiber.Ctx) error {
 		}
 	}
 
-	summary, err := h.svc.ComputeMonthlySummary(c.Context(), user.ID, year, month)
+	summary, err := h.svc.ProjectMonthlySummary(c.Context(), user.ID, year, month)
 	if err != nil {
 		return response.Internal(err)
 	}
 
-	return response.OK(c, LeaveMonthlySummaryResponse{
-		UserID:        summary.UserID,
-		Year:          summary.Year,
-		Month:         summary.Month,
-		ExpectedUnits: summary.ExpectedUnits,
-		WorkedUnits:   summary.WorkedUnits,
-		MissingUnits:  summary.MissingUnits,
-		PaidUsedUnits: summary.PaidUsedUnits,
-		UnpaidUnits:   summary.UnpaidUnits,
-		UpdatedAt:     summary.UpdatedAt,
-	})
+	return response.OK(c, toLeaveMonthlySummaryResponse(summary))
 }
 
 // GET /api/v1/admin/leave/summary?userId=&year=&month=
@@ -126,22 +118,140 @@ func (h *Handler) AdminGetLeaveSummary(c *fiber.Ctx) error {
 		}
 	}
 
-	summary, err := h.svc.ComputeMonthlySummary(c.Context(), userID, year, month)
+	summary, err := h.svc.ProjectMonthlySummary(c.Context(), userID, year, month)
 	if err != nil {
 		return response.Internal(err)
 	}
 
-	return response.OK(c, LeaveMonthlySummaryResponse{
-		UserID:        summary.UserID,
-		Year:          summary.Year,
-		Month:         summary.Month,
-		ExpectedUnits: summary.ExpectedUnits,
-		WorkedUnits:   summary.WorkedUnits,
-		MissingUnits:  summary.MissingUnits,
-		PaidUsedUnits: summary.PaidUsedUnits,
-		UnpaidUnits:   summary.UnpaidUnits,
-		UpdatedAt:     summary.UpdatedAt,
-	})
+	return response.OK(c, toLeaveMonthlySummaryResponse(summary))
+}
+
+// GET /api/v1/me/leave/summary/explain?year=&month=
+// Step-by-step trace of how the user's own monthly summary is computed
+func (h *Handler) ExplainMyLeaveSummary(c *fiber.Ctx) error {
+	user := authx.GetUser(c)
+	if user == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	now := time.Now()
+	year := now.Year()
+	month := int(now.Month())
+	if y := c.Query("year"); y != "" {
+		if yInt, err := strconv.Atoi(y); err == nil {
+			year = yInt
+		}
+	}
+	if m := c.Query("month"); m != "" {
+		if mInt, err := strconv.Atoi(m); err == nil && mInt >= 1 && mInt <= 12 {
+			month = mInt
+		}
+	}
+
+	trace, err := h.svc.ExplainMonthlySummary(c.Context(), user.ID, year, month)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	return response.OK(c, toSummaryTraceResponse(trace, h.svc.cfg.TimeLocation()))
+}
+
+// GET /api/v1/admin/leave/summary/explain?userId=&year=&month=
+// Step-by-step trace of ComputeMonthlySummary: counted days, sessions, birthday window, balances and the final split.
+// Read-only: the stored summary is not updated.
+func (h *Handler) AdminExplainLeaveSummary(c *fiber.Ctx) error {
+	userIDStr := c.Query("userId")
+	if userIDStr == "" {
+		return response.Validation("userId is required", nil)
+	}
+	userID64, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return response.Validation("invalid userId", nil)
+	}
+
+	now := time.Now()
+	year := now.Year()
+	month := int(now.Month())
+	if y := c.Query("year"); y != "" {
+		if yInt, err := strconv.Atoi(y); err == nil {
+			year = yInt
+		}
+	}
+	if m := c.Query("month"); m != "" {
+		if mInt, err := strconv.Atoi(m); err == nil && mInt >= 1 && mInt <= 12 {
+			month = mInt
+		}
+	}
+
+	trace, err := h.svc.ExplainMonthlySummary(c.Context(), uint(userID64), year, month)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	return response.OK(c, toSummaryTraceResponse(trace, h.svc.cfg.TimeLocation()))
+}
+
+// GET /api/v1/me/leave/days?year=&month=
+// Day-by-day allocation of the monthly summary (which balance covered each day)
+func (h *Handler) GetMyLeaveDays(c *fiber.Ctx) error {
+	user := authx.GetUser(c)
+	if user == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	now := time.Now()
+	year := now.Year()
+	month := int(now.Month())
+	if y := c.Query("year"); y != "" {
+		if yInt, err := strconv.Atoi(y); err == nil {
+			year = yInt
+		}
+	}
+	if m := c.Query("month"); m != "" {
+		if mInt, err := strconv.Atoi(m); err == nil && mInt >= 1 && mInt <= 12 {
+			month = mInt
+		}
+	}
+
+	b, err := h.svc.ProjectMonthlyBreakdown(c.Context(), user.ID, year, month)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	return response.OK(c, ToMonthlyBreakdownResponse(b))
+}
+
+// GET /api/v1/admin/leave/days?userId=&year=&month=
+func (h *Handler) AdminGetLeaveDays(c *fiber.Ctx) error {
+	userIDStr := c.Query("userId")
+	if userIDStr == "" {
+		return response.Validation("userId is required", nil)
+	}
+	userID64, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return response.Validation("invalid userId", nil)
+	}
+
+	now := time.Now()
+	year := now.Year()
+	month := int(now.Month())
+	if y := c.Query("year"); y != "" {
+		if yInt, err := strconv.Atoi(y); err == nil {
+			year = yInt
+		}
+	}
+	if m := c.Query("month"); m != "" {
+		if mInt, err := strconv.Atoi(m); err == nil && mInt >= 1 && mInt <= 12 {
+			month = mInt
+		}
+	}
+
+	b, err := h.svc.ProjectMonthlyBreakdown(c.Context(), uint(userID64), year, month)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	return response.OK(c, ToMonthlyBreakdownResponse(b))
 }
 
 // GET /api/v1/me/leave/stats
@@ -181,72 +291,86 @@ func (h *Handler) GetLeaveStats(c *fiber.Ctx) error {
 
 // GET /api/v1/admin/leave/summaries?year=&month=&userId=&departmentId=
 func (h *Handler) AdminListSummaries(c *fiber.Ctx) error {
+	return h.listSummaries(c, parseSummaryListFilter(c))
+}
+
+// GET /api/v1/manager/leave/summaries?year=&month=&userId=&departmentId=
+// Same listing as the admin one, limited to the departments the user manages
+func (h *Handler) ManagerListSummaries(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	filter := parseSummaryListFilter(c)
+	ids, err := a.ManagerScope().Narrow(filter.DepartmentID)
+	if err != nil {
+		return err
+	}
+	filter.DepartmentIDs = ids
+	return h.listSummaries(c, filter)
+}
+
+func (h *Handler) listSummaries(c *fiber.Ctx, filter SummaryListFilter) error {
+	summaries, err := h.svc.ListMonthlySummaries(c.Context(), filter)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	pending, err := h.svc.PendingRecomputeUsers(c.Context(), filter.Year, filter.Month)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	results := make([]LeaveMonthlySummaryResponse, len(summaries))
+	for i := range summaries {
+		results[i] = toLeaveMonthlySummaryResponse(&summaries[i])
+		results[i].Stale = pending[summaries[i].UserID]
+	}
+
+	return response.OK(c, results)
+}
+
+// parseSummaryListFilter reads year/month (default: current month), userId and departmentId
+func parseSummaryListFilter(c *fiber.Ctx) SummaryListFilter {
 	now := time.Now()
-	year := now.Year()
-	month := int(now.Month())
-	
+	filter := SummaryListFilter{Year: now.Year(), Month: int(now.Month())}
+
 	if y := c.Query("year"); y != "" {
 		if yInt, err := strconv.Atoi(y); err == nil {
-			year = yInt
+			filter.Year = yInt
 		}
 	}
 	if m := c.Query("month"); m != "" {
 		if mInt, err := strconv.Atoi(m); err == nil && mInt >= 1 && mInt <= 12 {
-			month = mInt
+			filter.Month = mInt
 		}
 	}
-	
-	var userID *uint
+
 	if userIdStr := c.Query("userId"); userIdStr != "" {
 		if userId64, err := strconv.ParseUint(userIdStr, 10, 64); err == nil {
 			uid := uint(userId64)
-			userID = &uid
+			filter.UserID = &uid
 		}
 	}
-	
-	var departmentID *uint
+
 	if deptIdStr := c.Query("departmentId"); deptIdStr != "" {
 		if deptId64, err := strconv.ParseUint(deptIdStr, 10, 64); err == nil {
 			did := uint(deptId64)
-			departmentID = &did
-		}
-	}
-	
-	summaries, err := h.svc.ListMonthlySummaries(c.Context(), year, month, userID, departmentID)
-	if err != nil {
-		return response.Internal(err)
-	}
-	
-	results := make([]LeaveMonthlySummaryResponse, len(summaries))
-	for i, s := range summaries {
-		results[i] = LeaveMonthlySummaryResponse{
-			UserID:        s.UserID,
-			Year:          s.Year,
-			Month:         s.Month,
-			ExpectedUnits: s.ExpectedUnits,
-			WorkedUnits:   s.WorkedUnits,
-			MissingUnits:  s.MissingUnits,
-			PaidUsedUnits: s.PaidUsedUnits,
-			UnpaidUnits:   s.UnpaidUnits,
-			UpdatedAt:     s.UpdatedAt,
+			filter.DepartmentID = &did
 		}
 	}
-	
-	return response.OK(c, results)
+	return filter
 }
 
 // POST /api/v1/admin/leave/summary/recalculate?userId=&year=&month=
+// Queues recomputation for one user, or for every user with a summary in the month when userId is omitted.
+// Follow progress via GET /api/v1/admin/leave/recompute/batches/:id
 func (h *Handler) AdminRecalculateSummary(c *fiber.Ctx) error {
-	userIDStr := c.Query("userId")
-	if userIDStr == "" {
-		return response.Validation("userId is required", nil)
-	}
-	userID64, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil {
-		return response.Validation("invalid userId", nil)
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
 	}
-	userID := uint(userID64)
-	
+
 	now := time.Now()
 	year := now.Year()
 	month := int(now.Month())
@@ -260,23 +384,27 @@ func (h *Handler) AdminRecalculateSummary(c *fiber.Ctx) error {
 			month = mInt
 		}
 	}
-	
-	summary, err := h.svc.ComputeMonthlySummary(c.Context(), userID, year, month)
-	if err != nil {
-		return response.Internal(err)
+
+	var batch *RecomputeBatch
+	if userIDStr := c.Query("userId"); userIDStr != "" {
+		userID64, err := strconv.ParseUint(userIDStr, 10, 64)
+		if err != nil {
+			return response.Validation("invalid userId", nil)
+		}
+		key := RecomputeKey{UserID: uint(userID64), Year: year, Month: month}
+		batch, err = h.svc.EnqueueRecompute(c.Context(), []RecomputeKey{key}, RecomputeReasonManual, &adminUser.ID)
+		if err != nil {
+			return response.Internal(err)
+		}
+	} else {
+		var err error
+		batch, err = h.svc.EnqueueRecomputeMonth(c.Context(), year, month, RecomputeReasonManual, &adminUser.ID)
+		if err != nil {
+			return response.Internal(err)
+		}
 	}
-	
-	return response.OK(c, LeaveMonthlySummaryResponse{
-		UserID:        summary.UserID,
-		Year:          summary.Year,
-		Month:         summary.Month,
-		ExpectedUnits: summary.ExpectedUnits,
-		WorkedUnits:   summary.WorkedUnits,
-		MissingUnits:  summary.MissingUnits,
-		PaidUsedUnits: summary.PaidUsedUnits,
-		UnpaidUnits:   summary.UnpaidUnits,
-		UpdatedAt:     summary.UpdatedAt,
-	})
+
+	return h.recomputeBatchResponse(c, batch)
 }
 
 // GET /api/v1/admin/leave/grants?year=&month=
@@ -285,18 +413,18 @@ func (h *Handler) AdminListGrants(c *fiber.Ctx) error {
 	if err != nil {
 		return response.Internal(err)
 	}
-	
+
 	results := make([]map[string]interface{}, len(grants))
 	for i, g := range grants {
 		results[i] = map[string]interface{}{
-			"id":        g.ID,
-			"grantYear": g.GrantYear,
+			"id":         g.ID,
+			"grantYear":  g.GrantYear,
 			"grantMonth": g.GrantMonth,
-			"grantType": g.GrantType,
-			"createdAt": g.CreatedAt,
+			"grantType":  g.GrantType,
+			"createdAt":  g.CreatedAt,
 		}
 	}
-	
+
 	return response.OK(c, results)
 }
 
@@ -311,52 +439,42 @@ func (h *Handler) AdminAdjustPaidLeave(c *fiber.Ctx) error {
 	userIDStr := c.Params("userId")
 	yearStr := c.Params("year")
 	monthStr := c.Params("month")
-	
+
 	userID64, err := strconv.ParseUint(userIDStr, 10, 64)
 	if err != nil {
 		return response.Validation("invalid userId", nil)
 	}
 	userID := uint(userID64)
-	
+
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
 		return response.Validation("invalid year", nil)
 	}
-	
+
 	month, err := strconv.Atoi(monthStr)
 	if err != nil || month < 1 || month > 12 {
 		return response.Validation("invalid month", nil)
 	}
-	
+
 	var req AdjustPaidLeaveRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.Validation("Invalid body", nil)
 	}
-	
+
 	if req.Reason == "" {
 		return response.Validation("reason is required", nil)
 	}
-	
+
 	// Update user's paid_leave via service
 	if err := h.svc.AdjustUserPaidLeave(c.Context(), userID, req.PaidLeave); err != nil {
 		return response.Internal(err)
 	}
-	
+
 	// Recompute summary
-	summary, err := h.svc.ComputeMonthlySummary(c.Context(), userID, year, month)
+	summary, err := h.svc.ComputeMonthlySummary(c.Context(), userID, year, month, SnapshotTriggerManual)
 	if err != nil {
 		return response.Internal(err)
 	}
-	
-	return response.OK(c, LeaveMonthlySummaryResponse{
-		UserID:        summary.UserID,
-		Year:          summary.Year,
-		Month:         summary.Month,
-		ExpectedUnits: summary.ExpectedUnits,
-		WorkedUnits:   summary.WorkedUnits,
-		MissingUnits:  summary.MissingUnits,
-		PaidUsedUnits: summary.PaidUsedUnits,
-		UnpaidUnits:   summary.UnpaidUnits,
-		UpdatedAt:     summary.UpdatedAt,
-	})
+
+	return response.OK(c, toLeaveMonthlySummaryResponse(summary))
 }
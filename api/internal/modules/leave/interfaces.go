@@ -3,6 +3,8 @@ package leave
 import (
 	"context"
 	"time"
+
+	"time-attendance-be/internal/modules/user"
 )
 
 // WorkCalendarDay defines the structure for a calendar day that the leave module needs.
@@ -19,3 +21,23 @@ type WorkCalendarRepo interface {
 	EnsureYear(ctx context.Context, year int) error
 	ListRange(ctx context.Context, fromDate, toDate time.Time) ([]WorkCalendarDay, error)
 }
+
+// SummaryRepo is what the summary projections read from the leave tables.
+// Implemented by *Repo; tests substitute a fake.
+type SummaryRepo interface {
+	GetBirthdayPolicy(ctx context.Context) (*BirthdayPolicy, error)
+	SumBirthdayUsed(ctx context.Context, userID uint, fromYM, toYM int) (float64, error)
+	ListBirthdayUsed(ctx context.Context, fromYM, toYM int) ([]MonthlySummary, error)
+	ListCompOffAvailable(ctx context.Context, userID *uint, year, month int, asOf, until time.Time) (map[uint]float64, error)
+}
+
+// SettlementRepo returns the paid leave balance offboarded users were settled from, as their
+// users.paid_leave is zeroed at offboarding (implemented by offboarding.Repo)
+type SettlementRepo interface {
+	ListPaidLeaveBefore(ctx context.Context, userIDs []uint) (map[uint]float64, error)
+}
+
+// UserLookup loads a user (implemented by *user.Repo)
+type UserLookup interface {
+	GetByID(ctx context.Context, id uint) (*user.User, error)
+}
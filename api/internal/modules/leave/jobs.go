@@ -0,0 +1,77 @@
+package leave
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"time-attendance-be/internal/platform/scheduler"
+)
+
+// RegisterJobs puts the leave module's periodic work on the scheduler (cron in APP_TZ).
+// Jobs that run on start catch up after downtime; each is idempotent.
+func (s *Service) RegisterJobs(sch *scheduler.Scheduler) {
+	sch.MustRegister(scheduler.Job{
+		Name:        "calendar.ensure-year",
+		Description: "Generate work calendar rows for the current year if missing",
+		Schedule:    "0 0 * * *",
+		RunOnStart:  true,
+		Run:         s.ensureCalendarYear,
+	})
+	sch.MustRegister(scheduler.Job{
+		Name:        "leave.monthly-grant",
+		Description: "Grant monthly leave per accrual policy (skips if already granted this month)",
+		Schedule:    "5 0 * * *",
+		RunOnStart:  true,
+		Run:         s.ProcessMonthlyLeaveGrant,
+	})
+	sch.MustRegister(scheduler.Job{
+		Name:        "leave.previous-month-deduction",
+		Description: "Deduct previous month's paid leave and comp-off usage (1st of month only)",
+		Schedule:    "10 0 1 * *",
+		RunOnStart:  true,
+		Run:         s.ProcessPreviousMonthLeaveDeduction,
+	})
+	sch.MustRegister(scheduler.Job{
+		Name:        "leave.summary-backfill",
+		Description: "Compute summaries for months that have attendance but no summary",
+		Schedule:    "0 2 * * 1",
+		RunOnStart:  true,
+		Run:         s.ProcessSummaryBackfill,
+	})
+	sch.MustRegister(scheduler.Job{
+		Name:        "leave.comp-off-detect",
+		Description: "Detect work on non-working days in the last 7 days (comp-off candidates)",
+		Schedule:    "30 0 * * *",
+		RunOnStart:  true,
+		Run:         s.detectRecentCompOff,
+	})
+}
+
+// ensureCalendarYear makes sure the work calendar for the current year exists
+func (s *Service) ensureCalendarYear(ctx context.Context) (scheduler.Result, error) {
+	var res scheduler.Result
+	if s.workCalRepo == nil {
+		return res, fmt.Errorf("work calendar repo not set")
+	}
+	year := time.Now().In(s.cfg.TimeLocation()).Year()
+	if err := s.workCalRepo.EnsureYear(ctx, year); err != nil {
+		return res, err
+	}
+	res.Processed = 1
+	res.Message = fmt.Sprintf("calendar %d ensured", year)
+	return res, nil
+}
+
+// detectRecentCompOff scans the last 7 days for sessions on non-working days
+func (s *Service) detectRecentCompOff(ctx context.Context) (scheduler.Result, error) {
+	var res scheduler.Result
+	to := time.Now().In(s.cfg.TimeLocation())
+	from := to.AddDate(0, 0, -7)
+	created, err := s.DetectCompOffCredits(ctx, from, to)
+	if err != nil {
+		return res, err
+	}
+	res.Processed = int(created)
+	return res, nil
+}
@@ -12,7 +12,7 @@ type LeaveGrant struct {
 	ID        uint           `gorm:"primaryKey"`
 	GrantYear int            `gorm:"not null"`
 	GrantMonth int           `gorm:"not null"`
-	GrantType string         `gorm:"type:enum('MONTHLY');not null"` // Only MONTHLY is used now
+	GrantType string         `gorm:"type:enum('MONTHLY','DEDUCTION');not null"` // MONTHLY grant, or DEDUCTION of that month's paid leave
 	CreatedAt time.Time      `gorm:"not null"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
@@ -23,7 +23,6 @@ func (LeaveGrant) TableName() string {
 
 const (
 	GrantTypeMonthly        = "MONTHLY"
-	GrantTypeBirthday       = "BIRTHDAY"
-	GrantTypeBirthdayDeduction = "BIRTHDAY_DEDUCTION"
+	GrantTypeDeduction      = "DEDUCTION"
 )
 
@@ -0,0 +1,122 @@
+package leave
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"time-attendance-be/internal/modules/user"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// FinalSettlement is the leave settlement of an offboarded user
+type FinalSettlement struct {
+	UserID  uint
+	Year    int
+	Month   int
+	Summary *MonthlySummary // final month, counted up to the termination date
+
+	PaidLeaveBefore   float64 // balance before the final month's usage was deducted
+	AccrualAdjustment float64 // grants for days after the termination date, taken back (<= 0)
+	// Days is what is left after the final month: > 0 is paid out, < 0 deducted from the final salary
+	Days float64
+
+	compOff []CompOffUsage // comp-off used by the final month, written by ApplyTermination
+}
+
+// IsMonthSettled reports whether the month-end deduction of a month has already run. A user
+// can't be offboarded into such a month: their paid leave for it was already deducted as if
+// they had worked the whole month.
+func (s *Service) IsMonthSettled(ctx context.Context, year, month int) (bool, error) {
+	return s.repo.HasGrant(ctx, year, month, GrantTypeDeduction)
+}
+
+// PlanTermination works out the leave settlement of a user leaving on u.TerminationDate,
+// without writing anything (u may not be saved yet):
+// - the final month's summary up to the termination date and the comp-off it uses
+// - accrual granted for the rest of the final month (pro-rating) and later months, taken back
+// - what remains of paid leave to pay out (or the negative balance to deduct)
+// The caller applies it with ApplyTermination in the transaction that zeroes the balance.
+func (s *Service) PlanTermination(ctx context.Context, u *user.User) (*FinalSettlement, error) {
+	if u.TerminationDate == nil {
+		return nil, fmt.Errorf("user %d has no termination date", u.ID)
+	}
+	year, month := u.TerminationDate.Year(), int(u.TerminationDate.Month())
+
+	in, err := s.userSummaryInputs(ctx, u, year, month)
+	if err != nil {
+		return nil, fmt.Errorf("final month summary: %w", err)
+	}
+	summary, err := buildMonthlySummary(in)
+	if err != nil {
+		return nil, fmt.Errorf("final month summary: %w", err)
+	}
+
+	// Grants already given assumed the user stays; recompute them with the termination date
+	policies, err := s.repo.ListAccrualPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("accrual policies: %w", err)
+	}
+	p := resolveAccrualPolicy(policies, u)
+	staying := *u
+	staying.TerminationDate = nil
+	adjustment := 0.0
+	now := time.Now().In(s.cfg.TimeLocation())
+	for ym := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC); !ym.After(now); ym = ym.AddDate(0, 1, 0) {
+		y, m := ym.Year(), int(ym.Month())
+		granted, err := s.repo.HasMonthlyGrant(ctx, y, m)
+		if err != nil {
+			return nil, fmt.Errorf("check grant %d-%02d: %w", y, m, err)
+		}
+		if granted {
+			adjustment += computeAccrual(p, u, y, m).Days - computeAccrual(p, &staying, y, m).Days
+		}
+	}
+
+	res := &FinalSettlement{
+		UserID:            u.ID,
+		Year:              year,
+		Month:             month,
+		Summary:           summary,
+		PaidLeaveBefore:   u.PaidLeave,
+		AccrualAdjustment: adjustment,
+		Days:              math.Round((u.PaidLeave-summary.PaidUsedUnits+adjustment)*100) / 100,
+	}
+
+	if summary.CompOffUsedUnits > 0 {
+		done, err := s.repo.HasCompOffUsage(ctx, u.ID, year, month)
+		if err != nil {
+			return nil, fmt.Errorf("comp-off usage: %w", err)
+		}
+		if !done {
+			monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.cfg.TimeLocation())
+			if res.compOff, err = s.planCompOffUsage(ctx, u.ID, summary.CompOffUsedUnits, monthStart, monthStart.AddDate(0, 1, -1)); err != nil {
+				return nil, fmt.Errorf("plan comp-off: %w", err)
+			}
+		}
+	}
+	return res, nil
+}
+
+// ApplyTermination writes the leave side of a settlement (the comp-off used by the final
+// month) with tx, the caller's transaction
+func (s *Service) ApplyTermination(ctx context.Context, tx *gorm.DB, final *FinalSettlement) error {
+	return NewRepo(tx).ConsumeCompOffCredits(ctx, final.compOff)
+}
+
+// RecordTermination stores the final month's summary once the settlement is committed
+func (s *Service) RecordTermination(ctx context.Context, final *FinalSettlement) error {
+	_, err := s.persistSummaries(ctx, final.Year, final.Month, []MonthlySummary{*final.Summary}, SnapshotTriggerOffboarding)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("settled leave of terminated user",
+		zap.Uint("userID", final.UserID),
+		zap.Int("year", final.Year),
+		zap.Int("month", final.Month),
+		zap.Float64("days", final.Days))
+	return nil
+}
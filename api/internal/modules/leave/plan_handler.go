@@ -0,0 +1,126 @@
+package leave
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /api/v1/admin/leave/plan?year=&month=&format=json|csv
+// Dry-run of the 1st-of-month run: grant for year/month and deduction for the month before.
+// Defaults to next month (the upcoming 1st).
+func (h *Handler) AdminPreviewPlan(c *fiber.Ctx) error {
+	next := time.Now().In(h.svc.cfg.TimeLocation()).AddDate(0, 1, 0)
+	year := next.Year()
+	month := int(next.Month())
+	if y := c.Query("year"); y != "" {
+		if yInt, err := strconv.Atoi(y); err == nil {
+			year = yInt
+		}
+	}
+	if m := c.Query("month"); m != "" {
+		if mInt, err := strconv.Atoi(m); err == nil && mInt >= 1 && mInt <= 12 {
+			month = mInt
+		}
+	}
+
+	plan, err := h.svc.PreviewLeavePlan(c.Context(), year, month)
+	if err != nil {
+		return response.Internal(err)
+	}
+	res := toLeavePlanResponse(plan)
+
+	switch c.Query("format", "json") {
+	case "json":
+		return response.OK(c, res)
+	case "csv":
+	default:
+		return response.Validation("format must be json or csv", nil)
+	}
+
+	c.Set("Content-Type", "text/csv; charset=utf-8")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=leave_plan_%d%02d_%s.csv", year, month, res.Checksum))
+
+	writer := csv.NewWriter(c.Response().BodyWriter())
+	defer writer.Flush()
+
+	header := []string{"User ID", "Nhân viên", "Email", "Chính sách", "Số dư hiện tại", "Cộng phép", "Trừ phép", "Nghỉ bù", "Không lương", "Số dư sau", "Cảnh báo"}
+	if err := writer.Write(header); err != nil {
+		return response.Internal(err)
+	}
+	for _, row := range res.Rows {
+		record := []string{
+			strconv.FormatUint(uint64(row.UserID), 10),
+			row.Name,
+			row.Email,
+			row.Policy,
+			fmt.Sprintf("%.1f", row.CurrentBalance),
+			fmt.Sprintf("%.1f", row.GrantDays),
+			fmt.Sprintf("%.1f", row.DeductionDays),
+			fmt.Sprintf("%.1f", row.CompOffDays),
+			fmt.Sprintf("%.1f", row.UnpaidUnits),
+			fmt.Sprintf("%.1f", row.ResultingBalance),
+			strings.Join(row.Warnings, ";"),
+		}
+		if err := writer.Write(record); err != nil {
+			return response.Internal(err)
+		}
+	}
+
+	return nil
+}
+
+// POST /api/v1/admin/leave/plan/apply
+// Body: { "year": 2026, "month": 11, "checksum": "<from preview>", "reason": "..." }
+func (h *Handler) AdminApplyPlan(c *fiber.Ctx) error {
+	type ApplyRequest struct {
+		Year     int    `json:"year"`
+		Month    int    `json:"month"`
+		Checksum string `json:"checksum"`
+		Reason   string `json:"reason"`
+	}
+
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	var req ApplyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+	if req.Month < 1 || req.Month > 12 || req.Year == 0 {
+		return response.Validation("year and month are required", nil)
+	}
+	if req.Checksum == "" {
+		return response.Validation("checksum from the preview is required", nil)
+	}
+
+	plan, err := h.svc.ApplyLeavePlan(c.Context(), req.Year, req.Month, req.Checksum)
+	if err != nil {
+		return err
+	}
+	res := toLeavePlanResponse(plan)
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"APPLY_LEAVE_PLAN",
+			"leave_plan",
+			fmt.Sprintf("%d-%02d", req.Year, req.Month),
+			nil,
+			res,
+			req.Reason,
+		)
+	}
+
+	return response.OK(c, res)
+}
@@ -0,0 +1,131 @@
+package leave
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ApplyMonthlyGrant records the monthly grant and credits paid_leave (amount -> user IDs) in one
+// transaction, so a failure leaves nothing behind and the grant can be retried. Returns false,
+// without changing anything, when the month was already granted.
+func (r *Repo) ApplyMonthlyGrant(ctx context.Context, year, month int, grants map[float64][]uint) (bool, error) {
+	applied := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "grant_year"}, {Name: "grant_month"}, {Name: "grant_type"}},
+			DoNothing: true,
+		}).Create(&LeaveGrant{GrantYear: year, GrantMonth: month, GrantType: GrantTypeMonthly})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		for amount, userIDs := range grants {
+			if err := tx.Table("users").Where("id IN ?", userIDs).
+				Update("paid_leave", gorm.Expr("paid_leave + ?", amount)).Error; err != nil {
+				return err
+			}
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+// ApplyMonthlyDeduction records the month-end deduction of a month, decrements paid_leave
+// (amount -> user IDs) and consumes comp-off in one transaction. Returns false without
+// changing anything if the deduction was already recorded.
+func (r *Repo) ApplyMonthlyDeduction(ctx context.Context, year, month int, deductions map[float64][]uint, compOff []CompOffUsage) (bool, error) {
+	applied := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "grant_year"}, {Name: "grant_month"}, {Name: "grant_type"}},
+			DoNothing: true,
+		}).Create(&LeaveGrant{GrantYear: year, GrantMonth: month, GrantType: GrantTypeDeduction})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		for amount, userIDs := range deductions {
+			// Same clamp as user.Repo.BatchDecrementPaidLeave
+			if err := tx.Table("users").Where("id IN ?", userIDs).
+				Update("paid_leave", gorm.Expr("GREATEST(paid_leave - ?, 0)", amount)).Error; err != nil {
+				return err
+			}
+		}
+		if err := consumeCompOffCredits(tx, compOff); err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+// ApplyLeavePlan writes a previewed plan in one transaction:
+// grant/deduction records, paid_leave increments and decrements, and comp-off consumption.
+// Fails (and rolls back) if another process recorded the same grant/deduction in the meantime.
+func (r *Repo) ApplyLeavePlan(ctx context.Context, plan *LeavePlan, compOff []CompOffUsage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		createRecord := func(year, month int, grantType string) error {
+			res := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "grant_year"}, {Name: "grant_month"}, {Name: "grant_type"}},
+				DoNothing: true,
+			}).Create(&LeaveGrant{GrantYear: year, GrantMonth: month, GrantType: grantType})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("%s %d-%02d already processed", grantType, year, month)
+			}
+			return nil
+		}
+
+		grants := make(map[float64][]uint)
+		deductions := make(map[float64][]uint)
+		for _, row := range plan.Rows {
+			if plan.GrantPending && row.GrantDays > 0 {
+				grants[row.GrantDays] = append(grants[row.GrantDays], row.UserID)
+			}
+			if plan.DeductionPending && row.DeductionDays > 0 {
+				deductions[row.DeductionDays] = append(deductions[row.DeductionDays], row.UserID)
+			}
+		}
+
+		if plan.GrantPending {
+			if err := createRecord(plan.Year, plan.Month, GrantTypeMonthly); err != nil {
+				return err
+			}
+			for amount, userIDs := range grants {
+				if err := tx.Table("users").Where("id IN ?", userIDs).
+					Update("paid_leave", gorm.Expr("paid_leave + ?", amount)).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if plan.DeductionPending {
+			if err := createRecord(plan.DeductionYear, plan.DeductionMonth, GrantTypeDeduction); err != nil {
+				return err
+			}
+			for amount, userIDs := range deductions {
+				// Same clamp as user.Repo.BatchDecrementPaidLeave
+				if err := tx.Table("users").Where("id IN ?", userIDs).
+					Update("paid_leave", gorm.Expr("GREATEST(paid_leave - ?, 0)", amount)).Error; err != nil {
+					return err
+				}
+			}
+			if err := consumeCompOffCredits(tx, compOff); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
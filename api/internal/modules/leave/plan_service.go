@@ -0,0 +1,210 @@
+package leave
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"time-attendance-be/internal/pkg/response"
+
+	"go.uber.org/zap"
+)
+
+// Warnings attached to a leave plan row
+const (
+	PlanWarningNegativeBalance = "NEGATIVE_BALANCE" // balance after the run would drop below 0 (clamped to 0 on apply)
+	PlanWarningUnpaidUnits     = "UNPAID_UNITS"     // deduction month has absences not covered by any balance
+	PlanWarningNoGrant         = "NO_GRANT"         // grant is pending but the user accrues nothing this month
+)
+
+// LeavePlanRow is the planned effect of the 1st-of-month run on one user
+type LeavePlanRow struct {
+	UserID           uint
+	Name             string
+	Email            string
+	DepartmentID     *uint
+	CurrentBalance   float64
+	Policy           string
+	GrantDays        float64 // accrual for the plan month
+	DeductionDays    float64 // paid_used_units of the previous month
+	CompOffDays      float64 // comp_off_used_units of the previous month
+	UnpaidUnits      float64
+	ResultingBalance float64
+	Warnings         []string
+}
+
+// LeavePlan previews what ProcessLeaveGrantForMonth and ProcessPreviousMonthLeaveDeduction
+// would do for a month: grant for (Year, Month), deduction for the month before it.
+type LeavePlan struct {
+	Year             int
+	Month            int
+	DeductionYear    int
+	DeductionMonth   int
+	GrantPending     bool // false if the grant for Year/Month was already processed
+	DeductionPending bool // false if the deduction for DeductionYear/DeductionMonth was already processed
+	Rows             []LeavePlanRow
+	summaries        []*MonthlySummary // projected deduction-month summaries (persisted on apply)
+}
+
+// Checksum identifies the plan contents; apply requires the checksum of the previewed plan
+// so admins confirm exactly what they reviewed.
+func (p *LeavePlan) Checksum() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d-%d|%t|%t\n", p.Year, p.Month, p.GrantPending, p.DeductionPending)
+	for _, r := range p.Rows {
+		fmt.Fprintf(h, "%d|%.2f|%.2f|%.2f|%.2f\n", r.UserID, r.CurrentBalance, r.GrantDays, r.DeductionDays, r.CompOffDays)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// PreviewLeavePlan computes the grant/deduction plan for a month without writing anything
+func (s *Service) PreviewLeavePlan(ctx context.Context, year, month int) (*LeavePlan, error) {
+	if s.workCalRepo == nil || s.attendanceRepo == nil {
+		return nil, fmt.Errorf("work calendar or attendance repo not set")
+	}
+
+	prev := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.cfg.TimeLocation()).AddDate(0, -1, 0)
+	plan := &LeavePlan{
+		Year:           year,
+		Month:          month,
+		DeductionYear:  prev.Year(),
+		DeductionMonth: int(prev.Month()),
+	}
+
+	hasGrant, err := s.repo.HasMonthlyGrant(ctx, year, month)
+	if err != nil {
+		return nil, err
+	}
+	hasDeduction, err := s.repo.HasGrant(ctx, plan.DeductionYear, plan.DeductionMonth, GrantTypeDeduction)
+	if err != nil {
+		return nil, err
+	}
+	plan.GrantPending = !hasGrant
+	plan.DeductionPending = !hasDeduction
+
+	users, err := s.userRepo.GetAllActiveUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	accruals, err := s.ComputeAccruals(ctx, users, year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []MonthlySummary
+	if plan.DeductionPending {
+		summaries, err = s.projectMonthlySummaries(ctx, users, plan.DeductionYear, plan.DeductionMonth)
+		if err != nil {
+			return nil, fmt.Errorf("project summaries: %w", err)
+		}
+	}
+
+	plan.Rows = make([]LeavePlanRow, 0, len(users))
+	for i := range users {
+		u := &users[i]
+		row := LeavePlanRow{
+			UserID:         u.ID,
+			Name:           u.Name,
+			Email:          u.Email,
+			DepartmentID:   u.DepartmentID,
+			CurrentBalance: u.PaidLeave,
+			Policy:         accruals[i].Policy,
+		}
+
+		if plan.GrantPending {
+			row.GrantDays = accruals[i].Days
+			if row.GrantDays <= 0 {
+				row.Warnings = append(row.Warnings, PlanWarningNoGrant)
+			}
+		}
+
+		if plan.DeductionPending {
+			summary := &summaries[i]
+			plan.summaries = append(plan.summaries, summary)
+			row.DeductionDays = summary.PaidUsedUnits
+			row.CompOffDays = summary.CompOffUsedUnits
+			row.UnpaidUnits = summary.UnpaidUnits
+			if row.UnpaidUnits > 0 {
+				row.Warnings = append(row.Warnings, PlanWarningUnpaidUnits)
+			}
+		}
+
+		row.ResultingBalance = row.CurrentBalance + row.GrantDays - row.DeductionDays
+		if row.ResultingBalance < 0 {
+			row.Warnings = append(row.Warnings, PlanWarningNegativeBalance)
+		}
+		plan.Rows = append(plan.Rows, row)
+	}
+
+	return plan, nil
+}
+
+// ApplyLeavePlan recomputes the plan for a month and applies it as one batch.
+// The checksum must match the previewed plan; otherwise balances or attendance changed since the preview.
+func (s *Service) ApplyLeavePlan(ctx context.Context, year, month int, checksum string) (*LeavePlan, error) {
+	loc := s.cfg.TimeLocation()
+	if time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc).After(time.Now().In(loc)) {
+		return nil, response.Validation("Cannot apply a leave plan before the 1st of its month", nil)
+	}
+
+	plan, err := s.PreviewLeavePlan(ctx, year, month)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if !plan.GrantPending && !plan.DeductionPending {
+		return nil, response.Conflict("Leave grant and deduction for this month have already been processed")
+	}
+	if plan.Checksum() != checksum {
+		return nil, response.Conflict("Leave plan has changed since preview, please review it again")
+	}
+
+	var compOff []CompOffUsage
+	if plan.DeductionPending {
+		monthStart := time.Date(plan.DeductionYear, time.Month(plan.DeductionMonth), 1, 0, 0, 0, 0, loc)
+		for _, r := range plan.Rows {
+			if r.CompOffDays <= 0 {
+				continue
+			}
+			usage, err := s.planCompOffUsage(ctx, r.UserID, r.CompOffDays, monthStart, monthStart.AddDate(0, 1, -1))
+			if err != nil {
+				return nil, response.Internal(err)
+			}
+			compOff = append(compOff, usage...)
+		}
+	}
+
+	// Persist the deduction-month summaries the plan is based on. This happens before the
+	// deduction is recorded, so their snapshots are not reported as changes to a closed month.
+	// A failed upsert stops here, so the applied plan never disagrees with the stored summaries.
+	persisted := make([]MonthlySummary, 0, len(plan.summaries))
+	for _, summary := range plan.summaries {
+		if err := s.repo.UpsertMonthlySummary(ctx, summary); err != nil {
+			return nil, response.Internal(fmt.Errorf("upsert summary of user %d: %w", summary.UserID, err))
+		}
+		persisted = append(persisted, *summary)
+	}
+	s.recordSummarySnapshots(ctx, plan.DeductionYear, plan.DeductionMonth, persisted, SnapshotTriggerManual)
+
+	if err := s.repo.ApplyLeavePlan(ctx, plan, compOff); err != nil {
+		return nil, response.Internal(err)
+	}
+
+	userIDs := make([]uint, len(plan.Rows))
+	for i, r := range plan.Rows {
+		userIDs[i] = r.UserID
+	}
+	s.enqueueBalanceRecompute(ctx, userIDs)
+
+	s.logger.Info("applied leave plan",
+		zap.Int("year", year),
+		zap.Int("month", month),
+		zap.Bool("grant", plan.GrantPending),
+		zap.Bool("deduction", plan.DeductionPending),
+		zap.Int("users", len(plan.Rows)))
+	return plan, nil
+}
@@ -0,0 +1,74 @@
+package leave
+
+import (
+	"strconv"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /api/v1/admin/leave/policies
+func (h *Handler) AdminListPolicies(c *fiber.Ctx) error {
+	policies, err := h.svc.ListAccrualPolicies(c.Context())
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	results := make([]AccrualPolicyResponse, len(policies))
+	for i := range policies {
+		results[i] = toAccrualPolicyResponse(&policies[i])
+	}
+	return response.OK(c, results)
+}
+
+// POST /api/v1/admin/leave/policies
+func (h *Handler) AdminCreatePolicy(c *fiber.Ctx) error {
+	if authx.GetUser(c) == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	var req AccrualPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	p, err := h.svc.CreateAccrualPolicy(c.Context(), req)
+	if err != nil {
+		return err
+	}
+	return response.Created(c, toAccrualPolicyResponse(p))
+}
+
+// PUT /api/v1/admin/leave/policies/:id
+func (h *Handler) AdminUpdatePolicy(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	var req AccrualPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	p, err := h.svc.UpdateAccrualPolicy(c.Context(), uint(id), req)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toAccrualPolicyResponse(p))
+}
+
+// DELETE /api/v1/admin/leave/policies/:id
+func (h *Handler) AdminDeletePolicy(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	if err := h.svc.DeleteAccrualPolicy(c.Context(), uint(id)); err != nil {
+		return err
+	}
+	return response.OK(c, true)
+}
@@ -0,0 +1,46 @@
+package leave
+
+import "time"
+
+// AccrualPolicy defines how many paid leave days an employee group earns each month.
+// Resolution order for a user: user.AccrualPolicyID -> policy of user's department -> default policy.
+// If no policy is configured at all, the built-in fallback (1.0 day/month) is used.
+type AccrualPolicy struct {
+	ID                uint          `gorm:"primaryKey"`
+	Name              string        `gorm:"size:120;uniqueIndex;not null"`
+	DepartmentID      *uint         `gorm:"index"`                                  // nil = not bound to a department
+	MonthlyDays       float64       `gorm:"type:decimal(4,1);not null;default:1.0"` // Số ngày phép cộng mỗi tháng
+	ProRateFirstMonth bool          `gorm:"not null;default:true"`                  // Pro-rate tháng vào làm theo hire_date
+	ProRateLastMonth  bool          `gorm:"not null;default:true"`                  // Pro-rate tháng nghỉ việc theo termination_date
+	IsDefault         bool          `gorm:"not null;default:false"`
+	Tiers             []AccrualTier `gorm:"foreignKey:PolicyID"`
+	CreatedAt         time.Time     `gorm:"not null"`
+	UpdatedAt         time.Time     `gorm:"not null"`
+}
+
+func (AccrualPolicy) TableName() string {
+	return "leave_accrual_policies"
+}
+
+// AccrualTier is a seniority band of a policy.
+// BonusDays are granted once a year, in the hire anniversary month,
+// using the highest tier whose MinYears <= completed years of service.
+type AccrualTier struct {
+	ID        uint    `gorm:"primaryKey"`
+	PolicyID  uint    `gorm:"not null;index"`
+	MinYears  int     `gorm:"not null"`
+	BonusDays float64 `gorm:"type:decimal(4,1);not null;default:0.0"`
+}
+
+func (AccrualTier) TableName() string {
+	return "leave_accrual_tiers"
+}
+
+// fallbackAccrualPolicy keeps the historical behaviour (1 day/month for everyone)
+// when no policy has been configured.
+var fallbackAccrualPolicy = AccrualPolicy{
+	Name:              "fallback",
+	MonthlyDays:       1.0,
+	ProRateFirstMonth: true,
+	ProRateLastMonth:  true,
+}
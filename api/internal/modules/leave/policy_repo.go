@@ -0,0 +1,78 @@
+package leave
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Accrual policy methods
+
+// ListAccrualPolicies returns all accrual policies with their tiers
+func (r *Repo) ListAccrualPolicies(ctx context.Context) ([]AccrualPolicy, error) {
+	var policies []AccrualPolicy
+	err := r.db.WithContext(ctx).
+		Preload("Tiers", func(db *gorm.DB) *gorm.DB { return db.Order("min_years ASC") }).
+		Order("id ASC").
+		Find(&policies).Error
+	return policies, err
+}
+
+// GetAccrualPolicy returns a policy by ID with its tiers
+func (r *Repo) GetAccrualPolicy(ctx context.Context, id uint) (*AccrualPolicy, error) {
+	var p AccrualPolicy
+	err := r.db.WithContext(ctx).
+		Preload("Tiers", func(db *gorm.DB) *gorm.DB { return db.Order("min_years ASC") }).
+		First(&p, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SaveAccrualPolicy creates or updates a policy and replaces its tiers.
+// If the policy is marked default, the flag is cleared on every other policy.
+func (r *Repo) SaveAccrualPolicy(ctx context.Context, p *AccrualPolicy) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tiers := p.Tiers
+		p.Tiers = nil
+		if err := tx.Save(p).Error; err != nil {
+			return err
+		}
+		if p.IsDefault {
+			if err := tx.Model(&AccrualPolicy{}).
+				Where("id <> ?", p.ID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("policy_id = ?", p.ID).Delete(&AccrualTier{}).Error; err != nil {
+			return err
+		}
+		for i := range tiers {
+			tiers[i].ID = 0
+			tiers[i].PolicyID = p.ID
+		}
+		if len(tiers) > 0 {
+			if err := tx.Create(&tiers).Error; err != nil {
+				return err
+			}
+		}
+		p.Tiers = tiers
+		return nil
+	})
+}
+
+// DeleteAccrualPolicy deletes a policy, its tiers, and unassigns it from users
+func (r *Repo) DeleteAccrualPolicy(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("users").Where("accrual_policy_id = ?", id).
+			Update("accrual_policy_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("policy_id = ?", id).Delete(&AccrualTier{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&AccrualPolicy{}, id).Error
+	})
+}
@@ -0,0 +1,215 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/pkg/response"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AccrualResult is the computed monthly grant for one user
+type AccrualResult struct {
+	UserID    uint
+	PolicyID  uint // 0 = built-in fallback policy
+	Policy    string
+	BaseDays  float64 // MonthlyDays after pro-rating
+	BonusDays float64 // Seniority bonus (anniversary month only)
+	Factor    float64 // Pro-rate factor applied to MonthlyDays (1 = full month)
+	Days      float64 // BaseDays + BonusDays
+}
+
+// resolveAccrualPolicy picks the policy that applies to a user:
+// explicit assignment -> department policy -> default policy -> fallback
+func resolveAccrualPolicy(policies []AccrualPolicy, u *user.User) *AccrualPolicy {
+	if u.AccrualPolicyID != nil {
+		for i := range policies {
+			if policies[i].ID == *u.AccrualPolicyID {
+				return &policies[i]
+			}
+		}
+	}
+	if u.DepartmentID != nil {
+		for i := range policies {
+			if policies[i].DepartmentID != nil && *policies[i].DepartmentID == *u.DepartmentID {
+				return &policies[i]
+			}
+		}
+	}
+	for i := range policies {
+		if policies[i].IsDefault {
+			return &policies[i]
+		}
+	}
+	return &fallbackAccrualPolicy
+}
+
+// computeAccrual computes the grant for a user/month under a policy.
+// - Users hired after the month or terminated before it get nothing
+// - First/last month are pro-rated by calendar days when the policy says so
+// - Seniority bonus is added once a year, in the hire anniversary month
+// Amounts are rounded to half days to match the half-day attendance model.
+func computeAccrual(p *AccrualPolicy, u *user.User, year, month int) AccrualResult {
+	res := AccrualResult{UserID: u.ID, PolicyID: p.ID, Policy: p.Name}
+
+	daysInMonth := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	monthKey := year*100 + month
+	activeFrom := 1
+	activeTo := daysInMonth
+
+	if u.HireDate != nil {
+		hy, hm, hd := u.HireDate.Date()
+		hireKey := hy*100 + int(hm)
+		if hireKey > monthKey {
+			return res
+		}
+		if hireKey == monthKey && p.ProRateFirstMonth {
+			activeFrom = hd
+		}
+	}
+	if u.TerminationDate != nil {
+		ty, tm, td := u.TerminationDate.Date()
+		termKey := ty*100 + int(tm)
+		if termKey < monthKey {
+			return res
+		}
+		if termKey == monthKey && p.ProRateLastMonth {
+			activeTo = td
+		}
+	}
+	if activeTo < activeFrom {
+		return res
+	}
+
+	res.Factor = float64(activeTo-activeFrom+1) / float64(daysInMonth)
+	res.BaseDays = roundHalfDay(p.MonthlyDays * res.Factor)
+
+	if u.HireDate != nil && int(u.HireDate.Month()) == month && year > u.HireDate.Year() {
+		years := year - u.HireDate.Year()
+		best := -1
+		for _, t := range p.Tiers {
+			if t.MinYears <= years && t.MinYears > best {
+				best = t.MinYears
+				res.BonusDays = t.BonusDays
+			}
+		}
+	}
+
+	res.Days = res.BaseDays + res.BonusDays
+	return res
+}
+
+func roundHalfDay(v float64) float64 {
+	return math.Round(v*2) / 2
+}
+
+// ComputeAccruals returns the per-user grant for a month without writing anything
+func (s *Service) ComputeAccruals(ctx context.Context, users []user.User, year, month int) ([]AccrualResult, error) {
+	policies, err := s.repo.ListAccrualPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]AccrualResult, 0, len(users))
+	for i := range users {
+		p := resolveAccrualPolicy(policies, &users[i])
+		results = append(results, computeAccrual(p, &users[i], year, month))
+	}
+	return results, nil
+}
+
+// grantAccruals records the monthly grant and applies the accrual to users, all in one
+// transaction (one batch update per amount). Returns false when the month was already granted.
+func (s *Service) grantAccruals(ctx context.Context, users []user.User, year, month int) (bool, error) {
+	results, err := s.ComputeAccruals(ctx, users, year, month)
+	if err != nil {
+		s.logger.Error("failed to compute leave accruals", zap.Error(err))
+		return false, err
+	}
+
+	groups := make(map[float64][]uint)
+	for _, r := range results {
+		if r.Days <= 0 {
+			continue
+		}
+		groups[r.Days] = append(groups[r.Days], r.UserID)
+	}
+
+	applied, err := s.repo.ApplyMonthlyGrant(ctx, year, month, groups)
+	if err != nil {
+		s.logger.Error("failed to apply monthly leave grant",
+			zap.Int("year", year),
+			zap.Int("month", month),
+			zap.Error(err))
+		return false, err
+	}
+	if !applied {
+		return false, nil
+	}
+	for amount, userIDs := range groups {
+		s.logger.Info("granted monthly leave",
+			zap.Float64("amount", amount),
+			zap.Int("userCount", len(userIDs)),
+			zap.Int("year", year),
+			zap.Int("month", month))
+	}
+	return true, nil
+}
+
+// ListAccrualPolicies returns all accrual policies
+func (s *Service) ListAccrualPolicies(ctx context.Context) ([]AccrualPolicy, error) {
+	return s.repo.ListAccrualPolicies(ctx)
+}
+
+// CreateAccrualPolicy creates a new accrual policy
+func (s *Service) CreateAccrualPolicy(ctx context.Context, req AccrualPolicyRequest) (*AccrualPolicy, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	p := &AccrualPolicy{CreatedAt: now}
+	req.applyTo(p)
+	p.UpdatedAt = now
+	if err := s.repo.SaveAccrualPolicy(ctx, p); err != nil {
+		return nil, response.Internal(err)
+	}
+	return p, nil
+}
+
+// UpdateAccrualPolicy replaces an accrual policy's settings and tiers
+func (s *Service) UpdateAccrualPolicy(ctx context.Context, id uint, req AccrualPolicyRequest) (*AccrualPolicy, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	p, err := s.repo.GetAccrualPolicy(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFound("Policy not found")
+		}
+		return nil, response.Internal(err)
+	}
+	req.applyTo(p)
+	p.UpdatedAt = time.Now()
+	if err := s.repo.SaveAccrualPolicy(ctx, p); err != nil {
+		return nil, response.Internal(err)
+	}
+	return p, nil
+}
+
+// DeleteAccrualPolicy deletes an accrual policy
+func (s *Service) DeleteAccrualPolicy(ctx context.Context, id uint) error {
+	if _, err := s.repo.GetAccrualPolicy(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound("Policy not found")
+		}
+		return response.Internal(err)
+	}
+	if err := s.repo.DeleteAccrualPolicy(ctx, id); err != nil {
+		return response.Internal(err)
+	}
+	return nil
+}
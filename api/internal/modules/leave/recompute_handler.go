@@ -0,0 +1,124 @@
+package leave
+
+import (
+	"errors"
+	"strconv"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GET /api/v1/admin/leave/recompute?status=&userId=&batchId=&limit=
+// Queue overview: counts per status plus the most recently updated tasks
+func (h *Handler) AdminListRecompute(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch status {
+	case "", RecomputeStatusPending, RecomputeStatusRunning, RecomputeStatusDone, RecomputeStatusFailed:
+	default:
+		return response.Validation("status must be PENDING, RUNNING, DONE or FAILED", nil)
+	}
+
+	var userID, batchID *uint
+	if v := c.Query("userId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return response.Validation("invalid userId", nil)
+		}
+		uid := uint(id)
+		userID = &uid
+	}
+	if v := c.Query("batchId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return response.Validation("invalid batchId", nil)
+		}
+		bid := uint(id)
+		batchID = &bid
+	}
+	limit := 100
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+
+	progress, err := h.svc.GetRecomputeProgress(c.Context(), batchID)
+	if err != nil {
+		return response.Internal(err)
+	}
+	tasks, err := h.svc.ListRecomputeTasks(c.Context(), status, userID, batchID, limit)
+	if err != nil {
+		return response.Internal(err)
+	}
+	items := make([]RecomputeTaskResponse, len(tasks))
+	for i := range tasks {
+		items[i] = toRecomputeTaskResponse(&tasks[i])
+	}
+
+	return response.OK(c, map[string]interface{}{
+		"progress": progress,
+		"tasks":    items,
+	})
+}
+
+// GET /api/v1/admin/leave/recompute/batches/:id
+func (h *Handler) AdminGetRecomputeBatch(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	batch, err := h.svc.GetRecomputeBatch(c.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound("Batch not found")
+		}
+		return response.Internal(err)
+	}
+	return h.recomputeBatchResponse(c, batch)
+}
+
+// POST /api/v1/admin/leave/recompute/retry
+// Re-queues every FAILED task
+func (h *Handler) AdminRetryRecompute(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	n, err := h.svc.RetryFailedRecompute(c.Context())
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"RETRY_SUMMARY_RECOMPUTE",
+			"leave_recompute_task",
+			"",
+			nil,
+			map[string]interface{}{"requeued": n},
+			"",
+		)
+	}
+
+	return response.OK(c, map[string]interface{}{"requeued": n})
+}
+
+func (h *Handler) recomputeBatchResponse(c *fiber.Ctx, batch *RecomputeBatch) error {
+	progress, err := h.svc.GetRecomputeProgress(c.Context(), &batch.ID)
+	if err != nil {
+		return response.Internal(err)
+	}
+	return response.OK(c, RecomputeBatchResponse{
+		ID:          batch.ID,
+		Reason:      batch.Reason,
+		RequestedBy: batch.RequestedBy,
+		Total:       batch.Total,
+		CreatedAt:   batch.CreatedAt,
+		Progress:    progress,
+	})
+}
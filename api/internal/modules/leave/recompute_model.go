@@ -0,0 +1,74 @@
+package leave
+
+import "time"
+
+// RecomputeTask is a queued recomputation of one leave_monthly_summary row.
+// There is at most one task per (user, month): enqueueing again resets it to PENDING and bumps
+// Version, so a change made while the task is RUNNING makes the worker run it once more.
+type RecomputeTask struct {
+	ID            uint      `gorm:"primaryKey"`
+	UserID        uint      `gorm:"not null;uniqueIndex:idx_recompute_user_month"`
+	Year          int       `gorm:"not null;uniqueIndex:idx_recompute_user_month"`
+	Month         int       `gorm:"not null;uniqueIndex:idx_recompute_user_month"`
+	Status        string    `gorm:"type:enum('PENDING','RUNNING','DONE','FAILED');not null;default:'PENDING';index:idx_recompute_status_next"`
+	Reason        string    `gorm:"type:varchar(64);not null"`
+	BatchID       *uint     `gorm:"index"` // latest batch that enqueued the task
+	Version       int       `gorm:"not null;default:1"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;index:idx_recompute_status_next"`
+	ClaimedBy     *string   `gorm:"type:varchar(128)"`
+	StartedAt     *time.Time
+	FinishedAt    *time.Time
+	Error         *string   `gorm:"type:text"`
+	CreatedAt     time.Time `gorm:"not null"`
+	UpdatedAt     time.Time `gorm:"not null"`
+}
+
+func (RecomputeTask) TableName() string {
+	return "leave_recompute_tasks"
+}
+
+// RecomputeBatch groups the tasks enqueued by one change (a calendar edit, an admin request, ...)
+// so its progress can be followed. A task re-enqueued by a later batch moves to that batch.
+type RecomputeBatch struct {
+	ID          uint      `gorm:"primaryKey"`
+	Reason      string    `gorm:"type:varchar(64);not null"`
+	RequestedBy *uint     // admin user, nil for automatic triggers
+	Total       int       `gorm:"not null;default:0"`
+	CreatedAt   time.Time `gorm:"not null"`
+}
+
+func (RecomputeBatch) TableName() string {
+	return "leave_recompute_batches"
+}
+
+const (
+	RecomputeStatusPending = "PENDING"
+	RecomputeStatusRunning = "RUNNING"
+	RecomputeStatusDone    = "DONE"
+	RecomputeStatusFailed  = "FAILED"
+)
+
+// Recompute reasons
+const (
+	RecomputeReasonAttendance = "ATTENDANCE_CHANGED"
+	RecomputeReasonCalendar   = "CALENDAR_CHANGED"
+	RecomputeReasonBalance    = "BALANCE_CHANGED"
+	RecomputeReasonManual     = "MANUAL"
+	RecomputeReasonRetry      = "RETRY"
+)
+
+// RecomputeKey identifies one summary row
+type RecomputeKey struct {
+	UserID uint
+	Year   int
+	Month  int
+}
+
+// RecomputeProgress counts tasks per status
+type RecomputeProgress struct {
+	Pending int64 `json:"pending"`
+	Running int64 `json:"running"`
+	Done    int64 `json:"done"`
+	Failed  int64 `json:"failed"`
+}
@@ -0,0 +1,220 @@
+package leave
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EnqueueRecompute records a batch and upserts one task per key. Existing tasks for the same
+// (user, month) are reset to PENDING instead of duplicated.
+func (r *Repo) EnqueueRecompute(ctx context.Context, keys []RecomputeKey, reason string, requestedBy *uint) (*RecomputeBatch, error) {
+	now := time.Now()
+	batch := &RecomputeBatch{Reason: reason, RequestedBy: requestedBy, Total: len(keys), CreatedAt: now}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+
+		tasks := make([]RecomputeTask, len(keys))
+		for i, k := range keys {
+			tasks[i] = RecomputeTask{
+				UserID:        k.UserID,
+				Year:          k.Year,
+				Month:         k.Month,
+				Status:        RecomputeStatusPending,
+				Reason:        reason,
+				BatchID:       &batch.ID,
+				Version:       1,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "year"}, {Name: "month"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"status":          RecomputeStatusPending,
+				"reason":          reason,
+				"batch_id":        batch.ID,
+				"version":         gorm.Expr("version + 1"),
+				"attempts":        0,
+				"next_attempt_at": now,
+				"error":           nil,
+				"updated_at":      now,
+			}),
+		}).CreateInBatches(tasks, 500).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// ClaimRecomputeTasks marks up to limit due PENDING tasks as RUNNING for this instance.
+// A task is only claimed if nobody else claimed or re-enqueued it in between.
+func (r *Repo) ClaimRecomputeTasks(ctx context.Context, instance string, limit int) ([]RecomputeTask, error) {
+	now := time.Now()
+	var candidates []RecomputeTask
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", RecomputeStatusPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	claimed := make([]RecomputeTask, 0, len(candidates))
+	for _, t := range candidates {
+		res := r.db.WithContext(ctx).Model(&RecomputeTask{}).
+			Where("id = ? AND status = ? AND version = ?", t.ID, RecomputeStatusPending, t.Version).
+			Updates(map[string]interface{}{
+				"status":     RecomputeStatusRunning,
+				"claimed_by": instance,
+				"started_at": now,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"updated_at": now,
+			})
+		if res.Error != nil {
+			return claimed, res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		t.Status = RecomputeStatusRunning
+		t.ClaimedBy = &instance
+		t.StartedAt = &now
+		t.Attempts++
+		claimed = append(claimed, t)
+	}
+	return claimed, nil
+}
+
+// FinishRecomputeTask stores the outcome of a claimed task. If the task was re-enqueued while
+// running (version changed) the update matches nothing and the task stays PENDING.
+// A failed task goes back to PENDING at retryAt, or to FAILED when retryAt is nil.
+func (r *Repo) FinishRecomputeTask(ctx context.Context, task *RecomputeTask, runErr error, retryAt *time.Time) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"finished_at": now,
+		"updated_at":  now,
+	}
+	switch {
+	case runErr == nil:
+		updates["status"] = RecomputeStatusDone
+		updates["error"] = nil
+	case retryAt != nil:
+		updates["status"] = RecomputeStatusPending
+		updates["next_attempt_at"] = *retryAt
+		updates["error"] = runErr.Error()
+	default:
+		updates["status"] = RecomputeStatusFailed
+		updates["error"] = runErr.Error()
+	}
+
+	return r.db.WithContext(ctx).Model(&RecomputeTask{}).
+		Where("id = ? AND status = ? AND version = ?", task.ID, RecomputeStatusRunning, task.Version).
+		Updates(updates).Error
+}
+
+// ResetStaleRecomputeTasks puts RUNNING tasks whose worker disappeared back to PENDING.
+// Bumping the version makes a late finish of the old run match nothing.
+func (r *Repo) ResetStaleRecomputeTasks(ctx context.Context, startedBefore time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&RecomputeTask{}).
+		Where("status = ? AND started_at < ?", RecomputeStatusRunning, startedBefore).
+		Updates(map[string]interface{}{
+			"status":          RecomputeStatusPending,
+			"version":         gorm.Expr("version + 1"),
+			"next_attempt_at": time.Now(),
+			"updated_at":      time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
+
+// RetryFailedRecomputeTasks re-enqueues every FAILED task with a fresh attempt budget
+func (r *Repo) RetryFailedRecomputeTasks(ctx context.Context) (int64, error) {
+	now := time.Now()
+	res := r.db.WithContext(ctx).Model(&RecomputeTask{}).
+		Where("status = ?", RecomputeStatusFailed).
+		Updates(map[string]interface{}{
+			"status":          RecomputeStatusPending,
+			"reason":          RecomputeReasonRetry,
+			"version":         gorm.Expr("version + 1"),
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	return res.RowsAffected, res.Error
+}
+
+// CountRecomputeTasks counts tasks per status, optionally limited to one batch
+func (r *Repo) CountRecomputeTasks(ctx context.Context, batchID *uint) (*RecomputeProgress, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	q := r.db.WithContext(ctx).Model(&RecomputeTask{}).Select("status, COUNT(*) AS count")
+	if batchID != nil {
+		q = q.Where("batch_id = ?", *batchID)
+	}
+	if err := q.Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	p := &RecomputeProgress{}
+	for _, row := range rows {
+		switch row.Status {
+		case RecomputeStatusPending:
+			p.Pending = row.Count
+		case RecomputeStatusRunning:
+			p.Running = row.Count
+		case RecomputeStatusDone:
+			p.Done = row.Count
+		case RecomputeStatusFailed:
+			p.Failed = row.Count
+		}
+	}
+	return p, nil
+}
+
+// ListRecomputeTasks lists tasks, most recently updated first
+func (r *Repo) ListRecomputeTasks(ctx context.Context, status string, userID *uint, batchID *uint, limit int) ([]RecomputeTask, error) {
+	var tasks []RecomputeTask
+	q := r.db.WithContext(ctx).Model(&RecomputeTask{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if userID != nil {
+		q = q.Where("user_id = ?", *userID)
+	}
+	if batchID != nil {
+		q = q.Where("batch_id = ?", *batchID)
+	}
+	err := q.Order("updated_at DESC, id DESC").Limit(limit).Find(&tasks).Error
+	return tasks, err
+}
+
+// ListPendingRecomputeUsers returns users with a PENDING or RUNNING task for the month
+func (r *Repo) ListPendingRecomputeUsers(ctx context.Context, year, month int) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.WithContext(ctx).
+		Model(&RecomputeTask{}).
+		Where("year = ? AND month = ? AND status IN ?", year, month, []string{RecomputeStatusPending, RecomputeStatusRunning}).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// GetRecomputeBatch returns a batch by ID
+func (r *Repo) GetRecomputeBatch(ctx context.Context, id uint) (*RecomputeBatch, error) {
+	var b RecomputeBatch
+	if err := r.db.WithContext(ctx).First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
@@ -0,0 +1,181 @@
+package leave
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"time-attendance-be/internal/modules/user"
+
+	"go.uber.org/zap"
+)
+
+const (
+	recomputePollInterval = 5 * time.Second
+	recomputeClaimSize    = 20
+	recomputeMaxAttempts  = 5
+	recomputeStaleAfter   = 10 * time.Minute
+)
+
+// EnqueueRecompute queues summary recomputation for the given (user, month) keys and wakes the worker
+func (s *Service) EnqueueRecompute(ctx context.Context, keys []RecomputeKey, reason string, requestedBy *uint) (*RecomputeBatch, error) {
+	batch, err := s.repo.EnqueueRecompute(ctx, keys, reason, requestedBy)
+	if err != nil {
+		return nil, fmt.Errorf("enqueue recompute: %w", err)
+	}
+	select {
+	case s.recomputeWake <- struct{}{}:
+	default:
+	}
+	return batch, nil
+}
+
+// EnqueueRecomputeMonth queues recomputation for every user that has a summary for the month
+func (s *Service) EnqueueRecomputeMonth(ctx context.Context, year, month int, reason string, requestedBy *uint) (*RecomputeBatch, error) {
+	userIDs, err := s.repo.GetUserIDsWithSummaryInMonth(ctx, year, month)
+	if err != nil {
+		return nil, fmt.Errorf("list users with summary: %w", err)
+	}
+	keys := make([]RecomputeKey, len(userIDs))
+	for i, id := range userIDs {
+		keys[i] = RecomputeKey{UserID: id, Year: year, Month: month}
+	}
+	return s.EnqueueRecompute(ctx, keys, reason, requestedBy)
+}
+
+// enqueueBalanceRecompute queues the current month for users whose balance changed.
+// Failures are logged: the balance change itself already succeeded.
+func (s *Service) enqueueBalanceRecompute(ctx context.Context, userIDs []uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	now := time.Now().In(s.cfg.TimeLocation())
+	keys := make([]RecomputeKey, len(userIDs))
+	for i, id := range userIDs {
+		keys[i] = RecomputeKey{UserID: id, Year: now.Year(), Month: int(now.Month())}
+	}
+	if _, err := s.EnqueueRecompute(ctx, keys, RecomputeReasonBalance, nil); err != nil {
+		s.logger.Error("failed to enqueue summary recompute after balance change",
+			zap.Int("userCount", len(userIDs)),
+			zap.Error(err))
+	}
+}
+
+func userIDsOf(users []user.User) []uint {
+	ids := make([]uint, len(users))
+	for i := range users {
+		ids[i] = users[i].ID
+	}
+	return ids
+}
+
+// GetRecomputeProgress returns task counts, for one batch or the whole queue
+func (s *Service) GetRecomputeProgress(ctx context.Context, batchID *uint) (*RecomputeProgress, error) {
+	return s.repo.CountRecomputeTasks(ctx, batchID)
+}
+
+// GetRecomputeBatch returns a batch by ID
+func (s *Service) GetRecomputeBatch(ctx context.Context, id uint) (*RecomputeBatch, error) {
+	return s.repo.GetRecomputeBatch(ctx, id)
+}
+
+// PendingRecomputeUsers returns users whose summary for the month is queued or being recomputed
+func (s *Service) PendingRecomputeUsers(ctx context.Context, year, month int) (map[uint]bool, error) {
+	userIDs, err := s.repo.ListPendingRecomputeUsers(ctx, year, month)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		pending[id] = true
+	}
+	return pending, nil
+}
+
+// ListRecomputeTasks lists queued, running, finished and failed tasks
+func (s *Service) ListRecomputeTasks(ctx context.Context, status string, userID, batchID *uint, limit int) ([]RecomputeTask, error) {
+	return s.repo.ListRecomputeTasks(ctx, status, userID, batchID, limit)
+}
+
+// RetryFailedRecompute re-enqueues all FAILED tasks
+func (s *Service) RetryFailedRecompute(ctx context.Context) (int64, error) {
+	n, err := s.repo.RetryFailedRecomputeTasks(ctx)
+	if err != nil {
+		return 0, err
+	}
+	select {
+	case s.recomputeWake <- struct{}{}:
+	default:
+	}
+	return n, nil
+}
+
+// RunRecomputeWorker processes the recompute queue until ctx is cancelled.
+// Every instance may run a worker; tasks are claimed atomically so each runs once.
+func (s *Service) RunRecomputeWorker(ctx context.Context) {
+	instance := s.cfg.Scheduler.InstanceID
+	s.logger.Info("summary recompute worker started", zap.String("instance", instance))
+
+	ticker := time.NewTicker(recomputePollInterval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.repo.ResetStaleRecomputeTasks(ctx, time.Now().Add(-recomputeStaleAfter)); err != nil {
+			s.logger.Error("failed to reset stale recompute tasks", zap.Error(err))
+		} else if n > 0 {
+			s.logger.Warn("reset stale recompute tasks", zap.Int64("count", n))
+		}
+
+		// Drain everything that is due before waiting again
+		for ctx.Err() == nil {
+			tasks, err := s.repo.ClaimRecomputeTasks(ctx, instance, recomputeClaimSize)
+			if err != nil {
+				s.logger.Error("failed to claim recompute tasks", zap.Error(err))
+			}
+			for i := range tasks {
+				s.runRecomputeTask(ctx, &tasks[i])
+			}
+			if len(tasks) == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("summary recompute worker stopped")
+			return
+		case <-ticker.C:
+		case <-s.recomputeWake:
+		}
+	}
+}
+
+func (s *Service) runRecomputeTask(ctx context.Context, task *RecomputeTask) {
+	_, runErr := s.ComputeMonthlySummary(ctx, task.UserID, task.Year, task.Month, task.Reason)
+
+	var retryAt *time.Time
+	if runErr != nil {
+		fields := []zap.Field{
+			zap.Uint("userID", task.UserID),
+			zap.Int("year", task.Year),
+			zap.Int("month", task.Month),
+			zap.Int("attempt", task.Attempts),
+			zap.Error(runErr),
+		}
+		if task.Attempts < recomputeMaxAttempts {
+			// Back off 1, 4, 9, 16 minutes
+			t := time.Now().Add(time.Duration(task.Attempts*task.Attempts) * time.Minute)
+			retryAt = &t
+			s.logger.Warn("summary recompute failed, will retry", fields...)
+		} else {
+			s.logger.Error("summary recompute failed permanently", fields...)
+		}
+	}
+
+	// Use a fresh context so a shutdown does not leave the task RUNNING until it goes stale
+	finishCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.repo.FinishRecomputeTask(finishCtx, task, runErr, retryAt); err != nil {
+		s.logger.Error("failed to record recompute result", zap.Uint("taskID", task.ID), zap.Error(err))
+	}
+}
@@ -18,9 +18,14 @@ func NewRepo(db *gorm.DB) *Repo {
 // HasMonthlyGrant checks if monthly grant has been given for a specific year/month
 // Only checks non-deleted grants (GORM automatically filters soft-deleted records)
 func (r *Repo) HasMonthlyGrant(ctx context.Context, year, month int) (bool, error) {
+	return r.HasGrant(ctx, year, month, GrantTypeMonthly)
+}
+
+// HasGrant checks if a grant record of the given type exists for a specific year/month
+func (r *Repo) HasGrant(ctx context.Context, year, month int, grantType string) (bool, error) {
 	var count int64
 	err := r.db.WithContext(ctx).Model(&LeaveGrant{}).
-		Where("grant_year = ? AND grant_month = ? AND grant_type = ? AND deleted_at IS NULL", year, month, GrantTypeMonthly).
+		Where("grant_year = ? AND grant_month = ? AND grant_type = ? AND deleted_at IS NULL", year, month, grantType).
 		Count(&count).Error
 	if err != nil {
 		return false, err
@@ -31,10 +36,15 @@ func (r *Repo) HasMonthlyGrant(ctx context.Context, year, month int) (bool, erro
 // CreateMonthlyGrant creates a record for monthly grant (for all active users)
 // Uses ON CONFLICT to ensure idempotency - if grant already exists, does nothing
 func (r *Repo) CreateMonthlyGrant(ctx context.Context, year, month int) error {
+	return r.CreateGrant(ctx, year, month, GrantTypeMonthly)
+}
+
+// CreateGrant creates a grant record of the given type (idempotent, see CreateMonthlyGrant)
+func (r *Repo) CreateGrant(ctx context.Context, year, month int, grantType string) error {
 	grant := &LeaveGrant{
 		GrantYear:  year,
 		GrantMonth: month,
-		GrantType:  GrantTypeMonthly,
+		GrantType:  grantType,
 	}
 	// Use ON CONFLICT to make it idempotent - if unique key exists, do nothing
 	return r.db.WithContext(ctx).
@@ -54,10 +64,22 @@ func (r *Repo) UpsertMonthlySummary(ctx context.Context, s *MonthlySummary) erro
 	return r.db.WithContext(ctx).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "user_id"}, {Name: "year"}, {Name: "month"}},
-			DoUpdates: clause.AssignmentColumns([]string{"expected_units", "worked_units", "missing_units", "paid_used_units", "unpaid_units", "is_birthday", "updated_at"}),
+			DoUpdates: clause.AssignmentColumns([]string{"expected_units", "worked_units", "missing_units", "birthday_used_units", "paid_used_units", "comp_off_used_units", "unpaid_units", "is_birthday", "updated_at"}),
 		}).Create(s).Error
 }
 
+// UpsertMonthlySummaries upserts many summaries in batched statements
+func (r *Repo) UpsertMonthlySummaries(ctx context.Context, summaries []MonthlySummary) error {
+	if len(summaries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "year"}, {Name: "month"}},
+			DoUpdates: clause.AssignmentColumns([]string{"expected_units", "worked_units", "missing_units", "birthday_used_units", "paid_used_units", "comp_off_used_units", "unpaid_units", "is_birthday", "updated_at"}),
+		}).CreateInBatches(summaries, 500).Error
+}
+
 // GetMonthlySummary returns summary if exists
 func (r *Repo) GetMonthlySummary(ctx context.Context, userID uint, year, month int) (*MonthlySummary, error) {
 	var s MonthlySummary
@@ -107,21 +129,36 @@ func (r *Repo) GetYearMonthWithSummary(ctx context.Context) ([]struct {
 	return yearMonths, nil
 }
 
+// SummaryListFilter selects monthly summaries of one month
+type SummaryListFilter struct {
+	Year          int
+	Month         int
+	UserID        *uint
+	DepartmentID  *uint
+	DepartmentIDs []uint // when not nil, only these departments (department-scoped access)
+}
+
 // ListMonthlySummaries returns summaries with optional filters
-func (r *Repo) ListMonthlySummaries(ctx context.Context, year, month int, userID *uint, departmentID *uint) ([]MonthlySummary, error) {
+func (r *Repo) ListMonthlySummaries(ctx context.Context, filter SummaryListFilter) ([]MonthlySummary, error) {
 	query := r.db.WithContext(ctx).
 		Table("leave_monthly_summary").
-		Where("year = ? AND month = ?", year, month)
-	
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
+		Where("year = ? AND month = ?", filter.Year, filter.Month)
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
 	}
-	
-	if departmentID != nil {
-		query = query.Joins("INNER JOIN users u ON leave_monthly_summary.user_id = u.id").
-			Where("u.department_id = ?", *departmentID)
+
+	if filter.DepartmentID != nil || filter.DepartmentIDs != nil {
+		query = query.Select("leave_monthly_summary.*").
+			Joins("INNER JOIN users u ON leave_monthly_summary.user_id = u.id")
+	}
+	if filter.DepartmentID != nil {
+		query = query.Where("u.department_id = ?", *filter.DepartmentID)
 	}
-	
+	if filter.DepartmentIDs != nil {
+		query = query.Where("u.department_id IN ?", filter.DepartmentIDs)
+	}
+
 	var summaries []MonthlySummary
 	err := query.Find(&summaries).Error
 	return summaries, err
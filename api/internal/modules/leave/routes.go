@@ -1,6 +1,9 @@
 package leave
 
 import (
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -9,8 +12,8 @@ type Module struct {
 	s *Service
 }
 
-func NewModule(svc *Service) *Module {
-	return &Module{h: NewHandler(svc), s: svc}
+func NewModule(svc *Service, auditSvc *audit.Service) *Module {
+	return &Module{h: NewHandler(svc, auditSvc), s: svc}
 }
 
 func (m *Module) Service() *Service {
@@ -20,15 +23,42 @@ func (m *Module) Service() *Service {
 func (m *Module) RegisterMe(v1 fiber.Router, auth fiber.Handler) {
 	g := v1.Group("/me/leave", auth)
 	g.Get("/summary", m.h.GetMyLeaveSummary)
+	g.Get("/summary/explain", m.h.ExplainMyLeaveSummary)
+	g.Get("/days", m.h.GetMyLeaveDays)
+	g.Get("/comp-off", m.h.GetMyCompOff)
 }
 
 func (m *Module) RegisterAdmin(admin fiber.Router) {
 	g := admin.Group("/leave")
-	g.Post("/grant", m.h.AdminGrantLeave)
-	g.Get("/summary", m.h.AdminGetLeaveSummary)
-	g.Get("/summaries", m.h.AdminListSummaries)
-	g.Post("/summary/recalculate", m.h.AdminRecalculateSummary)
-	g.Patch("/summary/:userId/:year/:month", m.h.AdminAdjustPaidLeave)
-	g.Get("/grants", m.h.AdminListGrants)
+	g.Post("/grant", authx.Require(authx.PermLeaveManage), m.h.AdminGrantLeave)
+	g.Get("/summary", authx.Require(authx.PermLeaveRead), m.h.AdminGetLeaveSummary)
+	g.Get("/summary/explain", authx.Require(authx.PermLeaveRead), m.h.AdminExplainLeaveSummary)
+	g.Get("/summaries", authx.Require(authx.PermLeaveRead), m.h.AdminListSummaries)
+	g.Get("/summaries/alerts", authx.Require(authx.PermLeaveRead), m.h.AdminListSummaryAlerts)
+	g.Post("/summaries/alerts/:id/ack", authx.Require(authx.PermLeaveManage), m.h.AdminAcknowledgeSummaryAlert)
+	g.Get("/summaries/:userId/:year/:month/versions", authx.Require(authx.PermLeaveRead), m.h.AdminListSummaryVersions)
+	g.Get("/summaries/:userId/:year/:month/diff", authx.Require(authx.PermLeaveRead), m.h.AdminDiffSummaryVersions)
+	g.Get("/days", authx.Require(authx.PermLeaveRead), m.h.AdminGetLeaveDays)
+	g.Post("/summary/recalculate", authx.Require(authx.PermLeaveManage), m.h.AdminRecalculateSummary)
+	g.Get("/recompute", authx.Require(authx.PermLeaveRead), m.h.AdminListRecompute)
+	g.Get("/recompute/batches/:id", authx.Require(authx.PermLeaveRead), m.h.AdminGetRecomputeBatch)
+	g.Post("/recompute/retry", authx.Require(authx.PermLeaveManage), m.h.AdminRetryRecompute)
+	g.Patch("/summary/:userId/:year/:month", authx.Require(authx.PermLeaveManage), m.h.AdminAdjustPaidLeave)
+	g.Get("/grants", authx.Require(authx.PermLeaveRead), m.h.AdminListGrants)
+	g.Get("/plan", authx.Require(authx.PermLeaveRead), m.h.AdminPreviewPlan)
+	g.Post("/plan/apply", authx.Require(authx.PermLeaveManage), m.h.AdminApplyPlan)
+	g.Get("/policies", authx.Require(authx.PermLeaveRead), m.h.AdminListPolicies)
+	g.Post("/policies", authx.Require(authx.PermLeavePolicy), m.h.AdminCreatePolicy)
+	g.Put("/policies/:id", authx.Require(authx.PermLeavePolicy), m.h.AdminUpdatePolicy)
+	g.Delete("/policies/:id", authx.Require(authx.PermLeavePolicy), m.h.AdminDeletePolicy)
+	g.Get("/birthday-policy", authx.Require(authx.PermLeaveRead), m.h.AdminGetBirthdayPolicy)
+	g.Put("/birthday-policy", authx.Require(authx.PermLeavePolicy), m.h.AdminUpdateBirthdayPolicy)
+	g.Get("/comp-off", authx.Require(authx.PermLeaveRead), m.h.AdminListCompOff)
+	g.Post("/comp-off/detect", authx.Require(authx.PermLeaveManage), m.h.AdminDetectCompOff)
+	g.Post("/comp-off/:id/approve", authx.Require(authx.PermLeaveManage), m.h.AdminApproveCompOff)
+	g.Post("/comp-off/:id/reject", authx.Require(authx.PermLeaveManage), m.h.AdminRejectCompOff)
 }
 
+func (m *Module) RegisterManager(mgr fiber.Router) {
+	mgr.Get("/leave/summaries", m.h.ManagerListSummaries)
+}
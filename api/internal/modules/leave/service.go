@@ -8,6 +8,7 @@ import (
 	"time-attendance-be/internal/config"
 	"time-attendance-be/internal/modules/attendance"
 	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/platform/scheduler"
 
 	"go.uber.org/zap"
 )
@@ -17,6 +18,10 @@ type AttendanceRepo interface {
 	GetDatesWithoutAttendance(ctx context.Context, userID uint, fromDate, toDate time.Time) ([]time.Time, error)
 	GetSessionsWithDayUnitZero(ctx context.Context, fromDate, toDate time.Time) ([]attendance.Session, error)
 	SumDayUnitByRange(ctx context.Context, userID uint, from, to string) (float64, error)
+	SumDayUnitByUserDay(ctx context.Context, from, to string) ([]attendance.UserDayUnit, error)
+	ListByUserDateRange(userID uint, from, to string) ([]attendance.Session, error)
+	ListClosedByRange(ctx context.Context, from, to string) ([]attendance.Session, error)
+	ListByRange(ctx context.Context, from, to string) ([]attendance.Session, error)
 	GetYearMonthWithAttendance(ctx context.Context) ([]struct {
 		Year  int
 		Month int
@@ -30,6 +35,14 @@ type Service struct {
 	attendanceRepo AttendanceRepo
 	workCalRepo    WorkCalendarRepo
 	logger         *zap.Logger
+
+	// summaryRepo and users are repo and userRepo as read by the summary projections
+	summaryRepo SummaryRepo
+	users       UserLookup
+	settlements SettlementRepo
+
+	// recomputeWake nudges the recompute worker after new tasks are queued
+	recomputeWake chan struct{}
 }
 
 func NewService(cfg *config.Config, userRepo *user.Repo, repo *Repo, logger *zap.Logger) *Service {
@@ -38,6 +51,11 @@ func NewService(cfg *config.Config, userRepo *user.Repo, repo *Repo, logger *zap
 		userRepo: userRepo,
 		repo:     repo,
 		logger:   logger,
+
+		summaryRepo: repo,
+		users:       userRepo,
+
+		recomputeWake: make(chan struct{}, 1),
 	}
 }
 
@@ -49,13 +67,18 @@ func (s *Service) SetWorkCalendarRepo(repo WorkCalendarRepo) {
 	s.workCalRepo = repo
 }
 
+func (s *Service) SetSettlementRepo(repo SettlementRepo) {
+	s.settlements = repo
+}
+
 // ProcessMonthlyLeaveGrant processes monthly leave grant
 // - Checks if the current month has already been granted leave
 // - If not granted yet, automatically grants leave for all active users
-// - Adds 1 day of leave for all active users
+// - Amount per user comes from their accrual policy (see policy_service.go)
 // - Prevents duplicate grants by checking leave_grants table
 // Note: Birthday leave is no longer granted separately - it's calculated dynamically in ComputeMonthlySummary
-func (s *Service) ProcessMonthlyLeaveGrant(ctx context.Context) error {
+func (s *Service) ProcessMonthlyLeaveGrant(ctx context.Context) (scheduler.Result, error) {
+	var res scheduler.Result
 	now := time.Now().In(s.cfg.TimeLocation())
 	currentYear := now.Year()
 	currentMonth := int(now.Month())
@@ -65,14 +88,15 @@ func (s *Service) ProcessMonthlyLeaveGrant(ctx context.Context) error {
 	hasGrant, err := s.repo.HasMonthlyGrant(ctx, currentYear, currentMonth)
 	if err != nil {
 		s.logger.Error("failed to check monthly grant", zap.Error(err))
-		return err
+		return res, err
 	}
 	if hasGrant {
 		s.logger.Info("monthly leave grant already processed for this month",
 			zap.Int("year", currentYear),
 			zap.Int("month", currentMonth),
 			zap.Int("day", currentDay))
-		return nil
+		res.Message = fmt.Sprintf("grant for %d-%02d already processed", currentYear, currentMonth)
+		return res, nil
 	}
 
 	// If we're past the 1st of the month and grant hasn't been processed, process it now
@@ -93,71 +117,33 @@ func (s *Service) ProcessMonthlyLeaveGrant(ctx context.Context) error {
 	users, err := s.userRepo.GetAllActiveUsers(ctx)
 	if err != nil {
 		s.logger.Error("failed to get active users", zap.Error(err))
-		return err
+		return res, err
 	}
 
-	if len(users) == 0 {
-		s.logger.Info("no active users found")
-		// Still record the grant to prevent retry
-		if err := s.repo.CreateMonthlyGrant(ctx, currentYear, currentMonth); err != nil {
-			s.logger.Error("failed to create monthly grant record", zap.Error(err))
-		}
-		return nil
-	}
-
-	// Get all user IDs
-	var allUserIDs []uint
-	for _, u := range users {
-		allUserIDs = append(allUserIDs, u.ID)
-	}
-
-	// IMPORTANT: Create grant record FIRST to prevent duplicate grants
-	// This uses ON CONFLICT to ensure idempotency - if grant already exists, does nothing
-	if err := s.repo.CreateMonthlyGrant(ctx, currentYear, currentMonth); err != nil {
-		s.logger.Error("failed to create monthly grant record", zap.Error(err))
-		return err // Don't proceed if we can't record the grant
-	}
-	s.logger.Info("monthly grant record created", zap.Int("year", currentYear), zap.Int("month", currentMonth))
-
-	// Double-check: if grant was created by another process, don't proceed
-	hasGrant, err = s.repo.HasMonthlyGrant(ctx, currentYear, currentMonth)
+	// Record the grant and credit every user per accrual policy (tenure bonus, pro-rated
+	// first/last month) in one transaction: a failure leaves nothing behind for the retry
+	applied, err := s.grantAccruals(ctx, users, currentYear, currentMonth)
 	if err != nil {
-		s.logger.Error("failed to re-check monthly grant after creation", zap.Error(err))
-		return err
+		return res, err
 	}
-	if !hasGrant {
-		// This shouldn't happen, but if it does, something is wrong
-		s.logger.Error("grant record not found after creation - possible race condition")
-		return fmt.Errorf("grant record not found after creation")
-	}
-
-	// Now add 1 day for all active users (regular monthly leave)
-	if err := s.userRepo.BatchIncrementPaidLeave(ctx, allUserIDs, 1.0); err != nil {
-		s.logger.Error("failed to increment paid leave for all users", zap.Error(err))
-		return err
+	if !applied {
+		s.logger.Info("monthly leave grant was processed concurrently",
+			zap.Int("year", currentYear),
+			zap.Int("month", currentMonth))
+		res.Message = fmt.Sprintf("grant for %d-%02d already processed", currentYear, currentMonth)
+		return res, nil
 	}
-	s.logger.Info("added 1 day leave for all active users", zap.Int("count", len(allUserIDs)))
+	res.Processed = len(users)
+	res.Message = fmt.Sprintf("granted leave for %d-%02d", currentYear, currentMonth)
 
 	// Note: Birthday leave is no longer granted separately.
 	// It's calculated dynamically in ComputeMonthlySummary based on user.birthday.
 
-	// Compute monthly summary for all users for current month (lazy initialization)
-	// This ensures summary exists even if user hasn't queried it yet
-	if s.attendanceRepo != nil && s.workCalRepo != nil {
-		s.logger.Info("computing monthly summary for all users", zap.Int("year", currentYear), zap.Int("month", currentMonth))
-		for _, userID := range allUserIDs {
-			if _, err := s.ComputeMonthlySummary(ctx, userID, currentYear, currentMonth); err != nil {
-				s.logger.Error("failed to compute monthly summary after grant",
-					zap.Uint("userID", userID),
-					zap.Int("year", currentYear),
-					zap.Int("month", currentMonth),
-					zap.Error(err))
-				// Continue with other users
-			}
-		}
-	}
+	// Queue the current month summary for all users (balances changed; also initializes
+	// summaries for users who haven't queried theirs yet)
+	s.enqueueBalanceRecompute(ctx, userIDsOf(users))
 
-	return nil
+	return res, nil
 }
 
 // ProcessLeaveGrantForMonth processes leave grant for a specific month/year (for manual/admin use)
@@ -187,47 +173,15 @@ func (s *Service) ProcessLeaveGrantForMonth(ctx context.Context, year, month int
 		return err
 	}
 
-	if len(users) == 0 {
-		s.logger.Info("no active users found")
-		// Still record the grant to prevent retry
-		if err := s.repo.CreateMonthlyGrant(ctx, year, month); err != nil {
-			s.logger.Error("failed to create monthly grant record", zap.Error(err))
-		}
-		return nil
-	}
-
-	// Get all user IDs
-	var allUserIDs []uint
-	for _, u := range users {
-		allUserIDs = append(allUserIDs, u.ID)
-	}
-
-	// IMPORTANT: Create grant record FIRST to prevent duplicate grants
-	// This uses ON CONFLICT to ensure idempotency - if grant already exists, does nothing
-	if err := s.repo.CreateMonthlyGrant(ctx, year, month); err != nil {
-		s.logger.Error("failed to create monthly grant record", zap.Error(err))
-		return err // Don't proceed if we can't record the grant
-	}
-	s.logger.Info("monthly grant record created", zap.Int("year", year), zap.Int("month", month))
-
-	// Double-check: if grant was created by another process, don't proceed
-	hasGrant, err = s.repo.HasMonthlyGrant(ctx, year, month)
+	// Record the grant and credit users in one transaction (see ProcessMonthlyLeaveGrant)
+	applied, err := s.grantAccruals(ctx, users, year, month)
 	if err != nil {
-		s.logger.Error("failed to re-check monthly grant after creation", zap.Error(err))
 		return err
 	}
-	if !hasGrant {
-		// This shouldn't happen, but if it does, something is wrong
-		s.logger.Error("grant record not found after creation - possible race condition")
-		return fmt.Errorf("grant record not found after creation")
-	}
-
-	// Now add 1 day for all active users (regular monthly leave)
-	if err := s.userRepo.BatchIncrementPaidLeave(ctx, allUserIDs, 1.0); err != nil {
-		s.logger.Error("failed to increment paid leave for all users", zap.Error(err))
-		return err
+	if !applied {
+		return nil
 	}
-	s.logger.Info("added 1 day leave for all active users", zap.Int("count", len(allUserIDs)))
+	s.enqueueBalanceRecompute(ctx, userIDsOf(users))
 
 	// Note: Birthday leave is no longer granted separately.
 	// It's calculated dynamically in ComputeMonthlySummary based on user.birthday.
@@ -237,9 +191,11 @@ func (s *Service) ProcessLeaveGrantForMonth(ctx context.Context, year, month int
 
 // ProcessPreviousMonthLeaveDeduction processes leave deduction from previous month on the 1st of each month
 // - Computes monthly summary for all users for previous month
+// - Marks comp_off_used_units as consumed on the user's comp-off credits
 // - Deducts paid_used_units from users.paid_leave
 // - This ensures paid leave is deducted based on actual usage
-func (s *Service) ProcessPreviousMonthLeaveDeduction(ctx context.Context) error {
+func (s *Service) ProcessPreviousMonthLeaveDeduction(ctx context.Context) (scheduler.Result, error) {
+	var res scheduler.Result
 	now := time.Now().In(s.cfg.TimeLocation())
 	currentDay := now.Day()
 
@@ -247,7 +203,8 @@ func (s *Service) ProcessPreviousMonthLeaveDeduction(ctx context.Context) error
 	if currentDay != 1 {
 		s.logger.Debug("previous month leave deduction skipped - not the 1st of month",
 			zap.Int("day", currentDay))
-		return nil
+		res.Message = "skipped - not the 1st of month"
+		return res, nil
 	}
 
 	// Calculate previous month
@@ -255,6 +212,20 @@ func (s *Service) ProcessPreviousMonthLeaveDeduction(ctx context.Context) error
 	prevYear := prevMonth.Year()
 	prevMonthNum := int(prevMonth.Month())
 
+	// Skip if the deduction was already applied (scheduler restart on the 1st, or admin-applied plan)
+	hasDeduction, err := s.repo.HasGrant(ctx, prevYear, prevMonthNum, GrantTypeDeduction)
+	if err != nil {
+		s.logger.Error("failed to check leave deduction record", zap.Error(err))
+		return res, err
+	}
+	if hasDeduction {
+		s.logger.Info("previous month leave deduction already processed",
+			zap.Int("year", prevYear),
+			zap.Int("month", prevMonthNum))
+		res.Message = fmt.Sprintf("deduction for %d-%02d already processed", prevYear, prevMonthNum)
+		return res, nil
+	}
+
 	s.logger.Info("processing previous month leave deduction",
 		zap.Int("year", prevYear),
 		zap.Int("month", prevMonthNum))
@@ -263,78 +234,75 @@ func (s *Service) ProcessPreviousMonthLeaveDeduction(ctx context.Context) error
 	users, err := s.userRepo.GetAllActiveUsers(ctx)
 	if err != nil {
 		s.logger.Error("failed to get active users for leave deduction", zap.Error(err))
-		return err
+		return res, err
 	}
 
 	if len(users) == 0 {
 		s.logger.Info("no active users found for leave deduction")
-		return nil
+		return res, nil
 	}
 
-	// Compute summary and deduct paid leave for each user
-	var usersToDeduct []struct {
-		userID    uint
-		deductAmt float64
+	// Compute the previous month's summaries before recording the deduction, so a failure
+	// here leaves the deduction to be retried
+	summaries, err := s.ComputeMonthlySummaries(ctx, users, prevYear, prevMonthNum, SnapshotTriggerScheduler)
+	if err != nil {
+		s.logger.Error("failed to compute monthly summaries for leave deduction",
+			zap.Int("year", prevYear),
+			zap.Int("month", prevMonthNum),
+			zap.Error(err))
+		return res, err
 	}
 
-	for _, user := range users {
-		summary, err := s.ComputeMonthlySummary(ctx, user.ID, prevYear, prevMonthNum)
-		if err != nil {
-			s.logger.Error("failed to compute monthly summary for leave deduction",
-				zap.Uint("userID", user.ID),
-				zap.Int("year", prevYear),
-				zap.Int("month", prevMonthNum),
-				zap.Error(err))
-			continue
+	// Collect paid leave to deduct (amount -> users) and the comp-off each user used
+	deductMap := make(map[float64][]uint)
+	var deducted []uint
+	var compOff []CompOffUsage
+	monthStart := time.Date(prevYear, time.Month(prevMonthNum), 1, 0, 0, 0, 0, s.cfg.TimeLocation())
+	for i := range summaries {
+		summary := &summaries[i]
+		if summary.CompOffUsedUnits > 0 {
+			done, err := s.repo.HasCompOffUsage(ctx, summary.UserID, prevYear, prevMonthNum)
+			if err != nil {
+				return res, err
+			}
+			if !done {
+				usage, err := s.planCompOffUsage(ctx, summary.UserID, summary.CompOffUsedUnits, monthStart, monthStart.AddDate(0, 1, -1))
+				if err != nil {
+					return res, err
+				}
+				compOff = append(compOff, usage...)
+			}
 		}
-
-		if summary != nil && summary.PaidUsedUnits > 0 {
-			usersToDeduct = append(usersToDeduct, struct {
-				userID    uint
-				deductAmt float64
-			}{
-				userID:    user.ID,
-				deductAmt: summary.PaidUsedUnits,
-			})
+		if summary.PaidUsedUnits > 0 {
+			deductMap[summary.PaidUsedUnits] = append(deductMap[summary.PaidUsedUnits], summary.UserID)
+			deducted = append(deducted, summary.UserID)
 		}
 	}
 
-	if len(usersToDeduct) == 0 {
-		s.logger.Info("no paid leave to deduct for previous month",
+	// The deduction record, the decrements and the comp-off usage commit together, so a
+	// failure leaves the whole deduction to be retried
+	applied, err := s.repo.ApplyMonthlyDeduction(ctx, prevYear, prevMonthNum, deductMap, compOff)
+	if err != nil {
+		s.logger.Error("failed to apply leave deduction",
 			zap.Int("year", prevYear),
-			zap.Int("month", prevMonthNum))
-		return nil
+			zap.Int("month", prevMonthNum),
+			zap.Error(err))
+		return res, err
 	}
-
-	// Deduct paid leave for each user
-	// Note: Each user may have different amounts, so we deduct individually
-	// Group by amount to use batch operations when possible for efficiency
-	deductMap := make(map[float64][]uint)
-	for _, item := range usersToDeduct {
-		deductMap[item.deductAmt] = append(deductMap[item.deductAmt], item.userID)
+	if !applied {
+		res.Message = fmt.Sprintf("deduction for %d-%02d already processed", prevYear, prevMonthNum)
+		return res, nil
 	}
+	res.Processed = len(deducted)
 
-	for amount, userIDs := range deductMap {
-		if err := s.userRepo.BatchDecrementPaidLeave(ctx, userIDs, amount); err != nil {
-			s.logger.Error("failed to deduct paid leave",
-				zap.Float64("amount", amount),
-				zap.Int("userCount", len(userIDs)),
-				zap.Error(err))
-			continue
-		}
-		s.logger.Info("deducted paid leave from previous month",
-			zap.Float64("amount", amount),
-			zap.Int("userCount", len(userIDs)),
-			zap.Int("year", prevYear),
-			zap.Int("month", prevMonthNum))
-	}
+	s.enqueueBalanceRecompute(ctx, deducted)
 
 	s.logger.Info("processed previous month leave deduction",
 		zap.Int("year", prevYear),
 		zap.Int("month", prevMonthNum),
-		zap.Int("usersProcessed", len(usersToDeduct)))
+		zap.Int("usersProcessed", len(deducted)))
 
-	return nil
+	return res, nil
 }
 
 // Leave Usage Service Methods
@@ -357,30 +325,74 @@ func (s *Service) CreateLeaveUsageForDayUnitZero(ctx context.Context, userID uin
 	return nil
 }
 
-// GetLeaveUsageByUser returns leave usage records for a user
+// GetLeaveUsageByUser returns leave usage for a user, derived from the monthly breakdowns
+// of every month overlapping [fromDate, toDate]
 func (s *Service) GetLeaveUsageByUser(ctx context.Context, userID uint, fromDate, toDate time.Time) ([]LeaveUsage, error) {
-	return []LeaveUsage{}, nil
+	from := fromDate.Format("2006-01-02")
+	to := toDate.Format("2006-01-02")
+
+	usages := []LeaveUsage{}
+	cur := time.Date(fromDate.Year(), fromDate.Month(), 1, 0, 0, 0, 0, s.cfg.TimeLocation())
+	for !cur.After(toDate) {
+		monthUsages, err := s.GetLeaveUsageByUserAndMonth(ctx, userID, cur.Year(), int(cur.Month()))
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range monthUsages {
+			d := u.UsageDate.Format("2006-01-02")
+			if d >= from && d <= to {
+				usages = append(usages, u)
+			}
+		}
+		cur = cur.AddDate(0, 1, 0)
+	}
+	return usages, nil
 }
 
-// GetLeaveUsageByUserAndMonth returns leave usage records for a user in a specific month
+// GetLeaveUsageByUserAndMonth returns leave usage for a user in a specific month,
+// derived from the day-by-day allocation of the monthly summary
 func (s *Service) GetLeaveUsageByUserAndMonth(ctx context.Context, userID uint, year, month int) ([]LeaveUsage, error) {
-	return []LeaveUsage{}, nil
+	b, err := s.ProjectMonthlyBreakdown(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	return usageFromBreakdown(userID, b), nil
 }
 
 // GetLeaveUsageInfoByUserAndMonth implements attendance.LeaveRepo interface
 // Returns leave usage info for attendance service (to avoid circular dependency)
 func (s *Service) GetLeaveUsageInfoByUserAndMonth(ctx context.Context, userID uint, year, month int) ([]attendance.LeaveUsageInfo, error) {
-	return []attendance.LeaveUsageInfo{}, nil
+	usages, err := s.GetLeaveUsageByUserAndMonth(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]attendance.LeaveUsageInfo, 0, len(usages))
+	for _, u := range usages {
+		infos = append(infos, attendance.LeaveUsageInfo{UsageDate: u.UsageDate, IsUnpaid: u.IsUnpaid})
+	}
+	return infos, nil
 }
 
 // GetTotalDaysUsedInMonth returns total days of leave used by a user in a specific month
 func (s *Service) GetTotalDaysUsedInMonth(ctx context.Context, userID uint, year, month int) (float64, error) {
-	return 0, nil
+	usages, err := s.GetLeaveUsageByUserAndMonth(ctx, userID, year, month)
+	if err != nil {
+		return 0, err
+	}
+	total := 0.0
+	for _, u := range usages {
+		total += u.DaysUsed
+	}
+	return total, nil
 }
 
 // GetUnpaidDaysInMonth returns total unpaid leave days for a user in a specific month
 func (s *Service) GetUnpaidDaysInMonth(ctx context.Context, userID uint, year, month int) (float64, error) {
-	return 0, nil
+	summary, err := s.projectMonthlySummary(ctx, userID, year, month)
+	if err != nil {
+		return 0, err
+	}
+	return summary.UnpaidUnits, nil
 }
 
 // ProcessAutoLeaveDetection is disabled in summary-only design.
@@ -397,29 +409,30 @@ func (s *Service) ProcessDayUnitZeroBackfill(ctx context.Context, fromDate, toDa
 
 // ProcessSummaryBackfill computes monthly summary for all users for months that have attendance but no summary
 // This ensures summary exists for historical data (e.g., attendance from Dec 2025 but calendar/summary not created)
-func (s *Service) ProcessSummaryBackfill(ctx context.Context) error {
+func (s *Service) ProcessSummaryBackfill(ctx context.Context) (scheduler.Result, error) {
+	var res scheduler.Result
 	if s.attendanceRepo == nil || s.workCalRepo == nil {
 		s.logger.Debug("summary backfill skipped - attendance or work calendar repo not set")
-		return nil
+		return res, nil
 	}
 
 	// Get all year-month combinations that have attendance
 	attendanceMonths, err := s.attendanceRepo.GetYearMonthWithAttendance(ctx)
 	if err != nil {
 		s.logger.Error("failed to get year-month with attendance", zap.Error(err))
-		return err
+		return res, err
 	}
 
 	if len(attendanceMonths) == 0 {
 		s.logger.Debug("no attendance data found for summary backfill")
-		return nil
+		return res, nil
 	}
 
 	// Get all year-month combinations that already have summaries
 	summaryMonths, err := s.repo.GetYearMonthWithSummary(ctx)
 	if err != nil {
 		s.logger.Error("failed to get year-month with summary", zap.Error(err))
-		return err
+		return res, err
 	}
 
 	// Create a map of existing summaries for quick lookup
@@ -443,7 +456,7 @@ func (s *Service) ProcessSummaryBackfill(ctx context.Context) error {
 
 	if len(monthsToProcess) == 0 {
 		s.logger.Debug("all months with attendance already have summaries")
-		return nil
+		return res, nil
 	}
 
 	s.logger.Info("found months with attendance but no summary",
@@ -453,7 +466,7 @@ func (s *Service) ProcessSummaryBackfill(ctx context.Context) error {
 	users, err := s.userRepo.GetAllActiveUsers(ctx)
 	if err != nil {
 		s.logger.Error("failed to get active users for summary backfill", zap.Error(err))
-		return err
+		return res, err
 	}
 
 	// Process each month
@@ -466,20 +479,21 @@ func (s *Service) ProcessSummaryBackfill(ctx context.Context) error {
 			s.logger.Error("failed to ensure work calendar for year",
 				zap.Int("year", year),
 				zap.Error(err))
+			res.Failed += len(users)
 			continue
 		}
 
-		// Compute summary for all users for this month
-		for _, user := range users {
-			if _, err := s.ComputeMonthlySummary(ctx, user.ID, year, month); err != nil {
-				s.logger.Error("failed to compute monthly summary in backfill",
-					zap.Uint("userID", user.ID),
-					zap.Int("year", year),
-					zap.Int("month", month),
-					zap.Error(err))
-				// Continue with other users
-			}
+		// Compute summary for all users for this month (batch path: a few queries per month)
+		if _, err := s.ComputeMonthlySummaries(ctx, users, year, month, SnapshotTriggerScheduler); err != nil {
+			s.logger.Error("failed to compute monthly summaries in backfill",
+				zap.Int("year", year),
+				zap.Int("month", month),
+				zap.Error(err))
+			// Continue with other months
+			res.Failed += len(users)
+			continue
 		}
+		res.Processed += len(users)
 
 		s.logger.Info("backfilled summary for month",
 			zap.Int("year", year),
@@ -487,16 +501,12 @@ func (s *Service) ProcessSummaryBackfill(ctx context.Context) error {
 			zap.Int("userCount", len(users)))
 	}
 
-	return nil
+	return res, nil
 }
 
-// StartScheduler starts the scheduled job that runs daily to check if leave grant is needed
-// - On startup: checks if current month has been granted, if not, grants it
-// - Daily: checks if it's the 1st of the month and grant hasn't been processed yet
-// - Also processes birthday leave deduction on the 1st of each month
 // ListMonthlySummaries returns summaries with optional filters
-func (s *Service) ListMonthlySummaries(ctx context.Context, year, month int, userID *uint, departmentID *uint) ([]MonthlySummary, error) {
-	return s.repo.ListMonthlySummaries(ctx, year, month, userID, departmentID)
+func (s *Service) ListMonthlySummaries(ctx context.Context, filter SummaryListFilter) ([]MonthlySummary, error) {
+	return s.repo.ListMonthlySummaries(ctx, filter)
 }
 
 // ListGrants returns all leave grants
@@ -515,7 +525,8 @@ func (s *Service) AdjustUserPaidLeave(ctx context.Context, userID uint, paidLeav
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return fmt.Errorf("update user: %w", err)
 	}
-	
+	s.enqueueBalanceRecompute(ctx, []uint{userID})
+
 	return nil
 }
 
@@ -523,56 +534,3 @@ func (s *Service) AdjustUserPaidLeave(ctx context.Context, userID uint, paidLeav
 func (s *Service) GetUserIDsWithSummaryInMonth(ctx context.Context, year, month int) ([]uint, error) {
 	return s.repo.GetUserIDsWithSummaryInMonth(ctx, year, month)
 }
-
-func (s *Service) StartScheduler(ctx context.Context) {
-	ticker := time.NewTicker(24 * time.Hour) // Check once per day
-	defer ticker.Stop()
-
-	// Run immediately on startup to check if current month needs grant
-	s.logger.Info("leave scheduler started - checking current month grant status")
-	s.ProcessMonthlyLeaveGrant(ctx)
-	s.ProcessPreviousMonthLeaveDeduction(ctx)
-
-	// Backfill summaries for months with attendance but no summary
-	s.ProcessSummaryBackfill(ctx)
-
-	// Ensure work calendar for current year (and pre-create if missing)
-	if s.workCalRepo != nil {
-		now := time.Now().In(s.cfg.TimeLocation())
-		year := now.Year()
-		if err := s.workCalRepo.EnsureYear(ctx, year); err != nil {
-			s.logger.Error("failed to ensure work calendar for current year", zap.Int("year", year), zap.Error(err))
-		} else {
-			s.logger.Info("work calendar ensured", zap.Int("year", year))
-		}
-	}
-
-	// Then check daily
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info("leave scheduler stopped")
-			return
-		case <-ticker.C:
-			// Each day, ensure work calendar for current year (use server time)
-			if s.workCalRepo != nil {
-				today := time.Now().In(s.cfg.TimeLocation())
-				year := today.Year()
-				if err := s.workCalRepo.EnsureYear(ctx, year); err != nil {
-					s.logger.Error("failed to ensure work calendar for current year", zap.Int("year", year), zap.Error(err))
-				}
-			}
-
-			// Check daily - will process if it's the 1st and grant hasn't been done
-			// or if grant is missing for current month
-			s.ProcessMonthlyLeaveGrant(ctx)
-			s.ProcessPreviousMonthLeaveDeduction(ctx)
-
-			// Backfill summaries weekly (only on Monday to avoid too frequent checks)
-			now := time.Now().In(s.cfg.TimeLocation())
-			if now.Weekday() == time.Monday {
-				s.ProcessSummaryBackfill(ctx)
-			}
-		}
-	}
-}
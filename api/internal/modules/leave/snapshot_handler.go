@@ -0,0 +1,116 @@
+package leave
+
+import (
+	"strconv"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /api/v1/admin/leave/summaries/:userId/:year/:month/versions
+// Version history of a monthly summary, newest first
+func (h *Handler) AdminListSummaryVersions(c *fiber.Ctx) error {
+	userID, year, month, err := summaryKeyParams(c)
+	if err != nil {
+		return err
+	}
+
+	snaps, err := h.svc.ListSummarySnapshots(c.Context(), userID, year, month)
+	if err != nil {
+		return response.Internal(err)
+	}
+	return response.OK(c, toSummarySnapshotResponses(snaps))
+}
+
+// GET /api/v1/admin/leave/summaries/:userId/:year/:month/diff?from=&to=
+// Compares two versions; to defaults to the latest and from to the version before to
+func (h *Handler) AdminDiffSummaryVersions(c *fiber.Ctx) error {
+	userID, year, month, err := summaryKeyParams(c)
+	if err != nil {
+		return err
+	}
+
+	from, to := 0, 0
+	if v := c.Query("from"); v != "" {
+		if from, err = strconv.Atoi(v); err != nil || from < 1 {
+			return response.Validation("invalid from version", nil)
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = strconv.Atoi(v); err != nil || to < 1 {
+			return response.Validation("invalid to version", nil)
+		}
+	}
+
+	diff, err := h.svc.DiffSummarySnapshots(c.Context(), userID, year, month, from, to)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toSnapshotDiffResponse(diff))
+}
+
+// GET /api/v1/admin/leave/summaries/alerts?all=true&limit=
+// Summaries of closed months (deduction already processed) whose figures changed; open alerts only unless all=true
+func (h *Handler) AdminListSummaryAlerts(c *fiber.Ctx) error {
+	limit := 100
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+
+	alerts, err := h.svc.ListSummaryAlerts(c.Context(), c.QueryBool("all"), limit)
+	if err != nil {
+		return response.Internal(err)
+	}
+	return response.OK(c, toSummarySnapshotResponses(alerts))
+}
+
+// POST /api/v1/admin/leave/summaries/alerts/:id/ack
+func (h *Handler) AdminAcknowledgeSummaryAlert(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	snap, err := h.svc.AcknowledgeSummaryAlert(c.Context(), uint(id), adminUser.ID)
+	if err != nil {
+		return err
+	}
+	res := toSummarySnapshotResponse(snap)
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"ACKNOWLEDGE",
+			"leave_summary_snapshot",
+			strconv.FormatUint(uint64(snap.ID), 10),
+			nil,
+			res,
+			"",
+		)
+	}
+
+	return response.OK(c, res)
+}
+
+func summaryKeyParams(c *fiber.Ctx) (uint, int, int, error) {
+	userID, err := strconv.ParseUint(c.Params("userId"), 10, 64)
+	if err != nil {
+		return 0, 0, 0, response.Validation("invalid userId", nil)
+	}
+	year, err := strconv.Atoi(c.Params("year"))
+	if err != nil {
+		return 0, 0, 0, response.Validation("invalid year", nil)
+	}
+	month, err := strconv.Atoi(c.Params("month"))
+	if err != nil || month < 1 || month > 12 {
+		return 0, 0, 0, response.Validation("invalid month", nil)
+	}
+	return uint(userID), year, month, nil
+}
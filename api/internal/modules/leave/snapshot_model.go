@@ -0,0 +1,61 @@
+package leave
+
+import "time"
+
+// SummarySnapshot is one version of a user's monthly summary (table leave_summary_snapshots).
+// A version is recorded whenever a recompute changes the figures, so the history of
+// leave_monthly_summary (which is overwritten in place) can be audited and diffed.
+type SummarySnapshot struct {
+	ID                uint    `gorm:"primaryKey"`
+	UserID            uint    `gorm:"not null;uniqueIndex:idx_snapshot_version"`
+	Year              int     `gorm:"not null;uniqueIndex:idx_snapshot_version"`
+	Month             int     `gorm:"not null;uniqueIndex:idx_snapshot_version"`
+	Version           int     `gorm:"not null;uniqueIndex:idx_snapshot_version"`
+	ExpectedUnits     float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	WorkedUnits       float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	MissingUnits      float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	BirthdayUsedUnits float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	PaidUsedUnits     float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	CompOffUsedUnits  float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	UnpaidUnits       float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	IsBirthday        bool    `gorm:"type:tinyint(1);not null;default:0"`
+	Trigger           string  `gorm:"type:varchar(32);not null"`
+	ChangedFields     *string `gorm:"type:varchar(255)"` // comma-separated, vs the previous version
+
+	// Alert is set when the attendance of a closed month (deduction already processed) changed
+	Alert          bool `gorm:"type:tinyint(1);not null;default:0;index"`
+	AcknowledgedBy *uint
+	AcknowledgedAt *time.Time
+	CreatedAt      time.Time `gorm:"not null"`
+}
+
+func (SummarySnapshot) TableName() string {
+	return "leave_summary_snapshots"
+}
+
+// What caused a summary to be recomputed. Recomputes from the queue use the task's
+// RecomputeReason* (ATTENDANCE_CHANGED, CALENDAR_CHANGED, BALANCE_CHANGED, MANUAL, RETRY).
+const (
+	SnapshotTriggerScheduler   = "SCHEDULER" // monthly deduction / backfill jobs
+	SnapshotTriggerManual      = RecomputeReasonManual
+	SnapshotTriggerSignOff     = "SIGN_OFF"    // timesheet confirmation
+	SnapshotTriggerOffboarding = "OFFBOARDING" // final month of an offboarded user
+)
+
+// Summary returns the snapshot's figures as a MonthlySummary
+func (s *SummarySnapshot) Summary() *MonthlySummary {
+	return &MonthlySummary{
+		UserID:            s.UserID,
+		Year:              s.Year,
+		Month:             s.Month,
+		ExpectedUnits:     s.ExpectedUnits,
+		WorkedUnits:       s.WorkedUnits,
+		MissingUnits:      s.MissingUnits,
+		BirthdayUsedUnits: s.BirthdayUsedUnits,
+		PaidUsedUnits:     s.PaidUsedUnits,
+		CompOffUsedUnits:  s.CompOffUsedUnits,
+		UnpaidUnits:       s.UnpaidUnits,
+		IsBirthday:        s.IsBirthday,
+		UpdatedAt:         s.CreatedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package leave
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// LatestSummarySnapshots returns the latest snapshot of each of the users for a month
+func (r *Repo) LatestSummarySnapshots(ctx context.Context, year, month int, userIDs []uint) (map[uint]SummarySnapshot, error) {
+	latest := make(map[uint]SummarySnapshot, len(userIDs))
+	if len(userIDs) == 0 {
+		return latest, nil
+	}
+	maxVersions := r.db.WithContext(ctx).
+		Model(&SummarySnapshot{}).
+		Select("user_id, MAX(version) AS version").
+		Where("year = ? AND month = ? AND user_id IN ?", year, month, userIDs).
+		Group("user_id")
+
+	var rows []SummarySnapshot
+	err := r.db.WithContext(ctx).
+		Table("leave_summary_snapshots AS s").
+		Select("s.*").
+		Joins("INNER JOIN (?) AS m ON m.user_id = s.user_id AND m.version = s.version", maxVersions).
+		Where("s.year = ? AND s.month = ?", year, month).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range rows {
+		latest[s.UserID] = s
+	}
+	return latest, nil
+}
+
+// CreateSummarySnapshots inserts new versions. A version already taken by a concurrent
+// recompute of the same summary is skipped.
+func (r *Repo) CreateSummarySnapshots(ctx context.Context, snapshots []SummarySnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(snapshots, 500).Error
+}
+
+// ListSummarySnapshots returns all versions of a user's monthly summary, newest first
+func (r *Repo) ListSummarySnapshots(ctx context.Context, userID uint, year, month int) ([]SummarySnapshot, error) {
+	var rows []SummarySnapshot
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND year = ? AND month = ?", userID, year, month).
+		Order("version DESC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// GetSummarySnapshot returns one version (nil if it does not exist)
+func (r *Repo) GetSummarySnapshot(ctx context.Context, userID uint, year, month, version int) (*SummarySnapshot, error) {
+	var rows []SummarySnapshot
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND year = ? AND month = ? AND version = ?", userID, year, month, version).
+		Limit(1).
+		Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// ListSummaryAlerts returns snapshots flagged as changes to closed months, newest first
+func (r *Repo) ListSummaryAlerts(ctx context.Context, includeAcknowledged bool, limit int) ([]SummarySnapshot, error) {
+	q := r.db.WithContext(ctx).Where("alert = ?", true)
+	if !includeAcknowledged {
+		q = q.Where("acknowledged_at IS NULL")
+	}
+	var rows []SummarySnapshot
+	err := q.Order("created_at DESC, id DESC").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// AcknowledgeSummaryAlert marks an alert as seen; returns false if there is no such open alert
+func (r *Repo) AcknowledgeSummaryAlert(ctx context.Context, id, adminID uint) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&SummarySnapshot{}).
+		Where("id = ? AND alert = ? AND acknowledged_at IS NULL", id, true).
+		Updates(map[string]interface{}{
+			"acknowledged_by": adminID,
+			"acknowledged_at": time.Now(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// GetSummarySnapshotByID returns a snapshot by ID
+func (r *Repo) GetSummarySnapshotByID(ctx context.Context, id uint) (*SummarySnapshot, error) {
+	var s SummarySnapshot
+	if err := r.db.WithContext(ctx).First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,307 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"time-attendance-be/internal/pkg/response"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// persistSummaries upserts computed summaries (all of one month) and records their snapshots.
+// A month whose leave deduction has run is closed: its stored summaries are what was settled, and
+// recomputing them from today's balances would only move units between paid and unpaid. They are
+// kept as they are; a change in attendance (expected, worked or missing units) is flagged as an
+// alert instead. Returns the summaries as stored.
+func (s *Service) persistSummaries(ctx context.Context, year, month int, summaries []MonthlySummary, trigger string) ([]MonthlySummary, error) {
+	closed, err := s.repo.HasGrant(ctx, year, month, GrantTypeDeduction)
+	if err != nil {
+		return nil, fmt.Errorf("check month closed: %w", err)
+	}
+	if !closed {
+		if err := s.repo.UpsertMonthlySummaries(ctx, summaries); err != nil {
+			return nil, fmt.Errorf("upsert summaries: %w", err)
+		}
+		s.recordSummarySnapshots(ctx, year, month, summaries, trigger)
+		return summaries, nil
+	}
+
+	filter := SummaryListFilter{Year: year, Month: month}
+	if len(summaries) == 1 {
+		filter.UserID = &summaries[0].UserID
+	}
+	rows, err := s.repo.ListMonthlySummaries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list summaries: %w", err)
+	}
+	stored := make(map[uint]MonthlySummary, len(rows))
+	for _, r := range rows {
+		stored[r.UserID] = r
+	}
+
+	result := make([]MonthlySummary, len(summaries))
+	var missing, recomputed []MonthlySummary
+	for i := range summaries {
+		if st, ok := stored[summaries[i].UserID]; ok {
+			result[i] = st
+			recomputed = append(recomputed, summaries[i])
+			continue
+		}
+		result[i] = summaries[i]
+		missing = append(missing, summaries[i])
+	}
+	// Users without a summary of the closed month yet (backfill) get one
+	if err := s.repo.UpsertMonthlySummaries(ctx, missing); err != nil {
+		return nil, fmt.Errorf("upsert summaries: %w", err)
+	}
+	s.recordSummarySnapshots(ctx, year, month, missing, trigger)
+	if err := s.recordClosedMonthChanges(ctx, year, month, stored, recomputed, trigger); err != nil {
+		s.logger.Error("failed to record closed month changes",
+			zap.Int("year", year),
+			zap.Int("month", month),
+			zap.String("trigger", trigger),
+			zap.Error(err))
+	}
+	return result, nil
+}
+
+// recordClosedMonthChanges stores an alert version for every closed-month summary whose
+// attendance figures differ from its latest version. The version keeps the stored leave split.
+func (s *Service) recordClosedMonthChanges(ctx context.Context, year, month int, stored map[uint]MonthlySummary, recomputed []MonthlySummary, trigger string) error {
+	if len(recomputed) == 0 {
+		return nil
+	}
+	userIDs := make([]uint, len(recomputed))
+	for i := range recomputed {
+		userIDs[i] = recomputed[i].UserID
+	}
+	latest, err := s.repo.LatestSummarySnapshots(ctx, year, month, userIDs)
+	if err != nil {
+		return err
+	}
+
+	var snapshots []SummarySnapshot
+	for i := range recomputed {
+		st := stored[recomputed[i].UserID]
+		base := newSummarySnapshot(&st, trigger)
+		if prev, ok := latest[st.UserID]; ok {
+			base = prev
+		}
+		snap := newSummarySnapshot(&st, trigger)
+		computed := newSummarySnapshot(&recomputed[i], trigger)
+		snap.ExpectedUnits = computed.ExpectedUnits
+		snap.WorkedUnits = computed.WorkedUnits
+		snap.MissingUnits = computed.MissingUnits
+		snap.CreatedAt = recomputed[i].UpdatedAt
+
+		fields := diffAttendance(&base, &snap)
+		if len(fields) == 0 {
+			continue
+		}
+		changed := strings.Join(fields, ",")
+		if prev, ok := latest[st.UserID]; ok {
+			snap.Version = prev.Version + 1
+		}
+		snap.ChangedFields = &changed
+		snap.Alert = true
+		s.logger.Warn("attendance of a closed month changed",
+			zap.Uint("userID", snap.UserID),
+			zap.Int("year", year),
+			zap.Int("month", month),
+			zap.Int("version", snap.Version),
+			zap.String("trigger", trigger),
+			zap.String("fields", changed))
+		snapshots = append(snapshots, snap)
+	}
+	return s.repo.CreateSummarySnapshots(ctx, snapshots)
+}
+
+// diffAttendance lists the attendance figures that differ between two versions
+func diffAttendance(a, b *SummarySnapshot) []string {
+	var fields []string
+	if a.ExpectedUnits != b.ExpectedUnits {
+		fields = append(fields, "expectedUnits")
+	}
+	if a.WorkedUnits != b.WorkedUnits {
+		fields = append(fields, "workedUnits")
+	}
+	if a.MissingUnits != b.MissingUnits {
+		fields = append(fields, "missingUnits")
+	}
+	return fields
+}
+
+// recordSummarySnapshots stores a new snapshot version for every summary (all of one month)
+// whose figures differ from its latest version.
+// Failures are logged: the summaries themselves are already persisted.
+func (s *Service) recordSummarySnapshots(ctx context.Context, year, month int, summaries []MonthlySummary, trigger string) {
+	if len(summaries) == 0 {
+		return
+	}
+	if err := s.createSummarySnapshots(ctx, year, month, summaries, trigger); err != nil {
+		s.logger.Error("failed to record summary snapshots",
+			zap.Int("year", year),
+			zap.Int("month", month),
+			zap.String("trigger", trigger),
+			zap.Error(err))
+	}
+}
+
+func (s *Service) createSummarySnapshots(ctx context.Context, year, month int, summaries []MonthlySummary, trigger string) error {
+	userIDs := make([]uint, len(summaries))
+	for i := range summaries {
+		userIDs[i] = summaries[i].UserID
+	}
+	latest, err := s.repo.LatestSummarySnapshots(ctx, year, month, userIDs)
+	if err != nil {
+		return err
+	}
+
+	var snapshots []SummarySnapshot
+	for i := range summaries {
+		snap := newSummarySnapshot(&summaries[i], trigger)
+		if prev, ok := latest[snap.UserID]; ok {
+			fields := diffSummaries(prev.Summary(), snap.Summary())
+			if len(fields) == 0 {
+				continue
+			}
+			changed := strings.Join(fields, ",")
+			snap.Version = prev.Version + 1
+			snap.ChangedFields = &changed
+		}
+		snapshots = append(snapshots, snap)
+	}
+	return s.repo.CreateSummarySnapshots(ctx, snapshots)
+}
+
+// newSummarySnapshot is version 1 of a summary, figures rounded like the decimal(6,2) columns
+// so comparisons with stored versions are exact
+func newSummarySnapshot(m *MonthlySummary, trigger string) SummarySnapshot {
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	return SummarySnapshot{
+		UserID:            m.UserID,
+		Year:              m.Year,
+		Month:             m.Month,
+		Version:           1,
+		ExpectedUnits:     round(m.ExpectedUnits),
+		WorkedUnits:       round(m.WorkedUnits),
+		MissingUnits:      round(m.MissingUnits),
+		BirthdayUsedUnits: round(m.BirthdayUsedUnits),
+		PaidUsedUnits:     round(m.PaidUsedUnits),
+		CompOffUsedUnits:  round(m.CompOffUsedUnits),
+		UnpaidUnits:       round(m.UnpaidUnits),
+		IsBirthday:        m.IsBirthday,
+		Trigger:           trigger,
+		CreatedAt:         m.UpdatedAt,
+	}
+}
+
+// ListSummarySnapshots returns the version history of a user's monthly summary, newest first
+func (s *Service) ListSummarySnapshots(ctx context.Context, userID uint, year, month int) ([]SummarySnapshot, error) {
+	return s.repo.ListSummarySnapshots(ctx, userID, year, month)
+}
+
+// SnapshotFieldChange is one figure that differs between two snapshot versions
+type SnapshotFieldChange struct {
+	Field string
+	From  float64
+	To    float64
+	Delta float64
+}
+
+// SnapshotDiff compares two versions of a monthly summary
+type SnapshotDiff struct {
+	From    *SummarySnapshot
+	To      *SummarySnapshot
+	Changes []SnapshotFieldChange
+}
+
+// DiffSummarySnapshots compares two versions of a user's monthly summary.
+// toVersion 0 means the latest version; fromVersion 0 means the version before toVersion.
+func (s *Service) DiffSummarySnapshots(ctx context.Context, userID uint, year, month, fromVersion, toVersion int) (*SnapshotDiff, error) {
+	if toVersion == 0 {
+		versions, err := s.repo.ListSummarySnapshots(ctx, userID, year, month)
+		if err != nil {
+			return nil, response.Internal(err)
+		}
+		if len(versions) == 0 {
+			return nil, response.NotFound("No snapshots for this summary")
+		}
+		toVersion = versions[0].Version
+	}
+	if fromVersion == 0 {
+		fromVersion = toVersion - 1
+	}
+	if fromVersion < 1 || fromVersion == toVersion {
+		return nil, response.Validation("from and to must be two different versions", nil)
+	}
+
+	from, err := s.repo.GetSummarySnapshot(ctx, userID, year, month, fromVersion)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	to, err := s.repo.GetSummarySnapshot(ctx, userID, year, month, toVersion)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if from == nil || to == nil {
+		return nil, response.NotFound("Snapshot version not found")
+	}
+
+	figures := []struct {
+		name     string
+		from, to float64
+	}{
+		{"expectedUnits", from.ExpectedUnits, to.ExpectedUnits},
+		{"workedUnits", from.WorkedUnits, to.WorkedUnits},
+		{"missingUnits", from.MissingUnits, to.MissingUnits},
+		{"birthdayUsedUnits", from.BirthdayUsedUnits, to.BirthdayUsedUnits},
+		{"paidUsedUnits", from.PaidUsedUnits, to.PaidUsedUnits},
+		{"compOffUsedUnits", from.CompOffUsedUnits, to.CompOffUsedUnits},
+		{"unpaidUnits", from.UnpaidUnits, to.UnpaidUnits},
+	}
+	diff := &SnapshotDiff{From: from, To: to, Changes: []SnapshotFieldChange{}}
+	for _, f := range figures {
+		if f.from != f.to {
+			diff.Changes = append(diff.Changes, SnapshotFieldChange{
+				Field: f.name,
+				From:  f.from,
+				To:    f.to,
+				Delta: math.Round((f.to-f.from)*100) / 100,
+			})
+		}
+	}
+	return diff, nil
+}
+
+// ListSummaryAlerts returns changes to closed months, unacknowledged only unless all is set
+func (s *Service) ListSummaryAlerts(ctx context.Context, all bool, limit int) ([]SummarySnapshot, error) {
+	return s.repo.ListSummaryAlerts(ctx, all, limit)
+}
+
+// AcknowledgeSummaryAlert marks an alert as reviewed
+func (s *Service) AcknowledgeSummaryAlert(ctx context.Context, id, adminID uint) (*SummarySnapshot, error) {
+	ok, err := s.repo.AcknowledgeSummaryAlert(ctx, id, adminID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	snap, err := s.repo.GetSummarySnapshotByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFound("Alert not found")
+		}
+		return nil, response.Internal(err)
+	}
+	if !ok {
+		if !snap.Alert {
+			return nil, response.NotFound("Alert not found")
+		}
+		return nil, response.Conflict("Alert is already acknowledged")
+	}
+	return snap, nil
+}
@@ -0,0 +1,186 @@
+package leave
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"time-attendance-be/internal/modules/user"
+)
+
+// ComputeMonthlySummaries is ComputeMonthlySummary for many users of one month.
+// It loads the calendar once, aggregates sessions with one GROUP BY and upserts in bulk,
+// so the number of queries does not grow with the number of users. Closed months keep their
+// stored summaries (see persistSummaries).
+func (s *Service) ComputeMonthlySummaries(ctx context.Context, users []user.User, year, month int, trigger string) ([]MonthlySummary, error) {
+	summaries, err := s.projectMonthlySummaries(ctx, users, year, month)
+	if err != nil {
+		return nil, err
+	}
+	return s.persistSummaries(ctx, year, month, summaries, trigger)
+}
+
+// projectMonthlySummaries computes summaries for many users without persisting them.
+// Results are identical to projectMonthlySummary per user (see summary_batch_service_test.go).
+func (s *Service) projectMonthlySummaries(ctx context.Context, users []user.User, year, month int) ([]MonthlySummary, error) {
+	if s.workCalRepo == nil || s.attendanceRepo == nil {
+		return nil, fmt.Errorf("work calendar or attendance repo not set")
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	if err := s.workCalRepo.EnsureYear(ctx, year); err != nil {
+		return nil, fmt.Errorf("ensure calendar year: %w", err)
+	}
+
+	startDate, calcEndDate := s.summaryPeriod(year, month)
+	calDays, err := s.workCalRepo.ListRange(ctx, startDate, calcEndDate)
+	if err != nil {
+		return nil, fmt.Errorf("list calendar: %w", err)
+	}
+
+	// Worked units per user and day (only CLOSED sessions on working days)
+	dayUnits, err := s.attendanceRepo.SumDayUnitByUserDay(ctx, startDate.Format("2006-01-02"), calcEndDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("sum attendance: %w", err)
+	}
+	workedByUser := make(map[uint]map[string]float64)
+	for _, d := range dayUnits {
+		if workedByUser[d.UserID] == nil {
+			workedByUser[d.UserID] = make(map[string]float64)
+		}
+		workedByUser[d.UserID][d.WorkDate] += d.DayUnit
+	}
+
+	policy, err := s.GetBirthdayPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("birthday policy: %w", err)
+	}
+	// Birthday usage of earlier months; a window starts at most ~15 months back (DAYS <= 90, carry-over)
+	usedByUser := make(map[uint][]MonthlySummary)
+	if policy.Days > 0 {
+		rows, err := s.summaryRepo.ListBirthdayUsed(ctx, (year-2)*100+1, year*100+month)
+		if err != nil {
+			return nil, fmt.Errorf("birthday usage: %w", err)
+		}
+		for _, r := range rows {
+			usedByUser[r.UserID] = append(usedByUser[r.UserID], r)
+		}
+	}
+
+	compOffByUser, err := s.summaryRepo.ListCompOffAvailable(ctx, nil, year, month, startDate, calcEndDate)
+	if err != nil {
+		return nil, fmt.Errorf("comp-off balance: %w", err)
+	}
+
+	balances, err := s.settledBalances(ctx, users)
+	if err != nil {
+		return nil, fmt.Errorf("settled balances: %w", err)
+	}
+
+	summaries := make([]MonthlySummary, 0, len(users))
+	for i := range users {
+		u := &users[i]
+		days := workedByUser[u.ID]
+
+		bw, err := s.resolveBirthdayWindow(policy, u, year, month, func(fromYM int) (float64, error) {
+			used := 0.0
+			for _, r := range usedByUser[u.ID] {
+				if r.Year*100+r.Month >= fromYM {
+					used += r.BirthdayUsedUnits
+				}
+			}
+			return math.Round(used*100) / 100, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("birthday window for user %d: %w", u.ID, err)
+		}
+
+		paidLeave, ok := balances[u.ID]
+		if !ok {
+			paidLeave = u.PaidLeave
+		}
+
+		endDate, userCalDays := calcEndDate, calDays
+		if u.TerminationDate != nil {
+			endDate = capAtTermination(u, calcEndDate)
+			userCalDays = calendarUntil(calDays, endDate)
+		}
+
+		summary, err := buildMonthlySummary(summaryInputs{
+			UserID:    u.ID,
+			Year:      year,
+			Month:     month,
+			Start:     startDate,
+			End:       endDate,
+			CalDays:   userCalDays,
+			Worked:    sumDayUnits(days, startDate, endDate),
+			PaidLeave: paidLeave,
+			Window:    bw,
+			WorkedBetween: func(from, to time.Time) (float64, error) {
+				return sumDayUnits(days, from, to), nil
+			},
+			CompOffAvailable: func() (float64, error) {
+				return compOffByUser[u.ID], nil
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("summary for user %d: %w", u.ID, err)
+		}
+		summaries = append(summaries, *summary)
+	}
+
+	return summaries, nil
+}
+
+// calendarUntil returns the calendar days up to and including end's date
+func calendarUntil(days []WorkCalendarDay, end time.Time) []WorkCalendarDay {
+	last := end.Format("2006-01-02")
+	out := make([]WorkCalendarDay, 0, len(days))
+	for _, d := range days {
+		if d.WorkDate.Format("2006-01-02") <= last {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// sumDayUnits adds up the worked units of the days in [from, to]. day_unit is decimal(2,1), so the
+// total is rounded to one decimal to match the exact SUM the database returns.
+func sumDayUnits(days map[string]float64, from, to time.Time) float64 {
+	fromStr := from.Format("2006-01-02")
+	toStr := to.Format("2006-01-02")
+	total := 0.0
+	for date, units := range days {
+		if date >= fromStr && date <= toStr {
+			total += units
+		}
+	}
+	return math.Round(total*10) / 10
+}
+
+// diffSummaries lists the computed fields that differ (UpdatedAt is ignored)
+func diffSummaries(a, b *MonthlySummary) []string {
+	if b == nil {
+		return []string{"missing"}
+	}
+	var fields []string
+	check := func(name string, x, y float64) {
+		if x != y {
+			fields = append(fields, name)
+		}
+	}
+	check("expectedUnits", a.ExpectedUnits, b.ExpectedUnits)
+	check("workedUnits", a.WorkedUnits, b.WorkedUnits)
+	check("missingUnits", a.MissingUnits, b.MissingUnits)
+	check("birthdayUsedUnits", a.BirthdayUsedUnits, b.BirthdayUsedUnits)
+	check("paidUsedUnits", a.PaidUsedUnits, b.PaidUsedUnits)
+	check("compOffUsedUnits", a.CompOffUsedUnits, b.CompOffUsedUnits)
+	check("unpaidUnits", a.UnpaidUnits, b.UnpaidUnits)
+	if a.IsBirthday != b.IsBirthday {
+		fields = append(fields, "isBirthday")
+	}
+	return fields
+}
@@ -0,0 +1,352 @@
+package leave
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"time-attendance-be/internal/config"
+	"time-attendance-be/internal/modules/attendance"
+	"time-attendance-be/internal/modules/user"
+
+	"go.uber.org/zap"
+)
+
+const testTZ = "Asia/Ho_Chi_Minh"
+
+// fakeCalendar is a Monday-Friday calendar with one unit per working day, minus holidays
+type fakeCalendar struct {
+	loc      *time.Location
+	holidays map[string]bool
+}
+
+func (f *fakeCalendar) EnsureYear(ctx context.Context, year int) error { return nil }
+
+func (f *fakeCalendar) ListRange(ctx context.Context, fromDate, toDate time.Time) ([]WorkCalendarDay, error) {
+	var days []WorkCalendarDay
+	from := time.Date(fromDate.Year(), fromDate.Month(), fromDate.Day(), 0, 0, 0, 0, f.loc)
+	last := toDate.Format("2006-01-02")
+	for d := from; d.Format("2006-01-02") <= last; d = d.AddDate(0, 0, 1) {
+		day := WorkCalendarDay{WorkDate: d}
+		if f.isWorkingDay(d.Format("2006-01-02")) {
+			day.IsWorkingDay = true
+			day.WorkUnit = 1
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func (f *fakeCalendar) isWorkingDay(date string) bool {
+	d, _ := time.ParseInLocation("2006-01-02", date, f.loc)
+	wd := d.Weekday()
+	return wd != time.Saturday && wd != time.Sunday && !f.holidays[date]
+}
+
+// fakeAttendance sums sessions the way attendance.Repo does: CLOSED sessions on working days
+type fakeAttendance struct {
+	cal      *fakeCalendar
+	sessions []attendance.Session
+}
+
+func (f *fakeAttendance) counts(s *attendance.Session, from, to string) bool {
+	date := s.WorkDate.Format("2006-01-02")
+	return s.Status == "CLOSED" && date >= from && date <= to && f.cal.isWorkingDay(date)
+}
+
+func (f *fakeAttendance) SumDayUnitByRange(ctx context.Context, userID uint, from, to string) (float64, error) {
+	total := 0.0
+	for i := range f.sessions {
+		if f.sessions[i].UserID == userID && f.counts(&f.sessions[i], from, to) {
+			total += float64(f.sessions[i].DayUnit)
+		}
+	}
+	return total, nil
+}
+
+func (f *fakeAttendance) SumDayUnitByUserDay(ctx context.Context, from, to string) ([]attendance.UserDayUnit, error) {
+	var rows []attendance.UserDayUnit
+	for i := range f.sessions {
+		if f.counts(&f.sessions[i], from, to) {
+			rows = append(rows, attendance.UserDayUnit{
+				UserID:   f.sessions[i].UserID,
+				WorkDate: f.sessions[i].WorkDate.Format("2006-01-02"),
+				DayUnit:  float64(f.sessions[i].DayUnit),
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeAttendance) FindByUserDate(userID uint, workDate string) (*attendance.Session, error) {
+	return nil, nil
+}
+
+func (f *fakeAttendance) GetDatesWithoutAttendance(ctx context.Context, userID uint, fromDate, toDate time.Time) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (f *fakeAttendance) GetSessionsWithDayUnitZero(ctx context.Context, fromDate, toDate time.Time) ([]attendance.Session, error) {
+	return nil, nil
+}
+
+func (f *fakeAttendance) ListByUserDateRange(userID uint, from, to string) ([]attendance.Session, error) {
+	return nil, nil
+}
+
+func (f *fakeAttendance) ListClosedByRange(ctx context.Context, from, to string) ([]attendance.Session, error) {
+	return nil, nil
+}
+
+func (f *fakeAttendance) ListByRange(ctx context.Context, from, to string) ([]attendance.Session, error) {
+	return nil, nil
+}
+
+func (f *fakeAttendance) GetYearMonthWithAttendance(ctx context.Context) ([]struct {
+	Year  int
+	Month int
+}, error) {
+	return nil, nil
+}
+
+// fakeSummaryRepo holds the birthday policy, earlier months' birthday usage and comp-off balances
+type fakeSummaryRepo struct {
+	policy  *BirthdayPolicy
+	used    []MonthlySummary
+	compOff map[uint]float64
+}
+
+func (f *fakeSummaryRepo) GetBirthdayPolicy(ctx context.Context) (*BirthdayPolicy, error) {
+	return f.policy, nil
+}
+
+func (f *fakeSummaryRepo) SumBirthdayUsed(ctx context.Context, userID uint, fromYM, toYM int) (float64, error) {
+	total := 0.0
+	for _, r := range f.used {
+		if ym := r.Year*100 + r.Month; r.UserID == userID && ym >= fromYM && ym < toYM {
+			total += r.BirthdayUsedUnits
+		}
+	}
+	return total, nil
+}
+
+func (f *fakeSummaryRepo) ListBirthdayUsed(ctx context.Context, fromYM, toYM int) ([]MonthlySummary, error) {
+	var rows []MonthlySummary
+	for _, r := range f.used {
+		if ym := r.Year*100 + r.Month; r.BirthdayUsedUnits > 0 && ym >= fromYM && ym < toYM {
+			rows = append(rows, r)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeSummaryRepo) ListCompOffAvailable(ctx context.Context, userID *uint, year, month int, asOf, until time.Time) (map[uint]float64, error) {
+	res := make(map[uint]float64)
+	for id, units := range f.compOff {
+		if userID == nil || *userID == id {
+			res[id] = units
+		}
+	}
+	return res, nil
+}
+
+type fakeUsers map[uint]user.User
+
+func (f fakeUsers) GetByID(ctx context.Context, id uint) (*user.User, error) {
+	u, ok := f[id]
+	if !ok {
+		return nil, fmt.Errorf("user %d not found", id)
+	}
+	return &u, nil
+}
+
+type fakeSettlements map[uint]float64
+
+func (f fakeSettlements) ListPaidLeaveBefore(ctx context.Context, userIDs []uint) (map[uint]float64, error) {
+	res := make(map[uint]float64)
+	for _, id := range userIDs {
+		if b, ok := f[id]; ok {
+			res[id] = b
+		}
+	}
+	return res, nil
+}
+
+// summaryCase is one month of fixture data projected both ways
+type summaryCase struct {
+	name     string
+	year     int
+	month    int
+	users    []user.User
+	sessions []attendance.Session
+	repo     *fakeSummaryRepo
+	settled  fakeSettlements // paid leave before offboarding
+	check    func(t *testing.T, s *MonthlySummary)
+}
+
+// workedSessions creates one CLOSED full-day session per working day of the month up to and
+// including `until`, except the days listed in absent
+func workedSessions(cal *fakeCalendar, userID uint, year, month, until int, absent ...int) []attendance.Session {
+	skip := make(map[int]bool, len(absent))
+	for _, d := range absent {
+		skip[d] = true
+	}
+	var sessions []attendance.Session
+	for day := 1; day <= until; day++ {
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, cal.loc)
+		if date.Month() != time.Month(month) || skip[day] || !cal.isWorkingDay(date.Format("2006-01-02")) {
+			continue
+		}
+		sessions = append(sessions, attendance.Session{UserID: userID, WorkDate: date, Status: "CLOSED", DayUnit: 1})
+	}
+	return sessions
+}
+
+func TestProjectMonthlySummariesMatchesPerUser(t *testing.T) {
+	loc, err := time.LoadLocation(testTZ)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", testTZ, err)
+	}
+	cal := &fakeCalendar{loc: loc, holidays: map[string]bool{"2026-03-02": true}}
+	date := func(y, m, d int) *time.Time {
+		t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, loc)
+		return &t
+	}
+	now := time.Now().In(loc)
+
+	var cases []summaryCase
+
+	// Birthday leave limited to a ±3 days window, and a quarter window partly used in February
+	{
+		daysPolicy := &BirthdayPolicy{Days: 1, Window: BirthdayWindowDays, WindowDays: 3, IncludeProbation: true}
+		quarterPolicy := &BirthdayPolicy{Days: 2, Window: BirthdayWindowQuarter, IncludeProbation: true}
+		users := []user.User{
+			{ID: 1, Status: "active", Birthday: date(1990, 3, 12), PaidLeave: 1},
+			{ID: 2, Status: "active", Birthday: date(1991, 3, 25), PaidLeave: 0},
+			{ID: 3, Status: "active", Birthday: date(1992, 3, 30), PaidLeave: 2}, // window spills into April
+		}
+		var sessions []attendance.Session
+		sessions = append(sessions, workedSessions(cal, 1, 2026, 3, 31, 4, 10, 11)...)
+		sessions = append(sessions, workedSessions(cal, 2, 2026, 3, 31, 5, 24)...)
+		sessions = append(sessions, workedSessions(cal, 3, 2026, 3, 31, 27, 31)...)
+		cases = append(cases,
+			summaryCase{name: "birthday window days", year: 2026, month: 3, users: users, sessions: sessions,
+				repo: &fakeSummaryRepo{policy: daysPolicy},
+				check: func(t *testing.T, s *MonthlySummary) {
+					if s.UserID == 1 && s.BirthdayUsedUnits != 1 {
+						t.Errorf("user 1: birthday used %v, want 1", s.BirthdayUsedUnits)
+					}
+				}},
+			summaryCase{name: "birthday window quarter", year: 2026, month: 3, users: users, sessions: sessions,
+				repo: &fakeSummaryRepo{policy: quarterPolicy, used: []MonthlySummary{
+					{UserID: 1, Year: 2026, Month: 2, BirthdayUsedUnits: 1},
+					{UserID: 2, Year: 2026, Month: 1, BirthdayUsedUnits: 2},
+				}},
+				check: func(t *testing.T, s *MonthlySummary) {
+					if s.UserID == 2 && s.BirthdayUsedUnits != 0 {
+						t.Errorf("user 2: birthday used %v, want 0 (window used up)", s.BirthdayUsedUnits)
+					}
+				}},
+		)
+	}
+
+	// Comp-off covers absences before paid leave
+	{
+		users := []user.User{
+			{ID: 1, Status: "active", PaidLeave: 0.5},
+			{ID: 2, Status: "active", PaidLeave: 3},
+			{ID: 3, Status: "active"},
+		}
+		var sessions []attendance.Session
+		sessions = append(sessions, workedSessions(cal, 1, 2026, 3, 31, 3, 4, 5)...)
+		sessions = append(sessions, workedSessions(cal, 2, 2026, 3, 31, 17)...)
+		sessions = append(sessions, workedSessions(cal, 3, 2026, 3, 31, 9, 20)...)
+		cases = append(cases, summaryCase{name: "comp-off", year: 2026, month: 3, users: users, sessions: sessions,
+			repo: &fakeSummaryRepo{compOff: map[uint]float64{1: 2, 2: 0.5}},
+			check: func(t *testing.T, s *MonthlySummary) {
+				if s.UserID == 1 && (s.CompOffUsedUnits != 2 || s.PaidUsedUnits != 0.5 || s.UnpaidUnits != 0.5) {
+					t.Errorf("user 1: comp-off %v paid %v unpaid %v, want 2/0.5/0.5", s.CompOffUsedUnits, s.PaidUsedUnits, s.UnpaidUnits)
+				}
+			}})
+	}
+
+	// The period of an offboarded user ends on the termination date, and their zeroed balance is
+	// replaced by the one the settlement started from
+	{
+		users := []user.User{
+			{ID: 1, Status: "disabled", TerminationDate: date(2026, 3, 17), PaidLeave: 0},
+			{ID: 2, Status: "active", PaidLeave: 1},
+		}
+		var sessions []attendance.Session
+		sessions = append(sessions, workedSessions(cal, 1, 2026, 3, 16, 9)...)
+		sessions = append(sessions, workedSessions(cal, 2, 2026, 3, 31, 9)...)
+		cases = append(cases, summaryCase{name: "termination cap", year: 2026, month: 3, users: users, sessions: sessions,
+			repo: &fakeSummaryRepo{}, settled: fakeSettlements{1: 1},
+			check: func(t *testing.T, s *MonthlySummary) {
+				// 3-17 March: 11 weekdays minus the 2 March holiday
+				if s.UserID == 1 && (s.ExpectedUnits != 11 || s.PaidUsedUnits != 1) {
+					t.Errorf("user 1: expected %v paid %v, want 11/1", s.ExpectedUnits, s.PaidUsedUnits)
+				}
+			}})
+	}
+
+	// The current month is only counted up to today
+	{
+		year, month, today := now.Year(), int(now.Month()), now.Day()
+		users := []user.User{
+			{ID: 1, Status: "active", PaidLeave: 1},
+			{ID: 2, Status: "active", Birthday: date(1990, month, today), PaidLeave: 0},
+		}
+		var sessions []attendance.Session
+		sessions = append(sessions, workedSessions(cal, 1, year, month, today-1, 1)...)
+		sessions = append(sessions, workedSessions(cal, 2, year, month, today, 2, 3)...)
+		// Sessions after today can't exist yet, but must not count if they did
+		sessions = append(sessions, workedSessions(cal, 1, year, month, 31)...)
+		cases = append(cases, summaryCase{name: "current month cut-off", year: year, month: month, users: users, sessions: sessions,
+			repo: &fakeSummaryRepo{}})
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := make(fakeUsers, len(tc.users))
+			for _, u := range tc.users {
+				users[u.ID] = u
+			}
+			svc := &Service{
+				cfg:            &config.Config{AppTZ: testTZ},
+				attendanceRepo: &fakeAttendance{cal: cal, sessions: tc.sessions},
+				workCalRepo:    cal,
+				logger:         zap.NewNop(),
+				summaryRepo:    tc.repo,
+				users:          users,
+				settlements:    tc.settled,
+			}
+
+			batch, err := svc.projectMonthlySummaries(ctx, tc.users, tc.year, tc.month)
+			if err != nil {
+				t.Fatalf("projectMonthlySummaries: %v", err)
+			}
+			if len(batch) != len(tc.users) {
+				t.Fatalf("got %d batch summaries, want %d", len(batch), len(tc.users))
+			}
+			for i := range batch {
+				b := &batch[i]
+				p, err := svc.projectMonthlySummary(ctx, b.UserID, tc.year, tc.month)
+				if err != nil {
+					t.Fatalf("projectMonthlySummary(%d): %v", b.UserID, err)
+				}
+				if b.Year != p.Year || b.Month != p.Month {
+					t.Errorf("user %d: batch %d-%02d, per user %d-%02d", b.UserID, b.Year, b.Month, p.Year, p.Month)
+				}
+				if fields := diffSummaries(p, b); len(fields) > 0 {
+					t.Errorf("user %d: batch differs from per user in %v\nper user: %+v\nbatch:    %+v", b.UserID, fields, *p, *b)
+				}
+				if tc.check != nil {
+					tc.check(t, p)
+				}
+			}
+		})
+	}
+}
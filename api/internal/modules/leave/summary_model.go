@@ -3,19 +3,20 @@ package leave
 import "time"
 
 type MonthlySummary struct {
-	UserID        uint    `gorm:"primaryKey"`
-	Year          int     `gorm:"primaryKey"`
-	Month         int     `gorm:"primaryKey"`
-	ExpectedUnits float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
-	WorkedUnits   float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
-	MissingUnits  float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
-	PaidUsedUnits float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
-	UnpaidUnits   float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
-	IsBirthday    bool    `gorm:"type:tinyint(1);not null;default:0" json:"isBirthday"`
-	UpdatedAt     time.Time `gorm:"not null"`
+	UserID            uint      `gorm:"primaryKey"`
+	Year              int       `gorm:"primaryKey"`
+	Month             int       `gorm:"primaryKey"`
+	ExpectedUnits     float64   `gorm:"type:decimal(6,2);not null;default:0.0"`
+	WorkedUnits       float64   `gorm:"type:decimal(6,2);not null;default:0.0"`
+	MissingUnits      float64   `gorm:"type:decimal(6,2);not null;default:0.0"`
+	BirthdayUsedUnits float64   `gorm:"type:decimal(6,2);not null;default:0.0"` // Nghỉ sinh nhật đã dùng (không trừ phép năm)
+	PaidUsedUnits     float64   `gorm:"type:decimal(6,2);not null;default:0.0"`
+	CompOffUsedUnits  float64   `gorm:"type:decimal(6,2);not null;default:0.0"` // Nghỉ bù đã dùng (trừ trước phép năm)
+	UnpaidUnits       float64   `gorm:"type:decimal(6,2);not null;default:0.0"`
+	IsBirthday        bool      `gorm:"type:tinyint(1);not null;default:0" json:"isBirthday"` // Birthday window overlaps this month
+	UpdatedAt         time.Time `gorm:"not null"`
 }
 
 func (MonthlySummary) TableName() string {
 	return "leave_monthly_summary"
 }
-
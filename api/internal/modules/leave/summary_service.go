@@ -4,19 +4,29 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"time-attendance-be/internal/modules/user"
 )
 
 // ComputeMonthlySummary computes projected summary for a user/month (realtime) and upserts to DB.
-func (s *Service) ComputeMonthlySummary(ctx context.Context, userID uint, year, month int) (*MonthlySummary, error) {
-	if s.workCalRepo == nil || s.attendanceRepo == nil {
-		return nil, fmt.Errorf("work calendar or attendance repo not set")
+// trigger is recorded on the snapshot version created when the figures changed.
+// A closed month keeps its stored summary (see persistSummaries), which is returned instead.
+func (s *Service) ComputeMonthlySummary(ctx context.Context, userID uint, year, month int, trigger string) (*MonthlySummary, error) {
+	summary, err := s.projectMonthlySummary(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
 	}
 
-	// Ensure calendar exists
-	if err := s.workCalRepo.EnsureYear(ctx, year); err != nil {
-		return nil, fmt.Errorf("ensure calendar year: %w", err)
+	stored, err := s.persistSummaries(ctx, year, month, []MonthlySummary{*summary}, trigger)
+	if err != nil {
+		return nil, err
 	}
+	return &stored[0], nil
+}
 
+// summaryPeriod returns the first day of the month and the last day counted:
+// today for the current month (realtime), otherwise the month end.
+func (s *Service) summaryPeriod(year, month int) (time.Time, time.Time) {
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.cfg.TimeLocation())
 	endDate := startDate.AddDate(0, 1, -1)
 
@@ -36,18 +46,66 @@ func (s *Service) ComputeMonthlySummary(ctx context.Context, userID uint, year,
 			calcEndDate = endDate
 		}
 	}
+	return startDate, calcEndDate
+}
 
-	// Fetch calendar range (only up to today if current month)
-	calDays, err := s.workCalRepo.ListRange(ctx, startDate, calcEndDate)
+// capAtTermination ends a summary period on the user's last working day, so days after
+// offboarding are neither expected nor charged to leave
+func capAtTermination(u *user.User, end time.Time) time.Time {
+	if u.TerminationDate == nil {
+		return end
+	}
+	ty, tm, td := u.TerminationDate.Date()
+	last := time.Date(ty, tm, td, 23, 59, 59, 0, end.Location())
+	if last.Before(end) {
+		return last
+	}
+	return end
+}
+
+// ProjectMonthlySummary is ComputeMonthlySummary without persisting the summary (read paths)
+func (s *Service) ProjectMonthlySummary(ctx context.Context, userID uint, year, month int) (*MonthlySummary, error) {
+	return s.projectMonthlySummary(ctx, userID, year, month)
+}
+
+// projectMonthlySummary computes the summary for a user/month without persisting it
+func (s *Service) projectMonthlySummary(ctx context.Context, userID uint, year, month int) (*MonthlySummary, error) {
+	in, err := s.loadSummaryInputs(ctx, userID, year, month)
 	if err != nil {
-		return nil, fmt.Errorf("list calendar: %w", err)
+		return nil, err
 	}
+	return buildMonthlySummary(in)
+}
 
-	expected := 0.0
-	for _, d := range calDays {
-		if d.IsWorkingDay && d.WorkUnit > 0 {
-			expected += d.WorkUnit
-		}
+// loadSummaryInputs loads what a single user's monthly summary is derived from
+func (s *Service) loadSummaryInputs(ctx context.Context, userID uint, year, month int) (summaryInputs, error) {
+	// Paid available (snapshot) from user
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return summaryInputs{}, fmt.Errorf("get user: %w", err)
+	}
+	return s.userSummaryInputs(ctx, user, year, month)
+}
+
+// userSummaryInputs loads the summary inputs of a user as given (which may not be saved yet)
+func (s *Service) userSummaryInputs(ctx context.Context, u *user.User, year, month int) (summaryInputs, error) {
+	if s.workCalRepo == nil || s.attendanceRepo == nil {
+		return summaryInputs{}, fmt.Errorf("work calendar or attendance repo not set")
+	}
+
+	// Ensure calendar exists
+	if err := s.workCalRepo.EnsureYear(ctx, year); err != nil {
+		return summaryInputs{}, fmt.Errorf("ensure calendar year: %w", err)
+	}
+
+	userID := u.ID
+	startDate, calcEndDate := s.summaryPeriod(year, month)
+	calcEndDate = capAtTermination(u, calcEndDate)
+
+	// Fetch calendar range (only up to today if current month)
+	calDays, err := s.workCalRepo.ListRange(ctx, startDate, calcEndDate)
+	if err != nil {
+		return summaryInputs{}, fmt.Errorf("list calendar: %w", err)
 	}
 
 	// Worked units (only CLOSED sessions) - also only up to today if current month
@@ -55,52 +113,171 @@ func (s *Service) ComputeMonthlySummary(ctx context.Context, userID uint, year,
 	workEndStr := calcEndDate.Format("2006-01-02")
 	worked, err := s.attendanceRepo.SumDayUnitByRange(ctx, userID, workStr, workEndStr)
 	if err != nil {
-		return nil, fmt.Errorf("sum attendance: %w", err)
+		return summaryInputs{}, fmt.Errorf("sum attendance: %w", err)
+	}
+
+	balances, err := s.settledBalances(ctx, []user.User{*u})
+	if err != nil {
+		return summaryInputs{}, fmt.Errorf("settled balance: %w", err)
+	}
+	paidLeave, ok := balances[userID]
+	if !ok {
+		paidLeave = u.PaidLeave
 	}
 
+	// Birthday leave window overlapping this month (per birthday policy)
+	policy, err := s.GetBirthdayPolicy(ctx)
+	if err != nil {
+		return summaryInputs{}, fmt.Errorf("birthday policy: %w", err)
+	}
+	bw, err := s.birthdayWindowFor(ctx, policy, u, year, month)
+	if err != nil {
+		return summaryInputs{}, fmt.Errorf("birthday window: %w", err)
+	}
+
+	return summaryInputs{
+		UserID:    userID,
+		Year:      year,
+		Month:     month,
+		Start:     startDate,
+		End:       calcEndDate,
+		CalDays:   calDays,
+		Worked:    worked,
+		PaidLeave: paidLeave,
+		Window:    bw,
+		WorkedBetween: func(from, to time.Time) (float64, error) {
+			return s.attendanceRepo.SumDayUnitByRange(ctx, userID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+		},
+		CompOffAvailable: func() (float64, error) {
+			return s.compOffAvailable(ctx, userID, startDate, calcEndDate)
+		},
+	}, nil
+}
+
+// settledBalances returns the paid leave balance the settlement of each offboarded user among
+// users started from. Their users.paid_leave is zeroed at offboarding, so summaries of their
+// last months use this balance instead and recompute to the settled figures.
+func (s *Service) settledBalances(ctx context.Context, users []user.User) (map[uint]float64, error) {
+	if s.settlements == nil {
+		return nil, nil
+	}
+	var ids []uint
+	for i := range users {
+		if users[i].TerminationDate != nil {
+			ids = append(ids, users[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return s.settlements.ListPaidLeaveBefore(ctx, ids)
+}
+
+// summaryInputs is everything a monthly summary is derived from. The per-user and the batch
+// computation (summary_batch_service.go) only differ in how they load these.
+type summaryInputs struct {
+	UserID     uint
+	Year       int
+	Month      int
+	Start, End time.Time // counted period, see summaryPeriod
+	CalDays    []WorkCalendarDay
+	Worked     float64 // closed-session units on working days in [Start, End]
+	PaidLeave  float64 // users.paid_leave snapshot (the settled balance for offboarded users)
+	Window     *birthdayWindow
+
+	// WorkedBetween returns closed-session units on working days in [from, to]
+	WorkedBetween func(from, to time.Time) (float64, error)
+	// CompOffAvailable returns the usable comp-off units for the period
+	CompOffAvailable func() (float64, error)
+
+	// Trace, when set, records the intermediate values of the allocation (see ExplainMonthlySummary)
+	Trace *SummaryTrace
+}
+
+// buildMonthlySummary allocates missing units to birthday leave, comp-off, paid and unpaid leave
+func buildMonthlySummary(in summaryInputs) (*MonthlySummary, error) {
+	expected := 0.0
+	for _, d := range in.CalDays {
+		if d.IsWorkingDay && d.WorkUnit > 0 {
+			expected += d.WorkUnit
+		}
+	}
+
+	worked := in.Worked
 	missing := expected - worked
 	if missing < 0 {
 		missing = 0
 	}
 
-	// Paid available (snapshot) from user
-	user, err := s.userRepo.GetByID(ctx, userID)
-	if err != nil {
-		return nil, fmt.Errorf("get user: %w", err)
-	}
-	paidAvailable := user.PaidLeave
+	paidAvailable := in.PaidLeave
 	if paidAvailable < 0 {
 		paidAvailable = 0
 	}
 
-	// Check if this is birthday month
-	isBirthdayMonth := false
-	if user.Birthday != nil {
-		birthdayMonth := int(user.Birthday.Month())
-		isBirthdayMonth = (birthdayMonth == month)
-	}
-
-	// Calculate paid used: prioritize birthday leave if in birthday month
-	// Logic: missing - birthday_leave_used - paid_used = unpaid
-	// Birthday leave is FREE (doesn't deduct from paid_leave)
-	// So: paid_used should NOT include birthday leave
+	// Logic: missing - birthday_used - comp_off_used - paid_used = unpaid
+	// Birthday leave is FREE (doesn't deduct from paid_leave) and is reported separately
+	bw := in.Window
+	birthdayUsed := 0.0
 	paidUsed := 0.0
+	compOffUsed := 0.0
 	unpaid := missing
 
 	if missing > 0 {
-		// If birthday month: user has 1 additional day of birthday leave (FREE)
-		// This birthday leave can only be used in birthday month
-		// First, use birthday leave if available
+		// First, use birthday leave - only for units missing on days inside the window
 		remainingMissing := missing
-		if isBirthdayMonth && remainingMissing > 0 {
-			// Use birthday leave first (up to 1 day)
-			birthdayLeaveUsed := 0.0
-			if remainingMissing >= 1.0 {
-				birthdayLeaveUsed = 1.0
-			} else {
-				birthdayLeaveUsed = remainingMissing
+		if bw != nil && bw.Available > 0 {
+			from, to := bw.Start, bw.End
+			if from.Before(in.Start) {
+				from = in.Start
+			}
+			if to.After(in.End) {
+				to = in.End
 			}
-			remainingMissing = remainingMissing - birthdayLeaveUsed
+			if !from.After(to) {
+				windowExpected := 0.0
+				for _, d := range in.CalDays {
+					if d.IsWorkingDay && d.WorkUnit > 0 && bw.Contains(d.WorkDate) {
+						windowExpected += d.WorkUnit
+					}
+				}
+				windowWorked, err := in.WorkedBetween(from, to)
+				if err != nil {
+					return nil, fmt.Errorf("sum attendance in birthday window: %w", err)
+				}
+				birthdayUsed = windowExpected - windowWorked
+				if in.Trace != nil {
+					in.Trace.Birthday.CountedFrom = &from
+					in.Trace.Birthday.CountedTo = &to
+					in.Trace.Birthday.WindowExpectedUnits = windowExpected
+					in.Trace.Birthday.WindowWorkedUnits = windowWorked
+				}
+				if birthdayUsed > bw.Available {
+					birthdayUsed = bw.Available
+				}
+				if birthdayUsed > remainingMissing {
+					birthdayUsed = remainingMissing
+				}
+				if birthdayUsed < 0 {
+					birthdayUsed = 0
+				}
+			}
+			remainingMissing = remainingMissing - birthdayUsed
+		}
+
+		// Next, use comp-off credits (earned by working on non-working days) before paid leave
+		if remainingMissing > 0 {
+			compOffAvailable, err := in.CompOffAvailable()
+			if err != nil {
+				return nil, fmt.Errorf("comp-off balance: %w", err)
+			}
+			if in.Trace != nil {
+				in.Trace.CompOffAvailable = compOffAvailable
+			}
+			compOffUsed = compOffAvailable
+			if compOffUsed > remainingMissing {
+				compOffUsed = remainingMissing
+			}
+			remainingMissing = remainingMissing - compOffUsed
 		}
 
 		// Then, use regular paid leave for remaining missing days
@@ -115,27 +292,38 @@ func (s *Service) ComputeMonthlySummary(ctx context.Context, userID uint, year,
 				unpaid = remainingMissing - paidUsed
 			}
 		} else {
-			// All missing days covered by birthday leave
+			// All missing days covered by birthday leave / comp-off
 			paidUsed = 0.0
 			unpaid = 0.0
 		}
 	}
 
+	if in.Trace != nil {
+		in.Trace.ExpectedUnits = expected
+		in.Trace.WorkedUnits = worked
+		in.Trace.PaidAvailable = paidAvailable
+		in.Trace.Split = []SplitStep{
+			{Step: SplitMissing, Units: missing, Remaining: missing},
+			{Step: SplitBirthday, Units: birthdayUsed, Remaining: missing - birthdayUsed},
+			{Step: SplitCompOff, Units: compOffUsed, Remaining: missing - birthdayUsed - compOffUsed},
+			{Step: SplitPaid, Units: paidUsed, Remaining: unpaid},
+			{Step: SplitUnpaid, Units: unpaid, Remaining: 0},
+		}
+	}
+
 	summary := &MonthlySummary{
-		UserID:        userID,
-		Year:          year,
-		Month:         month,
-		ExpectedUnits: expected,
-		WorkedUnits:   worked,
-		MissingUnits:  missing,
-		PaidUsedUnits: paidUsed,
-		UnpaidUnits:   unpaid,
-		IsBirthday:    isBirthdayMonth,
-		UpdatedAt:     time.Now(),
-	}
-
-	if err := s.repo.UpsertMonthlySummary(ctx, summary); err != nil {
-		return nil, fmt.Errorf("upsert summary: %w", err)
+		UserID:            in.UserID,
+		Year:              in.Year,
+		Month:             in.Month,
+		ExpectedUnits:     expected,
+		WorkedUnits:       worked,
+		MissingUnits:      missing,
+		BirthdayUsedUnits: birthdayUsed,
+		PaidUsedUnits:     paidUsed,
+		CompOffUsedUnits:  compOffUsed,
+		UnpaidUnits:       unpaid,
+		IsBirthday:        bw != nil,
+		UpdatedAt:         time.Now(),
 	}
 
 	return summary, nil
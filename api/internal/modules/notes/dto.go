@@ -19,3 +19,12 @@ type PutMeResponse struct {
 	UpdatedAt  string `json:"updatedAt"`
 }
 
+// GET /api/v1/manager/notes?from=&to=&userId=&departmentId=
+
+type NoteItem struct {
+	UserID    uint   `json:"userId"`
+	UserName  string `json:"userName"`
+	WorkDate  string `json:"workDate"`
+	Content   string `json:"content"`
+	UpdatedAt string `json:"updatedAt"`
+}
@@ -1,6 +1,7 @@
 package notes
 
 import (
+	"strconv"
 	"time"
 
 	"time-attendance-be/internal/authx"
@@ -67,3 +68,50 @@ func (h *Handler) PutMe(c *fiber.Ctx) error {
 	})
 }
 
+// GET /api/v1/manager/notes?from=YYYY-MM-DD&to=YYYY-MM-DD&userId=&departmentId=
+// Daily notes of the departments the user manages
+func (h *Handler) ListManager(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	filter := ListFilter{From: c.Query("from"), To: c.Query("to")}
+	if v := c.Query("userId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return response.Validation("invalid userId", nil)
+		}
+		uid := uint(id)
+		filter.UserID = &uid
+	}
+	if v := c.Query("departmentId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return response.Validation("invalid departmentId", nil)
+		}
+		did := uint(id)
+		filter.DepartmentID = &did
+	}
+	ids, err := a.ManagerScope().Narrow(filter.DepartmentID)
+	if err != nil {
+		return err
+	}
+	filter.DepartmentIDs = ids
+
+	rows, err := h.svc.List(c.Context(), filter)
+	if err != nil {
+		return err
+	}
+	items := make([]NoteItem, len(rows))
+	for i, r := range rows {
+		items[i] = NoteItem{
+			UserID:    r.UserID,
+			UserName:  r.UserName,
+			WorkDate:  r.WorkDate.Format("2006-01-02"),
+			Content:   r.Content,
+			UpdatedAt: r.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+	return response.OK(c, items)
+}
@@ -34,3 +34,40 @@ func (r *Repo) Save(ctx context.Context, note *DailyNote) error {
 	return r.db.WithContext(ctx).Save(note).Error
 }
 
+// ListFilter selects notes of a date range
+type ListFilter struct {
+	From          string
+	To            string
+	UserID        *uint
+	DepartmentID  *uint
+	DepartmentIDs []uint // when not nil, only these departments (department-scoped access)
+}
+
+// NoteRow is a note with its author
+type NoteRow struct {
+	DailyNote
+	UserName string
+}
+
+// List returns non-empty notes matching the filter, newest day first
+func (r *Repo) List(ctx context.Context, filter ListFilter) ([]NoteRow, error) {
+	query := r.db.WithContext(ctx).
+		Table("daily_notes AS n").
+		Select("n.*, u.name AS user_name").
+		Joins("INNER JOIN users u ON n.user_id = u.id").
+		Where("n.work_date BETWEEN ? AND ? AND n.content <> ''", filter.From, filter.To)
+
+	if filter.UserID != nil {
+		query = query.Where("n.user_id = ?", *filter.UserID)
+	}
+	if filter.DepartmentID != nil {
+		query = query.Where("u.department_id = ?", *filter.DepartmentID)
+	}
+	if filter.DepartmentIDs != nil {
+		query = query.Where("u.department_id IN ?", filter.DepartmentIDs)
+	}
+
+	var rows []NoteRow
+	err := query.Order("n.work_date DESC, u.name ASC").Scan(&rows).Error
+	return rows, err
+}
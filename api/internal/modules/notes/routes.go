@@ -12,3 +12,6 @@ func (m *Module) RegisterMe(v1 fiber.Router, auth fiber.Handler) {
 	g.Put("/me", m.h.PutMe)
 }
 
+func (m *Module) RegisterManager(mgr fiber.Router) {
+	mgr.Get("/notes", m.h.ListManager)
+}
@@ -54,3 +54,30 @@ func (s *Service) PutMe(ctx context.Context, userID uint, dateStr string, conten
 	return note, nil
 }
 
+// maxListDays caps the date range of one note listing
+const maxListDays = 62
+
+// List returns the notes of a date range (YYYY-MM-DD, inclusive)
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]NoteRow, error) {
+	loc := s.cfg.TimeLocation()
+	from, err := time.ParseInLocation("2006-01-02", filter.From, loc)
+	if err != nil {
+		return nil, response.Validation("Invalid from date", nil)
+	}
+	to, err := time.ParseInLocation("2006-01-02", filter.To, loc)
+	if err != nil {
+		return nil, response.Validation("Invalid to date", nil)
+	}
+	if to.Before(from) {
+		return nil, response.Validation("to must not be before from", nil)
+	}
+	if to.Sub(from) > maxListDays*24*time.Hour {
+		return nil, response.Validation("Range must not exceed 62 days", nil)
+	}
+
+	rows, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,60 @@
+package offboarding
+
+import "time"
+
+type OffboardRequest struct {
+	TerminationDate string `json:"terminationDate"` // YYYY-MM-DD, last working day
+	Reason          string `json:"reason"`
+}
+
+type SettlementResponse struct {
+	ID                uint    `json:"id"`
+	UserID            uint    `json:"userId"`
+	TerminationDate   string  `json:"terminationDate"`
+	Year              int     `json:"year"`
+	Month             int     `json:"month"`
+	ExpectedUnits     float64 `json:"expectedUnits"`
+	WorkedUnits       float64 `json:"workedUnits"`
+	PaidUsedUnits     float64 `json:"paidUsedUnits"`
+	CompOffUsedUnits  float64 `json:"compOffUsedUnits"`
+	UnpaidUnits       float64 `json:"unpaidUnits"`
+	PaidLeaveBefore   float64 `json:"paidLeaveBefore"`
+	AccrualAdjustment float64 `json:"accrualAdjustment"`
+	SettlementDays    float64 `json:"settlementDays"` // > 0 pay out, < 0 deduct
+	PayoutDays        float64 `json:"payoutDays"`
+	DeductDays        float64 `json:"deductDays"`
+	ClosedSessions    int     `json:"closedSessions"`
+	RevokedSessions   int     `json:"revokedSessions"`
+	Reason            *string `json:"reason"`
+	ProcessedBy       uint    `json:"processedBy"`
+	CreatedAt         string  `json:"createdAt"`
+}
+
+func toSettlementResponse(s *Settlement) SettlementResponse {
+	res := SettlementResponse{
+		ID:                s.ID,
+		UserID:            s.UserID,
+		TerminationDate:   s.TerminationDate.Format("2006-01-02"),
+		Year:              s.Year,
+		Month:             s.Month,
+		ExpectedUnits:     s.ExpectedUnits,
+		WorkedUnits:       s.WorkedUnits,
+		PaidUsedUnits:     s.PaidUsedUnits,
+		CompOffUsedUnits:  s.CompOffUsedUnits,
+		UnpaidUnits:       s.UnpaidUnits,
+		PaidLeaveBefore:   s.PaidLeaveBefore,
+		AccrualAdjustment: s.AccrualAdjustment,
+		SettlementDays:    s.SettlementDays,
+		ClosedSessions:    s.ClosedSessions,
+		RevokedSessions:   s.RevokedSessions,
+		Reason:            s.Reason,
+		ProcessedBy:       s.ProcessedBy,
+		CreatedAt:         s.CreatedAt.Format(time.RFC3339),
+	}
+	if s.SettlementDays > 0 {
+		res.PayoutDays = s.SettlementDays
+	} else {
+		res.DeductDays = -s.SettlementDays
+	}
+	return res
+}
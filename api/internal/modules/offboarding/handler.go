@@ -0,0 +1,105 @@
+package offboarding
+
+import (
+	"strconv"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handler struct {
+	svc      *Service
+	auditSvc *audit.Service
+}
+
+func NewHandler(svc *Service, auditSvc *audit.Service) *Handler {
+	return &Handler{svc: svc, auditSvc: auditSvc}
+}
+
+// POST /api/v1/admin/offboarding/:userId
+// Body: { "terminationDate": "2026-10-15", "reason": "Resigned" }
+func (h *Handler) Offboard(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	userID, err := strconv.ParseUint(c.Params("userId"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid userId", nil)
+	}
+	var req OffboardRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	res, err := h.svc.Offboard(c.Context(), adminUser.ID, uint(userID), OffboardInput{
+		TerminationDate: req.TerminationDate,
+		Reason:          req.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	settlement := toSettlementResponse(res.Settlement)
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"OFFBOARD",
+			"user",
+			c.Params("userId"),
+			user.ToUserResponse(res.Before),
+			fiber.Map{"user": user.ToUserResponse(res.After), "settlement": settlement},
+			req.Reason,
+		)
+	}
+
+	return response.Created(c, settlement)
+}
+
+// GET /api/v1/admin/offboarding/:userId
+func (h *Handler) Get(c *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Params("userId"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid userId", nil)
+	}
+	st, err := h.svc.Get(c.Context(), uint(userID))
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toSettlementResponse(st))
+}
+
+// GET /api/v1/admin/offboarding?year=&month=
+// Settlements for payroll, by final month
+func (h *Handler) List(c *fiber.Ctx) error {
+	year, month := 0, 0
+	if s := c.Query("year"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 2000 || v > 9999 {
+			return response.Validation("invalid year", nil)
+		}
+		year = v
+	}
+	if s := c.Query("month"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > 12 {
+			return response.Validation("invalid month", nil)
+		}
+		month = v
+	}
+
+	rows, err := h.svc.List(c.Context(), year, month)
+	if err != nil {
+		return err
+	}
+	res := make([]SettlementResponse, len(rows))
+	for i := range rows {
+		res[i] = toSettlementResponse(&rows[i])
+	}
+	return response.OK(c, res)
+}
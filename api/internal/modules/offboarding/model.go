@@ -0,0 +1,34 @@
+package offboarding
+
+import "time"
+
+// Settlement is the record of an offboarded user (table offboarding_settlements): the
+// final month's figures up to the termination date and the leave balance to settle.
+type Settlement struct {
+	ID              uint      `gorm:"primaryKey"`
+	UserID          uint      `gorm:"not null;uniqueIndex"`
+	TerminationDate time.Time `gorm:"type:date;not null"`
+	Year            int       `gorm:"not null;index:idx_settlement_month"` // final month
+	Month           int       `gorm:"not null;index:idx_settlement_month"`
+
+	// Final month, counted up to the termination date
+	ExpectedUnits    float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	WorkedUnits      float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	PaidUsedUnits    float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	CompOffUsedUnits float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	UnpaidUnits      float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+
+	PaidLeaveBefore   float64 `gorm:"type:decimal(6,2);not null;default:0.0"` // balance before the final month
+	AccrualAdjustment float64 `gorm:"type:decimal(6,2);not null;default:0.0"` // grants past the termination date taken back
+	SettlementDays    float64 `gorm:"type:decimal(6,2);not null;default:0.0"` // > 0 paid out, < 0 deducted from the final salary
+
+	ClosedSessions  int       `gorm:"not null;default:0"` // attendance sessions checked out on offboarding
+	RevokedSessions int       `gorm:"not null;default:0"` // sign-in sessions revoked
+	Reason          *string   `gorm:"type:varchar(255)"`
+	ProcessedBy     uint      `gorm:"not null"`
+	CreatedAt       time.Time `gorm:"not null"`
+}
+
+func (Settlement) TableName() string {
+	return "offboarding_settlements"
+}
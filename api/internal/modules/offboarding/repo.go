@@ -0,0 +1,67 @@
+package offboarding
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo { return &Repo{db: db} }
+
+// GetByUser returns the user's settlement, or nil if they were not offboarded
+func (r *Repo) GetByUser(ctx context.Context, userID uint) (*Settlement, error) {
+	var rows []Settlement
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// List returns the settlements of a final month (year 0 = all), newest first
+func (r *Repo) List(ctx context.Context, year, month int) ([]Settlement, error) {
+	q := r.db.WithContext(ctx).Model(&Settlement{})
+	if year > 0 {
+		q = q.Where("year = ?", year)
+		if month > 0 {
+			q = q.Where("month = ?", month)
+		}
+	}
+	var rows []Settlement
+	if err := q.Order("termination_date DESC, id DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ListPaidLeaveBefore returns the balance each user's settlement started from, for those of
+// userIDs who were offboarded
+func (r *Repo) ListPaidLeaveBefore(ctx context.Context, userIDs []uint) (map[uint]float64, error) {
+	var rows []Settlement
+	if err := r.db.WithContext(ctx).Select("user_id", "paid_leave_before").Where("user_id IN ?", userIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	res := make(map[uint]float64, len(rows))
+	for _, s := range rows {
+		res[s.UserID] = s.PaidLeaveBefore
+	}
+	return res, nil
+}
+
+func (r *Repo) Create(ctx context.Context, s *Settlement) error {
+	return r.db.WithContext(ctx).Create(s).Error
+}
+
+// Transaction runs fn with a repo and the *gorm.DB of one database transaction, so the other
+// modules' writes of an offboarding commit with the settlement
+func (r *Repo) Transaction(ctx context.Context, fn func(tx *Repo, db *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Repo{db: tx}, tx)
+	})
+}
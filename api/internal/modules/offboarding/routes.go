@@ -0,0 +1,21 @@
+package offboarding
+
+import (
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Module struct{ h *Handler }
+
+func NewModule(svc *Service, auditSvc *audit.Service) *Module {
+	return &Module{h: NewHandler(svc, auditSvc)}
+}
+
+func (m *Module) RegisterAdmin(admin fiber.Router) {
+	g := admin.Group("/offboarding", authx.Require(authx.PermUsersWrite))
+	g.Get("/", m.h.List)
+	g.Get("/:userId", m.h.Get)
+	g.Post("/:userId", m.h.Offboard)
+}
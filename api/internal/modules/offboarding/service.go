@@ -0,0 +1,214 @@
+package offboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/config"
+	"time-attendance-be/internal/modules/attendance"
+	"time-attendance-be/internal/modules/auth"
+	"time-attendance-be/internal/modules/leave"
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/pkg/response"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SessionRevoker signs a user out everywhere (implemented by auth.Service)
+type SessionRevoker interface {
+	RevokeUserSessions(ctx context.Context, userID uint, reason string) (int64, error)
+}
+
+type Service struct {
+	cfg      *config.Config
+	repo     *Repo
+	userRepo *user.Repo
+	attSvc   *attendance.Service
+	leaveSvc *leave.Service
+	sessions SessionRevoker
+	logger   *zap.Logger
+}
+
+func NewService(cfg *config.Config, repo *Repo, userRepo *user.Repo, attSvc *attendance.Service, leaveSvc *leave.Service, sessions SessionRevoker, logger *zap.Logger) *Service {
+	return &Service{
+		cfg:      cfg,
+		repo:     repo,
+		userRepo: userRepo,
+		attSvc:   attSvc,
+		leaveSvc: leaveSvc,
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+// OffboardInput is what HR enters when a user leaves
+type OffboardInput struct {
+	TerminationDate string // YYYY-MM-DD, the last working day
+	Reason          string
+}
+
+// Result is an offboarding: the user as they were and the settlement
+type Result struct {
+	Before     *user.User
+	After      *user.User
+	Settlement *Settlement
+}
+
+// Offboard ends a user's employment on the termination date:
+// - checks out open attendance sessions and revokes sign-in sessions
+// - computes the final month up to the termination date and settles the paid leave balance
+// - sets the termination date, disables the account and zeroes the balance
+// The termination date can't be in the future, nor in a month whose month-end leave
+// deduction has already run or whose timesheet is signed off. A user is offboarded once. Closing sessions and revoking
+// sign-ins are safe to repeat; the settlement, the user and the comp-off used by the final
+// month are written in one transaction, settlement first.
+func (s *Service) Offboard(ctx context.Context, adminID, userID uint, in OffboardInput) (*Result, error) {
+	if adminID == userID {
+		return nil, response.Validation("You cannot offboard yourself", nil)
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFound("User not found")
+		}
+		return nil, response.Internal(err)
+	}
+	existing, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if existing != nil {
+		return nil, response.Conflict("User has already been offboarded")
+	}
+
+	loc := s.cfg.TimeLocation()
+	termination, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(in.TerminationDate), loc)
+	if err != nil {
+		return nil, response.Validation("terminationDate must be YYYY-MM-DD", nil)
+	}
+	now := time.Now().In(loc)
+	if termination.After(now) {
+		return nil, response.Validation("The termination date cannot be in the future", nil)
+	}
+	if u.HireDate != nil && termination.Format("2006-01-02") < u.HireDate.Format("2006-01-02") {
+		return nil, response.Validation("The termination date is before the hire date", nil)
+	}
+	year, month := termination.Year(), int(termination.Month())
+	settled, err := s.leaveSvc.IsMonthSettled(ctx, year, month)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if settled {
+		return nil, response.Validation(fmt.Sprintf("The leave of %d-%02d has already been settled; choose a termination date in a later month", year, month), nil)
+	}
+	var reason *string
+	if r := strings.TrimSpace(in.Reason); r != "" {
+		if len(r) > 255 {
+			return nil, response.Validation("reason must be at most 255 characters", nil)
+		}
+		reason = &r
+	}
+	// The final month's figures change, so its timesheet must not be signed off
+	if err := s.attSvc.CheckMonthLock(ctx, userID, termination); err != nil {
+		if errors.Is(err, attendance.ErrMonthLocked) {
+			return nil, response.Conflict(err.Error())
+		}
+		return nil, response.Internal(err)
+	}
+
+	closed, err := s.attSvc.CloseOpenSessions(ctx, userID, "Offboarding")
+	if err != nil {
+		if errors.Is(err, attendance.ErrMonthLocked) {
+			return nil, response.Conflict(err.Error())
+		}
+		return nil, response.Internal(fmt.Errorf("close attendance sessions: %w", err))
+	}
+	var revoked int64
+	if s.sessions != nil {
+		if revoked, err = s.sessions.RevokeUserSessions(ctx, userID, auth.RevokeOffboarded); err != nil {
+			return nil, err
+		}
+	}
+
+	before := *u
+	u.TerminationDate = &termination
+	final, err := s.leaveSvc.PlanTermination(ctx, u)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	u.Status = "disabled"
+	u.PaidLeave = 0
+	u.UpdatedAt = now
+
+	settlement := &Settlement{
+		UserID:            userID,
+		TerminationDate:   termination,
+		Year:              year,
+		Month:             month,
+		ExpectedUnits:     final.Summary.ExpectedUnits,
+		WorkedUnits:       final.Summary.WorkedUnits,
+		PaidUsedUnits:     final.Summary.PaidUsedUnits,
+		CompOffUsedUnits:  final.Summary.CompOffUsedUnits,
+		UnpaidUnits:       final.Summary.UnpaidUnits,
+		PaidLeaveBefore:   final.PaidLeaveBefore,
+		AccrualAdjustment: final.AccrualAdjustment,
+		SettlementDays:    final.Days,
+		ClosedSessions:    closed,
+		RevokedSessions:   int(revoked),
+		Reason:            reason,
+		ProcessedBy:       adminID,
+		CreatedAt:         now,
+	}
+	err = s.repo.Transaction(ctx, func(tx *Repo, db *gorm.DB) error {
+		// The unique user index makes a concurrent offboarding of the same user fail here
+		if err := tx.Create(ctx, settlement); err != nil {
+			return err
+		}
+		if err := user.NewRepo(db).Update(ctx, u); err != nil {
+			return err
+		}
+		return s.leaveSvc.ApplyTermination(ctx, db, final)
+	})
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+
+	if err := s.leaveSvc.RecordTermination(ctx, final); err != nil {
+		s.logger.Warn("store final month summary failed", zap.Uint("userID", userID), zap.Error(err))
+	}
+	after, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	s.logger.Info("user offboarded",
+		zap.Uint("userID", userID),
+		zap.Uint("adminID", adminID),
+		zap.String("terminationDate", termination.Format("2006-01-02")),
+		zap.Float64("settlementDays", final.Days))
+	return &Result{Before: &before, After: after, Settlement: settlement}, nil
+}
+
+// Get returns a user's settlement
+func (s *Service) Get(ctx context.Context, userID uint) (*Settlement, error) {
+	st, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if st == nil {
+		return nil, response.NotFound("User has not been offboarded")
+	}
+	return st, nil
+}
+
+// List returns the settlements of a final month (year 0 = all)
+func (s *Service) List(ctx context.Context, year, month int) ([]Settlement, error) {
+	rows, err := s.repo.List(ctx, year, month)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,91 @@
+package rbac
+
+import (
+	"time"
+
+	"time-attendance-be/internal/authx"
+)
+
+type RoleRequest struct {
+	Key         string   `json:"key"` // create only
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type AssignRequest struct {
+	UserID       uint  `json:"userId"`
+	RoleID       uint  `json:"roleId"`
+	DepartmentID *uint `json:"departmentId"`
+}
+
+type PermissionResponse struct {
+	Permission  string `json:"permission"`
+	Description string `json:"description"`
+	Scopable    bool   `json:"scopable"`
+}
+
+type RoleResponse struct {
+	ID          uint     `json:"id"`
+	Key         string   `json:"key"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	System      bool     `json:"system"`
+	Permissions []string `json:"permissions"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+func toRoleResponse(r *Role) RoleResponse {
+	return RoleResponse{
+		ID:          r.ID,
+		Key:         r.Key,
+		Name:        r.Name,
+		Description: r.Description,
+		System:      r.System,
+		Permissions: r.PermissionList(),
+		UpdatedAt:   r.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+type AssignmentResponse struct {
+	ID           uint   `json:"id"`
+	UserID       uint   `json:"userId"`
+	RoleID       uint   `json:"roleId"`
+	RoleKey      string `json:"roleKey,omitempty"`
+	RoleName     string `json:"roleName,omitempty"`
+	DepartmentID *uint  `json:"departmentId"` // null = company-wide
+	AssignedBy   uint   `json:"assignedBy"`
+	CreatedAt    string `json:"createdAt"`
+}
+
+func toAssignmentResponse(a *UserRole) AssignmentResponse {
+	res := AssignmentResponse{
+		ID:           a.ID,
+		UserID:       a.UserID,
+		RoleID:       a.RoleID,
+		DepartmentID: a.DepartmentID,
+		AssignedBy:   a.AssignedBy,
+		CreatedAt:    a.CreatedAt.Format(time.RFC3339),
+	}
+	if a.Role != nil {
+		res.RoleKey = a.Role.Key
+		res.RoleName = a.Role.Name
+	}
+	return res
+}
+
+// MyPermissionResponse is one permission of the current user, for showing admin menus
+type MyPermissionResponse struct {
+	Permission     string `json:"permission"`
+	AllDepartments bool   `json:"allDepartments"`
+	DepartmentIDs  []uint `json:"departmentIds,omitempty"`
+}
+
+func toMyPermissionResponses(g *authx.Grants) []MyPermissionResponse {
+	list := g.List()
+	res := make([]MyPermissionResponse, len(list))
+	for i, p := range list {
+		res[i] = MyPermissionResponse{Permission: p.Permission, AllDepartments: p.Scope.All, DepartmentIDs: p.Scope.Departments}
+	}
+	return res
+}
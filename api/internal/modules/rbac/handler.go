@@ -0,0 +1,281 @@
+package rbac
+
+import (
+	"strconv"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handler struct {
+	svc      *Service
+	auditSvc *audit.Service
+}
+
+func NewHandler(svc *Service, auditSvc *audit.Service) *Handler {
+	return &Handler{svc: svc, auditSvc: auditSvc}
+}
+
+// GET /api/v1/me/permissions
+// The current user's effective permissions and their department scope
+func (h *Handler) MyPermissions(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	g, err := a.Grants(c.Context())
+	if err != nil {
+		return response.Internal(err)
+	}
+	return response.OK(c, toMyPermissionResponses(g))
+}
+
+// GET /api/v1/admin/roles/permissions
+// The permission catalog for the role editor
+func (h *Handler) ListPermissions(c *fiber.Ctx) error {
+	res := make([]PermissionResponse, len(authx.AllPermissions))
+	for i, p := range authx.AllPermissions {
+		res[i] = PermissionResponse{Permission: p.Permission, Description: p.Description, Scopable: p.Scopable}
+	}
+	return response.OK(c, res)
+}
+
+// GET /api/v1/admin/roles
+func (h *Handler) ListRoles(c *fiber.Ctx) error {
+	roles, err := h.svc.ListRoles(c.Context())
+	if err != nil {
+		return err
+	}
+	res := make([]RoleResponse, len(roles))
+	for i := range roles {
+		res[i] = toRoleResponse(&roles[i])
+	}
+	return response.OK(c, res)
+}
+
+// POST /api/v1/admin/roles
+// Body: { "key": "payroll", "name": "Payroll", "description": "", "permissions": ["attendance.export"] }
+func (h *Handler) CreateRole(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	role, err := h.svc.CreateRole(c.Context(), RoleInput{
+		Key:         req.Key,
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := toRoleResponse(role)
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"CREATE",
+			"role",
+			strconv.FormatUint(uint64(role.ID), 10),
+			nil,
+			res,
+			c.Query("reason"),
+		)
+	}
+
+	return response.Created(c, res)
+}
+
+// PUT /api/v1/admin/roles/:id
+// Body as for create; the key can't be changed
+func (h *Handler) UpdateRole(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+	var req RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	before, err := h.svc.repo.GetRole(c.Context(), uint(id))
+	if err != nil {
+		return response.Internal(err)
+	}
+	var beforeResp *RoleResponse
+	if before != nil {
+		r := toRoleResponse(before)
+		beforeResp = &r
+	}
+
+	role, err := h.svc.UpdateRole(c.Context(), uint(id), RoleInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := toRoleResponse(role)
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"UPDATE",
+			"role",
+			c.Params("id"),
+			beforeResp,
+			res,
+			c.Query("reason"),
+		)
+	}
+
+	return response.OK(c, res)
+}
+
+// DELETE /api/v1/admin/roles/:id
+func (h *Handler) DeleteRole(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	before, err := h.svc.DeleteRole(c.Context(), uint(id))
+	if err != nil {
+		return err
+	}
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"DELETE",
+			"role",
+			c.Params("id"),
+			toRoleResponse(before),
+			nil,
+			c.Query("reason"),
+		)
+	}
+
+	return response.OK(c, true)
+}
+
+// GET /api/v1/admin/roles/assignments?userId=&roleId=
+func (h *Handler) ListAssignments(c *fiber.Ctx) error {
+	var f AssignmentFilter
+	if s := c.Query("userId"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return response.Validation("invalid userId", nil)
+		}
+		v := uint(id)
+		f.UserID = &v
+	}
+	if s := c.Query("roleId"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return response.Validation("invalid roleId", nil)
+		}
+		v := uint(id)
+		f.RoleID = &v
+	}
+
+	rows, err := h.svc.ListAssignments(c.Context(), f)
+	if err != nil {
+		return err
+	}
+	res := make([]AssignmentResponse, len(rows))
+	for i := range rows {
+		res[i] = toAssignmentResponse(&rows[i])
+	}
+	return response.OK(c, res)
+}
+
+// POST /api/v1/admin/roles/assignments
+// Body: { "userId": 12, "roleId": 3, "departmentId": 2 } (departmentId null = company-wide)
+func (h *Handler) Assign(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var req AssignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	a, err := h.svc.Assign(c.Context(), adminUser.ID, AssignInput{
+		UserID:       req.UserID,
+		RoleID:       req.RoleID,
+		DepartmentID: req.DepartmentID,
+	})
+	if err != nil {
+		return err
+	}
+
+	res := toAssignmentResponse(a)
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"ASSIGN",
+			"user_role",
+			strconv.FormatUint(uint64(a.ID), 10),
+			nil,
+			res,
+			c.Query("reason"),
+		)
+	}
+
+	return response.Created(c, res)
+}
+
+// DELETE /api/v1/admin/roles/assignments/:id
+func (h *Handler) Unassign(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	before, err := h.svc.Unassign(c.Context(), adminUser.ID, uint(id))
+	if err != nil {
+		return err
+	}
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"UNASSIGN",
+			"user_role",
+			c.Params("id"),
+			toAssignmentResponse(before),
+			nil,
+			c.Query("reason"),
+		)
+	}
+
+	return response.OK(c, true)
+}
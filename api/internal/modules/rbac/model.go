@@ -0,0 +1,62 @@
+package rbac
+
+import "time"
+
+// Role is a named set of permissions (table rbac_roles). System roles are maintained by
+// the application and can't be edited or deleted.
+type Role struct {
+	ID          uint             `gorm:"primaryKey"`
+	Key         string           `gorm:"type:varchar(50);not null;uniqueIndex"`
+	Name        string           `gorm:"type:varchar(100);not null"`
+	Description string           `gorm:"type:varchar(255);not null;default:''"`
+	System      bool             `gorm:"type:tinyint(1);not null;default:0"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID"`
+	CreatedAt   time.Time        `gorm:"not null"`
+	UpdatedAt   time.Time        `gorm:"not null"`
+}
+
+func (Role) TableName() string {
+	return "rbac_roles"
+}
+
+// PermissionList returns the role's permission names
+func (r *Role) PermissionList() []string {
+	out := make([]string, len(r.Permissions))
+	for i, p := range r.Permissions {
+		out[i] = p.Permission
+	}
+	return out
+}
+
+// RolePermission is one permission of a role (table rbac_role_permissions)
+type RolePermission struct {
+	RoleID     uint   `gorm:"primaryKey"`
+	Permission string `gorm:"type:varchar(60);primaryKey"`
+}
+
+func (RolePermission) TableName() string {
+	return "rbac_role_permissions"
+}
+
+// UserRole assigns a role to a user, company-wide (DepartmentID nil) or for one
+// department (table rbac_user_roles)
+type UserRole struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"not null;index"`
+	RoleID       uint      `gorm:"not null;index"`
+	Role         *Role     `gorm:"foreignKey:RoleID"`
+	DepartmentID *uint     `gorm:"index"`
+	AssignedBy   uint      `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+func (UserRole) TableName() string {
+	return "rbac_user_roles"
+}
+
+// Built-in role keys
+const (
+	RoleAdmin    = "admin"     // every permission; kept in sync with the catalog at startup
+	RoleHR       = "hr"        // people and leave administration
+	RoleTeamLead = "team_lead" // read access to a team, meant to be assigned per department
+)
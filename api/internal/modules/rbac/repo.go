@@ -0,0 +1,157 @@
+package rbac
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type Repo struct{ db *gorm.DB }
+
+func NewRepo(db *gorm.DB) *Repo { return &Repo{db: db} }
+
+// ListRoles returns all roles with their permissions
+func (r *Repo) ListRoles(ctx context.Context) ([]Role, error) {
+	var rows []Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("system DESC, name ASC").Find(&rows).Error
+	return rows, err
+}
+
+// GetRole returns a role with its permissions, or nil if it doesn't exist
+func (r *Repo) GetRole(ctx context.Context, id uint) (*Role, error) {
+	var rows []Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Where("id = ?", id).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// GetRoleByKey returns a role by key, or nil if it doesn't exist
+func (r *Repo) GetRoleByKey(ctx context.Context, key string) (*Role, error) {
+	var rows []Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Where("`key` = ?", key).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// SaveRole creates or updates a role and replaces its permissions
+func (r *Repo) SaveRole(ctx context.Context, role *Role, perms []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&RolePermission{}).Error; err != nil {
+			return err
+		}
+		role.Permissions = make([]RolePermission, len(perms))
+		for i, p := range perms {
+			role.Permissions[i] = RolePermission{RoleID: role.ID, Permission: p}
+		}
+		if len(role.Permissions) == 0 {
+			return nil
+		}
+		return tx.Create(&role.Permissions).Error
+	})
+}
+
+// DeleteRole removes a role and its permissions
+func (r *Repo) DeleteRole(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Role{}, id).Error
+	})
+}
+
+// CountAssignments returns how many assignments use the role
+func (r *Repo) CountAssignments(ctx context.Context, roleID uint) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&UserRole{}).Where("role_id = ?", roleID).Count(&n).Error
+	return n, err
+}
+
+// AssignmentFilter selects role assignments
+type AssignmentFilter struct {
+	UserID *uint
+	RoleID *uint
+}
+
+// ListAssignments returns assignments with their role, newest first
+func (r *Repo) ListAssignments(ctx context.Context, f AssignmentFilter) ([]UserRole, error) {
+	q := r.db.WithContext(ctx).Preload("Role")
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.RoleID != nil {
+		q = q.Where("role_id = ?", *f.RoleID)
+	}
+	var rows []UserRole
+	err := q.Order("created_at DESC, id DESC").Find(&rows).Error
+	return rows, err
+}
+
+// GetAssignment returns an assignment, or nil if it doesn't exist
+func (r *Repo) GetAssignment(ctx context.Context, id uint) (*UserRole, error) {
+	var rows []UserRole
+	if err := r.db.WithContext(ctx).Preload("Role").Where("id = ?", id).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// FindAssignment returns the assignment of role to user for the department, or nil
+func (r *Repo) FindAssignment(ctx context.Context, userID, roleID uint, departmentID *uint) (*UserRole, error) {
+	q := r.db.WithContext(ctx).Where("user_id = ? AND role_id = ?", userID, roleID)
+	if departmentID != nil {
+		q = q.Where("department_id = ?", *departmentID)
+	} else {
+		q = q.Where("department_id IS NULL")
+	}
+	var rows []UserRole
+	if err := q.Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// CreateAssignment stores an assignment
+func (r *Repo) CreateAssignment(ctx context.Context, a *UserRole) error {
+	return r.db.WithContext(ctx).Create(a).Error
+}
+
+// DeleteAssignment removes an assignment
+func (r *Repo) DeleteAssignment(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&UserRole{}, id).Error
+}
+
+// userPermission is one permission a user holds through an assignment
+type userPermission struct {
+	Permission   string
+	DepartmentID *uint
+}
+
+// ListUserPermissions returns every permission the user's assignments grant
+func (r *Repo) ListUserPermissions(ctx context.Context, userID uint) ([]userPermission, error) {
+	var rows []userPermission
+	err := r.db.WithContext(ctx).
+		Table("rbac_user_roles AS ur").
+		Select("rp.permission, ur.department_id").
+		Joins("INNER JOIN rbac_role_permissions rp ON rp.role_id = ur.role_id").
+		Where("ur.user_id = ?", userID).
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,37 @@
+package rbac
+
+import (
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Module struct {
+	h *Handler
+	s *Service
+}
+
+func NewModule(svc *Service, auditSvc *audit.Service) *Module {
+	return &Module{h: NewHandler(svc, auditSvc), s: svc}
+}
+
+func (m *Module) Service() *Service {
+	return m.s
+}
+
+func (m *Module) RegisterMe(v1 fiber.Router, auth fiber.Handler) {
+	v1.Get("/me/permissions", auth, m.h.MyPermissions)
+}
+
+func (m *Module) RegisterAdmin(admin fiber.Router) {
+	g := admin.Group("/roles", authx.Require(authx.PermRolesManage))
+	g.Get("/permissions", m.h.ListPermissions)
+	g.Get("/assignments", m.h.ListAssignments)
+	g.Post("/assignments", m.h.Assign)
+	g.Delete("/assignments/:id", m.h.Unassign)
+	g.Get("/", m.h.ListRoles)
+	g.Post("/", m.h.CreateRole)
+	g.Put("/:id", m.h.UpdateRole)
+	g.Delete("/:id", m.h.DeleteRole)
+}
@@ -0,0 +1,332 @@
+package rbac
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/department"
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/pkg/response"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var roleKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// builtinRoles are created at startup when missing. Only the admin role is a system role;
+// the others are starting points that can be edited or deleted.
+var builtinRoles = []struct {
+	Key         string
+	Name        string
+	Description string
+	Permissions []string
+}{
+	{RoleAdmin, "Administrator", "Full access to the admin area", nil},
+	{RoleHR, "HR", "People, attendance, leave and timesheet administration", []string{
+		authx.PermUsersRead, authx.PermUsersWrite, authx.PermDepartmentsManage,
+		authx.PermAttendanceRead, authx.PermAttendanceWrite, authx.PermAttendanceExport,
+		authx.PermLeaveRead, authx.PermLeaveManage, authx.PermLeavePolicy,
+		authx.PermTimesheetsRead, authx.PermTimesheetsApprove,
+		authx.PermCalendarManage, authx.PermStatsRead,
+	}},
+	{RoleTeamLead, "Team lead", "View a team's people, attendance and timesheets; assign per department", []string{
+		authx.PermUsersRead, authx.PermAttendanceRead, authx.PermAttendanceExport, authx.PermTimesheetsRead,
+	}},
+}
+
+type Service struct {
+	repo     *Repo
+	userRepo *user.Repo
+	deptRepo *department.Repo
+	logger   *zap.Logger
+}
+
+func NewService(repo *Repo, userRepo *user.Repo, deptRepo *department.Repo, logger *zap.Logger) *Service {
+	return &Service{repo: repo, userRepo: userRepo, deptRepo: deptRepo, logger: logger}
+}
+
+// EnsureBuiltinRoles creates missing built-in roles and keeps the admin role's permissions
+// equal to the full catalog
+func (s *Service) EnsureBuiltinRoles(ctx context.Context) error {
+	for _, b := range builtinRoles {
+		role, err := s.repo.GetRoleByKey(ctx, b.Key)
+		if err != nil {
+			return err
+		}
+		perms := b.Permissions
+		if b.Key == RoleAdmin {
+			perms = allPermissions()
+		}
+		if role != nil && (b.Key != RoleAdmin || sameStrings(role.PermissionList(), perms)) {
+			continue
+		}
+		if role == nil {
+			now := time.Now()
+			role = &Role{Key: b.Key, Name: b.Name, Description: b.Description, System: b.Key == RoleAdmin, CreatedAt: now}
+			s.logger.Info("creating built-in role", zap.String("role", b.Key))
+		}
+		role.UpdatedAt = time.Now()
+		if err := s.repo.SaveRole(ctx, role, perms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Grants returns the user's effective permissions (implements middleware.PermissionStore).
+// The legacy admin account role keeps full access alongside role assignments.
+func (s *Service) Grants(ctx context.Context, userID uint, accountRole string) (*authx.Grants, error) {
+	g := authx.NewGrants()
+	if accountRole == "admin" {
+		g.AddAll()
+		return g, nil
+	}
+	rows, err := s.repo.ListUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		g.Add(r.Permission, r.DepartmentID)
+	}
+	return g, nil
+}
+
+// ListRoles returns all roles
+func (s *Service) ListRoles(ctx context.Context) ([]Role, error) {
+	rows, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	return rows, nil
+}
+
+// RoleInput describes a role to create or update
+type RoleInput struct {
+	Key         string
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// CreateRole creates a custom role
+func (s *Service) CreateRole(ctx context.Context, in RoleInput) (*Role, error) {
+	key := strings.TrimSpace(in.Key)
+	if !roleKeyPattern.MatchString(key) {
+		return nil, response.Validation("key must be 2-50 lowercase letters, digits or underscores", nil)
+	}
+	name, desc, perms, err := validateRole(in)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetRoleByKey(ctx, key)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if existing != nil {
+		return nil, response.Conflict("A role with this key already exists")
+	}
+
+	now := time.Now()
+	role := &Role{Key: key, Name: name, Description: desc, CreatedAt: now, UpdatedAt: now}
+	if err := s.repo.SaveRole(ctx, role, perms); err != nil {
+		return nil, response.Internal(err)
+	}
+	return role, nil
+}
+
+// UpdateRole changes a role's name, description and permissions; the key is fixed
+func (s *Service) UpdateRole(ctx context.Context, id uint, in RoleInput) (*Role, error) {
+	role, err := s.getEditableRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	name, desc, perms, err := validateRole(in)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Name = name
+	role.Description = desc
+	role.UpdatedAt = time.Now()
+	if err := s.repo.SaveRole(ctx, role, perms); err != nil {
+		return nil, response.Internal(err)
+	}
+	return role, nil
+}
+
+// DeleteRole deletes a role that is no longer assigned
+func (s *Service) DeleteRole(ctx context.Context, id uint) (*Role, error) {
+	role, err := s.getEditableRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.repo.CountAssignments(ctx, id)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if n > 0 {
+		return nil, response.Conflict("Role is still assigned, remove its assignments first")
+	}
+	if err := s.repo.DeleteRole(ctx, id); err != nil {
+		return nil, response.Internal(err)
+	}
+	return role, nil
+}
+
+// ListAssignments returns role assignments
+func (s *Service) ListAssignments(ctx context.Context, f AssignmentFilter) ([]UserRole, error) {
+	rows, err := s.repo.ListAssignments(ctx, f)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	return rows, nil
+}
+
+// AssignInput describes a role assignment
+type AssignInput struct {
+	UserID       uint
+	RoleID       uint
+	DepartmentID *uint // nil = company-wide
+}
+
+// Assign gives a user a role, company-wide or for one department. Only the role's scopable
+// permissions take effect in a department assignment.
+func (s *Service) Assign(ctx context.Context, actorID uint, in AssignInput) (*UserRole, error) {
+	if _, err := s.userRepo.GetByID(ctx, in.UserID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, response.Validation("User not found", nil)
+		}
+		return nil, response.Internal(err)
+	}
+	role, err := s.repo.GetRole(ctx, in.RoleID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if role == nil {
+		return nil, response.Validation("Role not found", nil)
+	}
+	if in.DepartmentID != nil {
+		if role.System {
+			return nil, response.Validation("The administrator role can only be assigned company-wide", nil)
+		}
+		if _, err := s.deptRepo.GetByID(ctx, *in.DepartmentID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, response.Validation("Department not found", nil)
+			}
+			return nil, response.Internal(err)
+		}
+	}
+
+	existing, err := s.repo.FindAssignment(ctx, in.UserID, in.RoleID, in.DepartmentID)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if existing != nil {
+		return nil, response.Conflict("The user already has this role")
+	}
+
+	a := &UserRole{
+		UserID:       in.UserID,
+		RoleID:       in.RoleID,
+		Role:         role,
+		DepartmentID: in.DepartmentID,
+		AssignedBy:   actorID,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.repo.CreateAssignment(ctx, a); err != nil {
+		return nil, response.Internal(err)
+	}
+	return a, nil
+}
+
+// Unassign removes an assignment. Administrators can't remove their own role management
+// access this way, so the last of them can't lock everyone out.
+func (s *Service) Unassign(ctx context.Context, actorID, id uint) (*UserRole, error) {
+	a, err := s.repo.GetAssignment(ctx, id)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if a == nil {
+		return nil, response.NotFound("Assignment not found")
+	}
+	if a.UserID == actorID && a.Role != nil && a.DepartmentID == nil {
+		for _, p := range a.Role.PermissionList() {
+			if p == authx.PermRolesManage {
+				return nil, response.Validation("You cannot remove your own role management access", nil)
+			}
+		}
+	}
+	if err := s.repo.DeleteAssignment(ctx, id); err != nil {
+		return nil, response.Internal(err)
+	}
+	return a, nil
+}
+
+func (s *Service) getEditableRole(ctx context.Context, id uint) (*Role, error) {
+	role, err := s.repo.GetRole(ctx, id)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if role == nil {
+		return nil, response.NotFound("Role not found")
+	}
+	if role.System {
+		return nil, response.Validation("System roles cannot be changed", nil)
+	}
+	return role, nil
+}
+
+func validateRole(in RoleInput) (name, desc string, perms []string, err error) {
+	name = strings.TrimSpace(in.Name)
+	if name == "" || len(name) > 100 {
+		return "", "", nil, response.Validation("name is required (max 100 characters)", nil)
+	}
+	desc = strings.TrimSpace(in.Description)
+	if len(desc) > 255 {
+		return "", "", nil, response.Validation("description must be at most 255 characters", nil)
+	}
+	seen := map[string]bool{}
+	for _, p := range in.Permissions {
+		p = strings.TrimSpace(p)
+		if !authx.IsPermission(p) {
+			return "", "", nil, response.Validation("unknown permission: "+p, nil)
+		}
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+	if len(perms) == 0 {
+		return "", "", nil, response.Validation("at least one permission is required", nil)
+	}
+	sort.Strings(perms)
+	return name, desc, perms, nil
+}
+
+func allPermissions() []string {
+	out := make([]string, len(authx.AllPermissions))
+	for i, p := range authx.AllPermissions {
+		out[i] = p.Permission
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	sort.Strings(a)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
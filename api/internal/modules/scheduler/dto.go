@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"time"
+
+	"time-attendance-be/internal/platform/scheduler"
+)
+
+// JobResponse describes a registered job
+type JobResponse struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"`
+	RunOnStart  bool       `json:"runOnStart"`
+	Running     bool       `json:"running"` // running on this instance
+	NextRun     *time.Time `json:"nextRun,omitempty"`
+}
+
+// RunResponse is one recorded job run
+type RunResponse struct {
+	ID          uint       `json:"id"`
+	JobName     string     `json:"jobName"`
+	Trigger     string     `json:"trigger"`
+	Status      string     `json:"status"`
+	Instance    string     `json:"instance"`
+	TriggeredBy *uint      `json:"triggeredBy,omitempty"`
+	RetryOf     *uint      `json:"retryOf,omitempty"`
+	StartedAt   time.Time  `json:"startedAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	DurationMs  int64      `json:"durationMs"`
+	Processed   int        `json:"processed"`
+	Failed      int        `json:"failed"`
+	Message     *string    `json:"message,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+}
+
+func toRunResponse(r *scheduler.Run) RunResponse {
+	return RunResponse{
+		ID:          r.ID,
+		JobName:     r.JobName,
+		Trigger:     r.Trigger,
+		Status:      r.Status,
+		Instance:    r.Instance,
+		TriggeredBy: r.TriggeredBy,
+		RetryOf:     r.RetryOf,
+		StartedAt:   r.StartedAt,
+		FinishedAt:  r.FinishedAt,
+		DurationMs:  r.DurationMs,
+		Processed:   r.Processed,
+		Failed:      r.Failed,
+		Message:     r.Message,
+		Error:       r.Error,
+	}
+}
@@ -0,0 +1,169 @@
+package scheduler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+	"time-attendance-be/internal/pkg/response"
+	"time-attendance-be/internal/platform/lease"
+	"time-attendance-be/internal/platform/scheduler"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	elector  *lease.Elector
+	sch      *scheduler.Scheduler
+	auditSvc *audit.Service
+}
+
+func NewHandler(elector *lease.Elector, sch *scheduler.Scheduler, auditSvc *audit.Service) *Handler {
+	return &Handler{elector: elector, sch: sch, auditSvc: auditSvc}
+}
+
+// GET /api/v1/admin/scheduler/lease
+// Shows which instance holds the scheduler lease and whether it is still valid
+func (h *Handler) LeaseStatus(c *fiber.Ctx) error {
+	l, err := h.elector.Current(c.Context())
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	res := map[string]interface{}{
+		"instance": h.elector.Holder(),
+		"isLeader": h.elector.IsLeader(),
+		"lease":    nil,
+	}
+	if l != nil {
+		res["lease"] = map[string]interface{}{
+			"name":       l.Name,
+			"holder":     l.Holder,
+			"acquiredAt": l.AcquiredAt,
+			"renewedAt":  l.RenewedAt,
+			"expiresAt":  l.ExpiresAt,
+			"expired":    l.ExpiresAt.Before(time.Now()),
+		}
+	}
+	return response.OK(c, res)
+}
+
+// GET /api/v1/admin/scheduler/jobs
+func (h *Handler) ListJobs(c *fiber.Ctx) error {
+	jobs := h.sch.Jobs()
+	results := make([]JobResponse, len(jobs))
+	for i, j := range jobs {
+		results[i] = JobResponse{
+			Name:        j.Name,
+			Description: j.Description,
+			Schedule:    j.Schedule,
+			RunOnStart:  j.RunOnStart,
+			Running:     j.Running,
+		}
+		if !j.NextRun.IsZero() {
+			next := j.NextRun
+			results[i].NextRun = &next
+		}
+	}
+	return response.OK(c, results)
+}
+
+// GET /api/v1/admin/scheduler/runs?job=&limit=
+func (h *Handler) ListRuns(c *fiber.Ctx) error {
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+
+	runs, err := h.sch.History(c.Context(), c.Query("job"), limit)
+	if err != nil {
+		return response.Internal(err)
+	}
+	results := make([]RunResponse, len(runs))
+	for i := range runs {
+		results[i] = toRunResponse(&runs[i])
+	}
+	return response.OK(c, results)
+}
+
+// POST /api/v1/admin/scheduler/jobs/:name/trigger
+// Starts the job now; the run continues in the background (poll /runs for the outcome)
+func (h *Handler) TriggerJob(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	run, err := h.sch.Trigger(c.Context(), c.Params("name"), adminUser.ID, nil)
+	if err != nil {
+		return triggerError(err)
+	}
+
+	res := toRunResponse(run)
+	h.logRun(c, adminUser.ID, "TRIGGER_JOB", res)
+	return response.OK(c, res)
+}
+
+// POST /api/v1/admin/scheduler/runs/:id/retry
+// Re-runs the job of a failed run
+func (h *Handler) RetryRun(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+	prev, err := h.sch.GetRun(c.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound("Run not found")
+		}
+		return response.Internal(err)
+	}
+	if prev.Status != scheduler.StatusFailed {
+		return response.Conflict("Only failed runs can be retried")
+	}
+
+	runID := prev.ID
+	run, err := h.sch.Trigger(c.Context(), prev.JobName, adminUser.ID, &runID)
+	if err != nil {
+		return triggerError(err)
+	}
+
+	res := toRunResponse(run)
+	h.logRun(c, adminUser.ID, "RETRY_JOB", res)
+	return response.OK(c, res)
+}
+
+func (h *Handler) logRun(c *fiber.Ctx, adminID uint, action string, run RunResponse) {
+	if h.auditSvc == nil {
+		return
+	}
+	_ = h.auditSvc.LogAdminAction(
+		c.Context(),
+		adminID,
+		action,
+		"scheduler_job_run",
+		strconv.FormatUint(uint64(run.ID), 10),
+		nil,
+		run,
+		run.JobName,
+	)
+}
+
+func triggerError(err error) error {
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		return response.NotFound("Job not found")
+	case errors.Is(err, scheduler.ErrJobRunning):
+		return response.Conflict("Job is already running")
+	default:
+		return response.Internal(err)
+	}
+}
@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+	"time-attendance-be/internal/platform/lease"
+	"time-attendance-be/internal/platform/scheduler"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Module struct {
+	h *Handler
+}
+
+func NewModule(elector *lease.Elector, sch *scheduler.Scheduler, auditSvc *audit.Service) *Module {
+	return &Module{h: NewHandler(elector, sch, auditSvc)}
+}
+
+func (m *Module) RegisterAdmin(admin fiber.Router) {
+	g := admin.Group("/scheduler", authx.Require(authx.PermSchedulerManage))
+	g.Get("/lease", m.h.LeaseStatus)
+	g.Get("/jobs", m.h.ListJobs)
+	g.Post("/jobs/:name/trigger", m.h.TriggerJob)
+	g.Get("/runs", m.h.ListRuns)
+	g.Post("/runs/:id/retry", m.h.RetryRun)
+}
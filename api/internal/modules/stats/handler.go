@@ -1,6 +1,7 @@
 package stats
 
 import (
+	"strconv"
 	"time"
 
 	"time-attendance-be/internal/authx"
@@ -45,7 +46,38 @@ func (h *Handler) GetMeStats(c *fiber.Ctx) error {
 
 // GET /api/v1/admin/overview/today
 func (h *Handler) GetTodayOps(c *fiber.Ctx) error {
-	ops, err := h.svc.GetTodayOps(c.Context())
+	ops, err := h.svc.GetTodayOps(c.Context(), nil)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, ops)
+}
+
+// GET /api/v1/manager/overview/today?departmentId=
+// Today's numbers for the departments the user manages
+func (h *Handler) GetManagerTodayOps(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	var departmentID *uint
+	if v := c.Query("departmentId"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return response.Validation("invalid departmentId", nil)
+		}
+		did := uint(id)
+		departmentID = &did
+	}
+	ids, err := a.ManagerScope().Narrow(departmentID)
+	if err != nil {
+		return err
+	}
+	if departmentID != nil {
+		ids = []uint{*departmentID}
+	}
+
+	ops, err := h.svc.GetTodayOps(c.Context(), ids)
 	if err != nil {
 		return err
 	}
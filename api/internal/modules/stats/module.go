@@ -1,6 +1,7 @@
 package stats
 
 import (
+	"time-attendance-be/internal/authx"
 	"time-attendance-be/internal/config"
 	"time-attendance-be/internal/pkg/clock"
 
@@ -22,8 +23,11 @@ func (m *Module) RegisterMe(v1 fiber.Router, auth fiber.Handler) {
 }
 
 func (m *Module) RegisterAdmin(admin fiber.Router) {
-	overview := admin.Group("/overview")
+	overview := admin.Group("/overview", authx.Require(authx.PermStatsRead))
 	overview.Get("/today", m.h.GetTodayOps)
 	overview.Get("/top-issues", m.h.GetTopIssues)
 }
 
+func (m *Module) RegisterManager(mgr fiber.Router) {
+	mgr.Get("/overview/today", m.h.GetManagerTodayOps)
+}
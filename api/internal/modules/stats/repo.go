@@ -85,15 +85,17 @@ type TodayOpsData struct {
 	Anomalies       int
 }
 
-func (r *Repo) GetTodayOps(ctx context.Context, today string) (*TodayOpsData, error) {
+// GetTodayOps counts today's attendance; departmentIDs, when not nil, limits it to those departments
+func (r *Repo) GetTodayOps(ctx context.Context, today string, departmentIDs []uint) (*TodayOpsData, error) {
 	var data TodayOpsData
 
 	// Count active users (users with status = 'active')
 	var cnt int64
-	err := r.db.WithContext(ctx).
-		Table("users").
-		Where("status = ?", "active").
-		Count(&cnt).Error
+	users := r.db.WithContext(ctx).Table("users").Where("status = ?", "active")
+	if departmentIDs != nil {
+		users = users.Where("department_id IN ?", departmentIDs)
+	}
+	err := users.Count(&cnt).Error
 	if err != nil {
 		return nil, err
 	}
@@ -101,8 +103,7 @@ func (r *Repo) GetTodayOps(ctx context.Context, today string) (*TodayOpsData, er
 
 	// Count checked in today
 	cnt = 0
-	err = r.db.WithContext(ctx).
-		Table("attendance_sessions").
+	err = r.sessions(ctx, departmentIDs).
 		Where("work_date = ? AND check_in_at IS NOT NULL", today).
 		Count(&cnt).Error
 	if err != nil {
@@ -115,8 +116,7 @@ func (r *Repo) GetTodayOps(ctx context.Context, today string) (*TodayOpsData, er
 
 	// Open sessions (checked in but not checked out)
 	cnt = 0
-	err = r.db.WithContext(ctx).
-		Table("attendance_sessions").
+	err = r.sessions(ctx, departmentIDs).
 		Where("work_date = ? AND status = ?", today, "OPEN").
 		Count(&cnt).Error
 	if err != nil {
@@ -126,8 +126,7 @@ func (r *Repo) GetTodayOps(ctx context.Context, today string) (*TodayOpsData, er
 
 	// Missing checkout (checked in but no checkout, and it's past 19:00 or next day)
 	cnt = 0
-	err = r.db.WithContext(ctx).
-		Table("attendance_sessions").
+	err = r.sessions(ctx, departmentIDs).
 		Where("work_date = ? AND status = ? AND check_out_at IS NULL", today, "OPEN").
 		Count(&cnt).Error
 	if err != nil {
@@ -137,8 +136,7 @@ func (r *Repo) GetTodayOps(ctx context.Context, today string) (*TodayOpsData, er
 
 	// Anomalies (OPEN sessions + MISSING status)
 	var anomalies int64
-	err = r.db.WithContext(ctx).
-		Table("attendance_sessions").
+	err = r.sessions(ctx, departmentIDs).
 		Where("work_date = ? AND (status = ? OR status = ?)", today, "OPEN", "MISSING").
 		Count(&anomalies).Error
 	if err != nil {
@@ -149,6 +147,15 @@ func (r *Repo) GetTodayOps(ctx context.Context, today string) (*TodayOpsData, er
 	return &data, nil
 }
 
+// sessions queries attendance_sessions, limited to users of departmentIDs when not nil
+func (r *Repo) sessions(ctx context.Context, departmentIDs []uint) *gorm.DB {
+	q := r.db.WithContext(ctx).Table("attendance_sessions")
+	if departmentIDs != nil {
+		q = q.Where("user_id IN (?)", r.db.Table("users").Select("id").Where("department_id IN ?", departmentIDs))
+	}
+	return q
+}
+
 type TopIssueRow struct {
 	UserID         uint
 	Name           string
@@ -141,11 +141,12 @@ func parseRange(loc *time.Location, queryRange, value string) (time.Time, time.T
 }
 
 // Admin service methods
-func (s *Service) GetTodayOps(ctx context.Context) (*AdminTodayOpsResponse, error) {
+// GetTodayOps summarises today's attendance; departmentIDs, when not nil, limits it to those departments
+func (s *Service) GetTodayOps(ctx context.Context, departmentIDs []uint) (*AdminTodayOpsResponse, error) {
 	loc := s.cfg.TimeLocation()
 	today := s.clock.Now().In(loc).Format("2006-01-02")
 	
-	data, err := s.repo.GetTodayOps(ctx, today)
+	data, err := s.repo.GetTodayOps(ctx, today, departmentIDs)
 	if err != nil {
 		return nil, response.Internal(err)
 	}
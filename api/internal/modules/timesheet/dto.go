@@ -0,0 +1,239 @@
+package timesheet
+
+import (
+	"time"
+
+	"time-attendance-be/internal/modules/attendance"
+	"time-attendance-be/internal/modules/leave"
+)
+
+// CorrectionItemRequest is one disputed point; workDate (YYYY-MM-DD) is optional
+type CorrectionItemRequest struct {
+	WorkDate *string `json:"workDate"`
+	Message  string  `json:"message"`
+}
+
+// DisputeRequest is the body of dispute (employee) and reject (manager)
+type DisputeRequest struct {
+	Items []CorrectionItemRequest `json:"items"`
+}
+
+// ReopenRequest is the body of an admin reopen
+type ReopenRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ResolveCorrectionRequest closes a correction request
+type ResolveCorrectionRequest struct {
+	Status     string `json:"status"` // RESOLVED or REJECTED
+	Resolution string `json:"resolution"`
+}
+
+// SignOffResponse is the sign-off state of a user/month
+type SignOffResponse struct {
+	ID                  uint    `json:"id,omitempty"`
+	UserID              uint    `json:"userId"`
+	Year                int     `json:"year"`
+	Month               int     `json:"month"`
+	Status              string  `json:"status"`
+	Locked              bool    `json:"locked"`
+	ExpectedUnits       float64 `json:"expectedUnits"`
+	WorkedUnits         float64 `json:"workedUnits"`
+	MissingUnits        float64 `json:"missingUnits"`
+	BirthdayUsedUnits   float64 `json:"birthdayUsedUnits"`
+	PaidUsedUnits       float64 `json:"paidUsedUnits"`
+	CompOffUsedUnits    float64 `json:"compOffUsedUnits"`
+	UnpaidUnits         float64 `json:"unpaidUnits"`
+	EmployeeConfirmedAt *string `json:"employeeConfirmedAt"`
+	ManagerApprovedBy   *uint   `json:"managerApprovedBy"`
+	ManagerApprovedAt   *string `json:"managerApprovedAt"`
+	ReopenedBy          *uint   `json:"reopenedBy,omitempty"`
+	ReopenedAt          *string `json:"reopenedAt,omitempty"`
+	ReopenReason        *string `json:"reopenReason,omitempty"`
+	LastRemindedAt      *string `json:"lastRemindedAt,omitempty"`
+	ReminderCount       int     `json:"reminderCount"`
+}
+
+func toSignOffResponse(s *SignOff) SignOffResponse {
+	return SignOffResponse{
+		ID:                  s.ID,
+		UserID:              s.UserID,
+		Year:                s.Year,
+		Month:               s.Month,
+		Status:              s.Status,
+		Locked:              s.IsLocked(),
+		ExpectedUnits:       s.ExpectedUnits,
+		WorkedUnits:         s.WorkedUnits,
+		MissingUnits:        s.MissingUnits,
+		BirthdayUsedUnits:   s.BirthdayUsedUnits,
+		PaidUsedUnits:       s.PaidUsedUnits,
+		CompOffUsedUnits:    s.CompOffUsedUnits,
+		UnpaidUnits:         s.UnpaidUnits,
+		EmployeeConfirmedAt: formatTime(s.EmployeeConfirmedAt),
+		ManagerApprovedBy:   s.ManagerApprovedBy,
+		ManagerApprovedAt:   formatTime(s.ManagerApprovedAt),
+		ReopenedBy:          s.ReopenedBy,
+		ReopenedAt:          formatTime(s.ReopenedAt),
+		ReopenReason:        s.ReopenReason,
+		LastRemindedAt:      formatTime(s.LastRemindedAt),
+		ReminderCount:       s.ReminderCount,
+	}
+}
+
+// CorrectionResponse is a correction request
+type CorrectionResponse struct {
+	ID         uint    `json:"id"`
+	SignOffID  uint    `json:"signOffId"`
+	UserID     uint    `json:"userId"`
+	WorkDate   *string `json:"workDate"`
+	Message    string  `json:"message"`
+	RaisedBy   uint    `json:"raisedBy"`
+	Status     string  `json:"status"`
+	Resolution *string `json:"resolution"`
+	ResolvedBy *uint   `json:"resolvedBy"`
+	ResolvedAt *string `json:"resolvedAt"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+func toCorrectionResponse(c *CorrectionRequest) CorrectionResponse {
+	var workDate *string
+	if c.WorkDate != nil {
+		d := c.WorkDate.Format("2006-01-02")
+		workDate = &d
+	}
+	return CorrectionResponse{
+		ID:         c.ID,
+		SignOffID:  c.SignOffID,
+		UserID:     c.UserID,
+		WorkDate:   workDate,
+		Message:    c.Message,
+		RaisedBy:   c.RaisedBy,
+		Status:     c.Status,
+		Resolution: c.Resolution,
+		ResolvedBy: c.ResolvedBy,
+		ResolvedAt: formatTime(c.ResolvedAt),
+		CreatedAt:  c.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toCorrectionResponses(cs []CorrectionRequest) []CorrectionResponse {
+	res := make([]CorrectionResponse, len(cs))
+	for i := range cs {
+		res[i] = toCorrectionResponse(&cs[i])
+	}
+	return res
+}
+
+// SessionResponse is one attendance session of the reviewed month
+type SessionResponse struct {
+	ID            uint    `json:"id"`
+	WorkDate      string  `json:"workDate"`
+	CheckInAt     string  `json:"checkInAt"`
+	CheckOutAt    *string `json:"checkOutAt"`
+	WorkedMinutes int     `json:"workedMinutes"`
+	DayUnit       float32 `json:"dayUnit"`
+	Status        string  `json:"status"`
+}
+
+// ReviewResponse is a month under review: sign-off state, current figures per day, sessions and corrections
+type ReviewResponse struct {
+	SignOff     SignOffResponse                `json:"signOff"`
+	Breakdown   leave.MonthlyBreakdownResponse `json:"breakdown"`
+	Sessions    []SessionResponse              `json:"sessions"`
+	Corrections []CorrectionResponse           `json:"corrections"`
+}
+
+func toReviewResponse(r *Review, loc *time.Location) ReviewResponse {
+	sessions := make([]SessionResponse, len(r.Sessions))
+	for i := range r.Sessions {
+		sessions[i] = toSessionResponse(&r.Sessions[i], loc)
+	}
+	return ReviewResponse{
+		SignOff:     toSignOffResponse(r.SignOff),
+		Breakdown:   leave.ToMonthlyBreakdownResponse(r.Breakdown),
+		Sessions:    sessions,
+		Corrections: toCorrectionResponses(r.Corrections),
+	}
+}
+
+func toSessionResponse(s *attendance.Session, loc *time.Location) SessionResponse {
+	var checkOut *string
+	if s.CheckOutAt != nil {
+		t := s.CheckOutAt.In(loc).Format(time.RFC3339)
+		checkOut = &t
+	}
+	return SessionResponse{
+		ID:            s.ID,
+		WorkDate:      s.WorkDate.Format("2006-01-02"),
+		CheckInAt:     s.CheckInAt.In(loc).Format(time.RFC3339),
+		CheckOutAt:    checkOut,
+		WorkedMinutes: s.WorkedMinutes,
+		DayUnit:       s.DayUnit,
+		Status:        s.Status,
+	}
+}
+
+// OverviewRowResponse is one user's line in the admin overview
+type OverviewRowResponse struct {
+	UserID         uint    `json:"userId"`
+	Name           string  `json:"name"`
+	Email          string  `json:"email"`
+	DepartmentID   *uint   `json:"departmentId,omitempty"`
+	Status         string  `json:"status"`
+	ConfirmedAt    *string `json:"employeeConfirmedAt"`
+	ApprovedAt     *string `json:"managerApprovedAt"`
+	LastRemindedAt *string `json:"lastRemindedAt"`
+	ReminderCount  int     `json:"reminderCount"`
+}
+
+// OverviewResponse is the sign-off state of all active users for a month
+type OverviewResponse struct {
+	Year   int                   `json:"year"`
+	Month  int                   `json:"month"`
+	Counts map[string]int        `json:"counts"`
+	Rows   []OverviewRowResponse `json:"rows"`
+}
+
+func toOverviewResponse(year, month int, rows []OverviewRow, status string) OverviewResponse {
+	res := OverviewResponse{
+		Year:  year,
+		Month: month,
+		Counts: map[string]int{
+			StatusPending:           0,
+			StatusEmployeeConfirmed: 0,
+			StatusManagerApproved:   0,
+			StatusDisputed:          0,
+		},
+		Rows: []OverviewRowResponse{},
+	}
+	for _, r := range rows {
+		row := OverviewRowResponse{
+			UserID:       r.User.ID,
+			Name:         r.User.Name,
+			Email:        r.User.Email,
+			DepartmentID: r.User.DepartmentID,
+			Status:       StatusPending,
+		}
+		if r.SignOff != nil {
+			row.Status = r.SignOff.Status
+			row.ConfirmedAt = formatTime(r.SignOff.EmployeeConfirmedAt)
+			row.ApprovedAt = formatTime(r.SignOff.ManagerApprovedAt)
+			row.LastRemindedAt = formatTime(r.SignOff.LastRemindedAt)
+			row.ReminderCount = r.SignOff.ReminderCount
+		}
+		res.Counts[row.Status]++
+		if status != "" && row.Status != status {
+			continue
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
@@ -0,0 +1,430 @@
+package timesheet
+
+import (
+	"strconv"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handler struct {
+	svc      *Service
+	auditSvc *audit.Service
+}
+
+func NewHandler(svc *Service, auditSvc *audit.Service) *Handler {
+	return &Handler{svc: svc, auditSvc: auditSvc}
+}
+
+// GET /api/v1/me/timesheets
+// Lists the user's sign-offs of the last 24 months
+func (h *Handler) ListMine(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	signOffs, err := h.svc.ListMine(c.Context(), a.ID)
+	if err != nil {
+		return response.Internal(err)
+	}
+	res := make([]SignOffResponse, len(signOffs))
+	for i := range signOffs {
+		res[i] = toSignOffResponse(&signOffs[i])
+	}
+	return response.OK(c, res)
+}
+
+// GET /api/v1/me/timesheets/:year/:month
+func (h *Handler) GetMine(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	year, month, err := parseYearMonth(c)
+	if err != nil {
+		return err
+	}
+
+	review, err := h.svc.GetReview(c.Context(), a.ID, year, month)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toReviewResponse(review, h.svc.cfg.TimeLocation()))
+}
+
+// POST /api/v1/me/timesheets/:year/:month/confirm
+// Confirms the month's figures; attendance of the month is locked afterwards
+func (h *Handler) ConfirmMine(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	year, month, err := parseYearMonth(c)
+	if err != nil {
+		return err
+	}
+
+	signOff, err := h.svc.Confirm(c.Context(), a.ID, year, month)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toSignOffResponse(signOff))
+}
+
+// POST /api/v1/me/timesheets/:year/:month/dispute
+// Body: { "items": [{ "workDate": "2026-03-04", "message": "Forgot to check out, left at 18:00" }] }
+func (h *Handler) DisputeMine(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	year, month, err := parseYearMonth(c)
+	if err != nil {
+		return err
+	}
+	items, err := parseDispute(c)
+	if err != nil {
+		return err
+	}
+
+	signOff, err := h.svc.Dispute(c.Context(), a.ID, year, month, items)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toSignOffResponse(signOff))
+}
+
+// GET /api/v1/admin/timesheets?year=&month=&status=&departmentId=
+// Overview of who has (not) signed the month; users without a sign-off count as PENDING
+func (h *Handler) AdminOverview(c *fiber.Ctx) error {
+	loc := h.svc.cfg.TimeLocation()
+	now := time.Now().In(loc)
+	prev := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, -1, 0)
+	year, month := prev.Year(), int(prev.Month())
+	if y := c.Query("year"); y != "" {
+		yInt, err := strconv.Atoi(y)
+		if err != nil {
+			return response.Validation("invalid year", nil)
+		}
+		year = yInt
+	}
+	if m := c.Query("month"); m != "" {
+		mInt, err := strconv.Atoi(m)
+		if err != nil || mInt < 1 || mInt > 12 {
+			return response.Validation("invalid month", nil)
+		}
+		month = mInt
+	}
+
+	var departmentID *uint
+	if d := c.Query("departmentId"); d != "" {
+		id, err := strconv.ParseUint(d, 10, 64)
+		if err != nil {
+			return response.Validation("invalid departmentId", nil)
+		}
+		v := uint(id)
+		departmentID = &v
+	}
+	scope, err := authx.PermissionScope(c, authx.PermTimesheetsRead)
+	if err != nil {
+		return err
+	}
+	if departmentID != nil && !scope.Allows(departmentID) {
+		return response.Forbidden("You can only view timesheets of your departments")
+	}
+
+	rows, err := h.svc.Overview(c.Context(), year, month, departmentID)
+	if err != nil {
+		return response.Internal(err)
+	}
+	if !scope.All {
+		visible := rows[:0]
+		for _, r := range rows {
+			if scope.Allows(r.User.DepartmentID) {
+				visible = append(visible, r)
+			}
+		}
+		rows = visible
+	}
+	return response.OK(c, toOverviewResponse(year, month, rows, c.Query("status")))
+}
+
+// GET /api/v1/admin/timesheets/:userId/:year/:month
+func (h *Handler) AdminGet(c *fiber.Ctx) error {
+	scope, err := authx.PermissionScope(c, authx.PermTimesheetsRead)
+	if err != nil {
+		return err
+	}
+	return h.getReview(c, scope)
+}
+
+// GET /api/v1/manager/timesheets/:userId/:year/:month
+func (h *Handler) ManagerGet(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	return h.getReview(c, a.ManagerScope())
+}
+
+func (h *Handler) getReview(c *fiber.Ctx, scope authx.Scope) error {
+	userID, year, month, err := h.parseScopedTarget(c, scope)
+	if err != nil {
+		return err
+	}
+	review, err := h.svc.GetReview(c.Context(), userID, year, month)
+	if err != nil {
+		return err
+	}
+	return response.OK(c, toReviewResponse(review, h.svc.cfg.TimeLocation()))
+}
+
+// POST /api/v1/admin/timesheets/:userId/:year/:month/approve
+func (h *Handler) AdminApprove(c *fiber.Ctx) error {
+	scope, err := authx.PermissionScope(c, authx.PermTimesheetsApprove)
+	if err != nil {
+		return err
+	}
+	return h.approve(c, scope, true)
+}
+
+// POST /api/v1/manager/timesheets/:userId/:year/:month/approve
+// Department managers countersign the months of their departments' members
+func (h *Handler) ManagerApprove(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	return h.approve(c, a.ManagerScope(), false)
+}
+
+func (h *Handler) approve(c *fiber.Ctx, scope authx.Scope, escalated bool) error {
+	approver := authx.GetUser(c)
+	if approver == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	userID, year, month, err := h.parseScopedTarget(c, scope)
+	if err != nil {
+		return err
+	}
+
+	signOff, err := h.svc.Approve(c.Context(), approver.ID, userID, year, month, escalated)
+	if err != nil {
+		return err
+	}
+	res := toSignOffResponse(signOff)
+	h.logAction(c, approver.ID, "APPROVE", "timesheet_signoff", signOff.ID, res, "")
+	return response.OK(c, res)
+}
+
+// POST /api/v1/admin/timesheets/:userId/:year/:month/reject
+// Body: { "items": [{ "workDate": "2026-03-04", "message": "Session on the 4th is missing" }] }
+func (h *Handler) AdminReject(c *fiber.Ctx) error {
+	scope, err := authx.PermissionScope(c, authx.PermTimesheetsApprove)
+	if err != nil {
+		return err
+	}
+	return h.reject(c, scope)
+}
+
+// POST /api/v1/manager/timesheets/:userId/:year/:month/reject
+// Body: same as the admin reject
+func (h *Handler) ManagerReject(c *fiber.Ctx) error {
+	a := authx.GetUser(c)
+	if a == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	return h.reject(c, a.ManagerScope())
+}
+
+func (h *Handler) reject(c *fiber.Ctx, scope authx.Scope) error {
+	approver := authx.GetUser(c)
+	if approver == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	userID, year, month, err := h.parseScopedTarget(c, scope)
+	if err != nil {
+		return err
+	}
+	items, err := parseDispute(c)
+	if err != nil {
+		return err
+	}
+
+	signOff, err := h.svc.Reject(c.Context(), approver.ID, userID, year, month, items)
+	if err != nil {
+		return err
+	}
+	res := toSignOffResponse(signOff)
+	h.logAction(c, approver.ID, "REJECT", "timesheet_signoff", signOff.ID, res, items[0].Message)
+	return response.OK(c, res)
+}
+
+// parseScopedTarget reads :userId/:year/:month and checks the user's department is in scope
+func (h *Handler) parseScopedTarget(c *fiber.Ctx, scope authx.Scope) (uint, int, int, error) {
+	userID, err := parseUserID(c)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	year, month, err := parseYearMonth(c)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if !scope.All {
+		departmentID, err := h.svc.UserDepartment(c.Context(), userID)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if !scope.Allows(departmentID) {
+			return 0, 0, 0, response.Forbidden("You can only manage timesheets of your departments")
+		}
+	}
+	return userID, year, month, nil
+}
+
+// POST /api/v1/admin/timesheets/:userId/:year/:month/reopen
+// Body: { "reason": "Late sick note" }
+// Unlocks a confirmed or approved month; the employee has to confirm it again
+func (h *Handler) AdminReopen(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	userID, err := parseUserID(c)
+	if err != nil {
+		return err
+	}
+	year, month, err := parseYearMonth(c)
+	if err != nil {
+		return err
+	}
+
+	var req ReopenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	signOff, err := h.svc.Reopen(c.Context(), adminUser.ID, userID, year, month, req.Reason)
+	if err != nil {
+		return err
+	}
+	res := toSignOffResponse(signOff)
+	h.logAction(c, adminUser.ID, "REOPEN", "timesheet_signoff", signOff.ID, res, req.Reason)
+	return response.OK(c, res)
+}
+
+// GET /api/v1/admin/timesheets/corrections?status=OPEN&userId=
+func (h *Handler) AdminListCorrections(c *fiber.Ctx) error {
+	filter := CorrectionFilter{Status: c.Query("status", CorrectionOpen)}
+	if filter.Status == "ALL" {
+		filter.Status = ""
+	}
+	if u := c.Query("userId"); u != "" {
+		id, err := strconv.ParseUint(u, 10, 64)
+		if err != nil {
+			return response.Validation("invalid userId", nil)
+		}
+		v := uint(id)
+		filter.UserID = &v
+	}
+
+	corrections, err := h.svc.ListCorrections(c.Context(), filter)
+	if err != nil {
+		return response.Internal(err)
+	}
+	return response.OK(c, toCorrectionResponses(corrections))
+}
+
+// POST /api/v1/admin/timesheets/corrections/:id/resolve
+// Body: { "status": "RESOLVED", "resolution": "Added check-out at 18:00" }
+// Closing the last open correction of a disputed month returns it to PENDING
+func (h *Handler) AdminResolveCorrection(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	var req ResolveCorrectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	correction, signOff, err := h.svc.CloseCorrection(c.Context(), adminUser.ID, uint(id), req.Status, req.Resolution)
+	if err != nil {
+		return err
+	}
+	res := toCorrectionResponse(correction)
+	h.logAction(c, adminUser.ID, req.Status, "timesheet_correction", correction.ID, res, req.Resolution)
+	return response.OK(c, map[string]interface{}{
+		"correction": res,
+		"signOff":    toSignOffResponse(signOff),
+	})
+}
+
+func (h *Handler) logAction(c *fiber.Ctx, adminID uint, action, entityType string, entityID uint, after interface{}, reason string) {
+	if h.auditSvc == nil {
+		return
+	}
+	_ = h.auditSvc.LogAdminAction(
+		c.Context(),
+		adminID,
+		action,
+		entityType,
+		strconv.FormatUint(uint64(entityID), 10),
+		nil,
+		after,
+		reason,
+	)
+}
+
+func parseUserID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("userId"), 10, 64)
+	if err != nil {
+		return 0, response.Validation("invalid userId", nil)
+	}
+	return uint(id), nil
+}
+
+func parseYearMonth(c *fiber.Ctx) (int, int, error) {
+	year, err := strconv.Atoi(c.Params("year"))
+	if err != nil {
+		return 0, 0, response.Validation("invalid year", nil)
+	}
+	month, err := strconv.Atoi(c.Params("month"))
+	if err != nil || month < 1 || month > 12 {
+		return 0, 0, response.Validation("invalid month", nil)
+	}
+	return year, month, nil
+}
+
+func parseDispute(c *fiber.Ctx) ([]CorrectionItem, error) {
+	var req DisputeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, response.Validation("Invalid body", nil)
+	}
+	if len(req.Items) == 0 {
+		return nil, response.Validation("at least one item is required", nil)
+	}
+
+	items := make([]CorrectionItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i].Message = item.Message
+		if item.WorkDate != nil && *item.WorkDate != "" {
+			d, err := time.Parse("2006-01-02", *item.WorkDate)
+			if err != nil {
+				return nil, response.Validation("workDate must be YYYY-MM-DD", nil)
+			}
+			items[i].WorkDate = &d
+		}
+	}
+	return items, nil
+}
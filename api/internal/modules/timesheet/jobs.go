@@ -0,0 +1,97 @@
+package timesheet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/platform/mailer"
+	"time-attendance-be/internal/platform/scheduler"
+
+	"go.uber.org/zap"
+)
+
+// RegisterJobs puts the sign-off reminders on the scheduler (cron in APP_TZ)
+func (s *Service) RegisterJobs(sch *scheduler.Scheduler) {
+	sch.MustRegister(scheduler.Job{
+		Name:        "timesheet.signoff-reminders",
+		Description: "Open last month's timesheet sign-offs and remind employees who have not confirmed",
+		Schedule:    "0 9 * * 1-5",
+		Run:         s.SendReminders,
+	})
+}
+
+// SendReminders opens sign-offs for last month and emails employees who have not confirmed it.
+// Months waiting for approval are listed in the admin overview; department managers
+// countersign them under /manager/timesheets.
+// A failed email is logged and retried on the next run.
+func (s *Service) SendReminders(ctx context.Context) (scheduler.Result, error) {
+	var res scheduler.Result
+	now := time.Now().In(s.cfg.TimeLocation())
+	prev := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	year, month := prev.Year(), int(prev.Month())
+
+	users, err := s.userRepo.GetAllActiveUsers(ctx)
+	if err != nil {
+		return res, err
+	}
+	userIDs := make([]uint, len(users))
+	usersByID := make(map[uint]*user.User, len(users))
+	for i := range users {
+		userIDs[i] = users[i].ID
+		usersByID[users[i].ID] = &users[i]
+	}
+	if err := s.repo.EnsurePending(ctx, userIDs, year, month); err != nil {
+		return res, err
+	}
+
+	pending, err := s.repo.ListByStatus(ctx, year, month, StatusPending)
+	if err != nil {
+		return res, err
+	}
+	awaitingManager, err := s.repo.ListByStatus(ctx, year, month, StatusEmployeeConfirmed)
+	if err != nil {
+		return res, err
+	}
+
+	ids := make([]uint, 0, len(pending))
+	for i := range pending {
+		u := usersByID[pending[i].UserID]
+		if u == nil {
+			continue
+		}
+		if err := s.mailer.Send(ctx, reminderMessage(u, year, month)); err != nil {
+			s.logger.Error("failed to send timesheet reminder", zap.Uint("userID", u.ID), zap.Error(err))
+			res.Failed++
+			continue
+		}
+		ids = append(ids, pending[i].ID)
+	}
+	if err := s.repo.MarkReminded(ctx, ids); err != nil {
+		return res, err
+	}
+
+	s.logger.Info("timesheet sign-off reminders",
+		zap.Int("year", year),
+		zap.Int("month", month),
+		zap.Int("awaitingEmployee", len(pending)),
+		zap.Int("awaitingManager", len(awaitingManager)))
+
+	res.Processed = len(ids)
+	res.Message = fmt.Sprintf("%d-%02d: %d awaiting employee (%d reminded), %d awaiting manager", year, month, len(pending), len(ids), len(awaitingManager))
+	return res, nil
+}
+
+// reminderMessage asks an employee to confirm or dispute a month
+func reminderMessage(u *user.User, year, month int) mailer.Message {
+	return mailer.Message{
+		To:      []string{u.Email},
+		Subject: fmt.Sprintf("Please confirm your timesheet for %d-%02d", year, month),
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"Your timesheet for %d-%02d is waiting for your confirmation. "+
+			"Please review your attendance and leave for the month and confirm it, "+
+			"or dispute the days that are wrong.\n",
+			u.Name, year, month),
+	}
+}
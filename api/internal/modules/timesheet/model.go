@@ -0,0 +1,81 @@
+package timesheet
+
+import "time"
+
+// SignOff is the monthly timesheet sign-off of one user (table timesheet_signoffs).
+//
+//	PENDING --confirm--> EMPLOYEE_CONFIRMED --approve--> MANAGER_APPROVED
+//	PENDING / EMPLOYEE_CONFIRMED --dispute/reject--> DISPUTED --all corrections closed--> PENDING
+//	EMPLOYEE_CONFIRMED / MANAGER_APPROVED --reopen--> PENDING
+//
+// While EMPLOYEE_CONFIRMED or MANAGER_APPROVED the month's attendance is locked.
+type SignOff struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"not null;uniqueIndex:idx_signoff_user_month"`
+	Year   int    `gorm:"not null;uniqueIndex:idx_signoff_user_month;index:idx_signoff_month"`
+	Month  int    `gorm:"not null;uniqueIndex:idx_signoff_user_month;index:idx_signoff_month"`
+	Status string `gorm:"type:enum('PENDING','EMPLOYEE_CONFIRMED','MANAGER_APPROVED','DISPUTED');not null;default:'PENDING';index"`
+
+	// Figures the employee confirmed (leave_monthly_summary at confirmation time)
+	ExpectedUnits     float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	WorkedUnits       float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	MissingUnits      float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	BirthdayUsedUnits float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	PaidUsedUnits     float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	CompOffUsedUnits  float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+	UnpaidUnits       float64 `gorm:"type:decimal(6,2);not null;default:0.0"`
+
+	EmployeeConfirmedAt *time.Time
+	ManagerApprovedBy   *uint
+	ManagerApprovedAt   *time.Time
+	ReopenedBy          *uint
+	ReopenedAt          *time.Time
+	ReopenReason        *string `gorm:"type:varchar(255)"`
+	LastRemindedAt      *time.Time
+	ReminderCount       int       `gorm:"not null;default:0"`
+	CreatedAt           time.Time `gorm:"not null"`
+	UpdatedAt           time.Time `gorm:"not null"`
+}
+
+func (SignOff) TableName() string {
+	return "timesheet_signoffs"
+}
+
+const (
+	StatusPending           = "PENDING"
+	StatusEmployeeConfirmed = "EMPLOYEE_CONFIRMED"
+	StatusManagerApproved   = "MANAGER_APPROVED"
+	StatusDisputed          = "DISPUTED"
+)
+
+// IsLocked reports whether the month is signed and its attendance must not change
+func (s *SignOff) IsLocked() bool {
+	return s.Status == StatusEmployeeConfirmed || s.Status == StatusManagerApproved
+}
+
+// CorrectionRequest is one disputed item of a sign-off, raised by the employee (dispute)
+// or the manager (reject), and closed by an admin after fixing (or declining) it.
+type CorrectionRequest struct {
+	ID         uint       `gorm:"primaryKey"`
+	SignOffID  uint       `gorm:"not null;index"`
+	UserID     uint       `gorm:"not null;index"`
+	WorkDate   *time.Time `gorm:"type:date"` // nil = concerns the whole month
+	Message    string     `gorm:"type:text;not null"`
+	RaisedBy   uint       `gorm:"not null"`
+	Status     string     `gorm:"type:enum('OPEN','RESOLVED','REJECTED');not null;default:'OPEN';index"`
+	Resolution *string    `gorm:"type:text"`
+	ResolvedBy *uint
+	ResolvedAt *time.Time
+	CreatedAt  time.Time `gorm:"not null"`
+	UpdatedAt  time.Time `gorm:"not null"`
+}
+
+func (CorrectionRequest) TableName() string {
+	return "timesheet_corrections"
+}
+
+const (
+	CorrectionOpen     = "OPEN"
+	CorrectionResolved = "RESOLVED"
+	CorrectionRejected = "REJECTED"
+)
@@ -0,0 +1,184 @@
+package timesheet
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) *Repo { return &Repo{db: db} }
+
+// Get returns the sign-off for a user/month, or nil if none was created yet
+func (r *Repo) Get(ctx context.Context, userID uint, year, month int) (*SignOff, error) {
+	var s SignOff
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND year = ? AND month = ?", userID, year, month).
+		First(&s).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetOrCreate returns the sign-off for a user/month, creating it as PENDING if missing
+func (r *Repo) GetOrCreate(ctx context.Context, userID uint, year, month int) (*SignOff, error) {
+	now := time.Now()
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&SignOff{
+		UserID:    userID,
+		Year:      year,
+		Month:     month,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, userID, year, month)
+}
+
+// EnsurePending creates PENDING sign-offs for the users that have none for the month
+func (r *Repo) EnsurePending(ctx context.Context, userIDs []uint, year, month int) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	rows := make([]SignOff, len(userIDs))
+	for i, id := range userIDs {
+		rows[i] = SignOff{UserID: id, Year: year, Month: month, Status: StatusPending, CreatedAt: now, UpdatedAt: now}
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, 500).Error
+}
+
+// Save updates a sign-off
+func (r *Repo) Save(ctx context.Context, s *SignOff) error {
+	return r.db.WithContext(ctx).Save(s).Error
+}
+
+// ListByMonth returns all sign-offs of a month
+func (r *Repo) ListByMonth(ctx context.Context, year, month int) ([]SignOff, error) {
+	var rows []SignOff
+	err := r.db.WithContext(ctx).Where("year = ? AND month = ?", year, month).Find(&rows).Error
+	return rows, err
+}
+
+// ListByUser returns a user's sign-offs, most recent month first
+func (r *Repo) ListByUser(ctx context.Context, userID uint, limit int) ([]SignOff, error) {
+	var rows []SignOff
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("year DESC, month DESC").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+// ListByStatus returns the sign-offs of a month in the given status
+func (r *Repo) ListByStatus(ctx context.Context, year, month int, status string) ([]SignOff, error) {
+	var rows []SignOff
+	err := r.db.WithContext(ctx).
+		Where("year = ? AND month = ? AND status = ?", year, month, status).
+		Find(&rows).Error
+	return rows, err
+}
+
+// MarkReminded records that the owners of the given sign-offs were reminded
+func (r *Repo) MarkReminded(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&SignOff{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"last_reminded_at": now,
+			"reminder_count":   gorm.Expr("reminder_count + 1"),
+			"updated_at":       now,
+		}).Error
+}
+
+// Dispute saves the sign-off as DISPUTED together with its correction requests
+func (r *Repo) Dispute(ctx context.Context, s *SignOff, corrections []CorrectionRequest) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(s).Error; err != nil {
+			return err
+		}
+		for i := range corrections {
+			corrections[i].SignOffID = s.ID
+		}
+		return tx.Create(&corrections).Error
+	})
+}
+
+// CorrectionFilter filters correction request listings
+type CorrectionFilter struct {
+	Status    string
+	UserID    *uint
+	SignOffID *uint
+}
+
+// ListCorrections returns correction requests, oldest first
+func (r *Repo) ListCorrections(ctx context.Context, filter CorrectionFilter) ([]CorrectionRequest, error) {
+	q := r.db.WithContext(ctx).Model(&CorrectionRequest{})
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != nil {
+		q = q.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.SignOffID != nil {
+		q = q.Where("sign_off_id = ?", *filter.SignOffID)
+	}
+	var rows []CorrectionRequest
+	err := q.Order("created_at ASC, id ASC").Find(&rows).Error
+	return rows, err
+}
+
+// GetCorrection returns a correction request by ID
+func (r *Repo) GetCorrection(ctx context.Context, id uint) (*CorrectionRequest, error) {
+	var c CorrectionRequest
+	if err := r.db.WithContext(ctx).First(&c, id).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CloseCorrection stores a resolved/rejected correction. When it was the last open one of a
+// DISPUTED sign-off, the sign-off goes back to PENDING for the employee to confirm again.
+// Returns the sign-off as it is afterwards.
+func (r *Repo) CloseCorrection(ctx context.Context, c *CorrectionRequest) (*SignOff, error) {
+	var s SignOff
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(c).Error; err != nil {
+			return err
+		}
+		var open int64
+		if err := tx.Model(&CorrectionRequest{}).
+			Where("sign_off_id = ? AND status = ?", c.SignOffID, CorrectionOpen).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&s, c.SignOffID).Error; err != nil {
+			return err
+		}
+		if open == 0 && s.Status == StatusDisputed {
+			s.Status = StatusPending
+			s.UpdatedAt = time.Now()
+			return tx.Save(&s).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
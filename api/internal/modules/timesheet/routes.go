@@ -0,0 +1,48 @@
+package timesheet
+
+import (
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Module struct {
+	h *Handler
+	s *Service
+}
+
+func NewModule(svc *Service, auditSvc *audit.Service) *Module {
+	return &Module{h: NewHandler(svc, auditSvc), s: svc}
+}
+
+func (m *Module) Service() *Service {
+	return m.s
+}
+
+func (m *Module) RegisterMe(v1 fiber.Router, auth fiber.Handler) {
+	g := v1.Group("/me/timesheets", auth)
+	g.Get("/", m.h.ListMine)
+	g.Get("/:year/:month", m.h.GetMine)
+	g.Post("/:year/:month/confirm", m.h.ConfirmMine)
+	g.Post("/:year/:month/dispute", m.h.DisputeMine)
+}
+
+// RegisterManager lets department managers review and countersign their members' months
+func (m *Module) RegisterManager(mgr fiber.Router) {
+	g := mgr.Group("/timesheets")
+	g.Get("/:userId/:year/:month", m.h.ManagerGet)
+	g.Post("/:userId/:year/:month/approve", m.h.ManagerApprove)
+	g.Post("/:userId/:year/:month/reject", m.h.ManagerReject)
+}
+
+func (m *Module) RegisterAdmin(admin fiber.Router) {
+	g := admin.Group("/timesheets")
+	g.Get("/", authx.Require(authx.PermTimesheetsRead), m.h.AdminOverview)
+	g.Get("/corrections", authx.Require(authx.PermTimesheetsApprove), m.h.AdminListCorrections)
+	g.Post("/corrections/:id/resolve", authx.Require(authx.PermTimesheetsApprove), m.h.AdminResolveCorrection)
+	g.Get("/:userId/:year/:month", authx.Require(authx.PermTimesheetsRead), m.h.AdminGet)
+	g.Post("/:userId/:year/:month/approve", authx.Require(authx.PermTimesheetsApprove), m.h.AdminApprove)
+	g.Post("/:userId/:year/:month/reject", authx.Require(authx.PermTimesheetsApprove), m.h.AdminReject)
+	g.Post("/:userId/:year/:month/reopen", authx.Require(authx.PermTimesheetsApprove), m.h.AdminReopen)
+}
@@ -0,0 +1,371 @@
+package timesheet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/config"
+	"time-attendance-be/internal/modules/attendance"
+	"time-attendance-be/internal/modules/leave"
+	"time-attendance-be/internal/modules/user"
+	"time-attendance-be/internal/modules/workcalendar"
+	"time-attendance-be/internal/pkg/response"
+	"time-attendance-be/internal/platform/mailer"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Service struct {
+	cfg      *config.Config
+	repo     *Repo
+	userRepo *user.Repo
+	attRepo  *attendance.Repo
+	leaveSvc *leave.Service
+	rules    *workcalendar.Service
+	mailer   mailer.Mailer
+	logger   *zap.Logger
+}
+
+func NewService(cfg *config.Config, repo *Repo, userRepo *user.Repo, attRepo *attendance.Repo, leaveSvc *leave.Service, rules *workcalendar.Service, mail mailer.Mailer, logger *zap.Logger) *Service {
+	return &Service{
+		cfg:      cfg,
+		repo:     repo,
+		userRepo: userRepo,
+		attRepo:  attRepo,
+		leaveSvc: leaveSvc,
+		rules:    rules,
+		mailer:   mail,
+		logger:   logger,
+	}
+}
+
+// Review is what an employee (or their manager) looks at before signing a month
+type Review struct {
+	SignOff     *SignOff
+	Breakdown   *leave.MonthlyBreakdown
+	Sessions    []attendance.Session
+	Corrections []CorrectionRequest
+}
+
+// GetReview returns the sign-off state, current figures, sessions and corrections for a user/month
+func (s *Service) GetReview(ctx context.Context, userID uint, year, month int) (*Review, error) {
+	signOff, err := s.repo.Get(ctx, userID, year, month)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if signOff == nil {
+		signOff = &SignOff{UserID: userID, Year: year, Month: month, Status: StatusPending}
+	}
+
+	breakdown, err := s.leaveSvc.ProjectMonthlyBreakdown(ctx, userID, year, month)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.cfg.TimeLocation())
+	sessions, err := s.attRepo.ListByUserDateRange(userID, start.Format("2006-01-02"), start.AddDate(0, 1, -1).Format("2006-01-02"))
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+
+	corrections := []CorrectionRequest{}
+	if signOff.ID != 0 {
+		corrections, err = s.repo.ListCorrections(ctx, CorrectionFilter{SignOffID: &signOff.ID})
+		if err != nil {
+			return nil, response.Internal(err)
+		}
+	}
+
+	return &Review{SignOff: signOff, Breakdown: breakdown, Sessions: sessions, Corrections: corrections}, nil
+}
+
+// ListMine returns the user's recent sign-offs
+func (s *Service) ListMine(ctx context.Context, userID uint) ([]SignOff, error) {
+	return s.repo.ListByUser(ctx, userID, 24)
+}
+
+// Confirm records the employee's confirmation of a finished month and snapshots its figures
+func (s *Service) Confirm(ctx context.Context, userID uint, year, month int) (*SignOff, error) {
+	if err := s.checkMonthOver(year, month); err != nil {
+		return nil, err
+	}
+	signOff, err := s.repo.GetOrCreate(ctx, userID, year, month)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if signOff.Status != StatusPending {
+		return nil, response.Conflict(fmt.Sprintf("Timesheet is %s and cannot be confirmed", signOff.Status))
+	}
+
+	summary, err := s.leaveSvc.ComputeMonthlySummary(ctx, userID, year, month, leave.SnapshotTriggerSignOff)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+
+	now := time.Now()
+	signOff.Status = StatusEmployeeConfirmed
+	signOff.ExpectedUnits = summary.ExpectedUnits
+	signOff.WorkedUnits = summary.WorkedUnits
+	signOff.MissingUnits = summary.MissingUnits
+	signOff.BirthdayUsedUnits = summary.BirthdayUsedUnits
+	signOff.PaidUsedUnits = summary.PaidUsedUnits
+	signOff.CompOffUsedUnits = summary.CompOffUsedUnits
+	signOff.UnpaidUnits = summary.UnpaidUnits
+	signOff.EmployeeConfirmedAt = &now
+	signOff.UpdatedAt = now
+	if err := s.repo.Save(ctx, signOff); err != nil {
+		return nil, response.Internal(err)
+	}
+	return signOff, nil
+}
+
+// CorrectionItem is one disputed point
+type CorrectionItem struct {
+	WorkDate *time.Time
+	Message  string
+}
+
+// Dispute turns the employee's objections into correction requests. Allowed until the manager approves.
+func (s *Service) Dispute(ctx context.Context, userID uint, year, month int, items []CorrectionItem) (*SignOff, error) {
+	if err := s.checkMonthOver(year, month); err != nil {
+		return nil, err
+	}
+	signOff, err := s.repo.GetOrCreate(ctx, userID, year, month)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if signOff.Status != StatusPending && signOff.Status != StatusEmployeeConfirmed {
+		return nil, response.Conflict(fmt.Sprintf("Timesheet is %s and cannot be disputed", signOff.Status))
+	}
+	return s.dispute(ctx, signOff, userID, items)
+}
+
+// Approve is the manager's countersignature of an employee-confirmed month. Leave breaching a
+// REJECT blackout/staffing rule can't be approved; leave breaching an ESCALATE rule needs an
+// escalated approver (timesheets.approve holders, not department managers).
+func (s *Service) Approve(ctx context.Context, managerID, userID uint, year, month int, escalated bool) (*SignOff, error) {
+	if managerID == userID {
+		return nil, response.Forbidden("You cannot approve your own timesheet")
+	}
+	signOff, err := s.getExisting(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	if signOff.Status != StatusEmployeeConfirmed {
+		return nil, response.Conflict("Only employee-confirmed timesheets can be approved")
+	}
+
+	// The countersignature approves the month's leave, so it has to respect blackout and staffing rules
+	check, err := s.rules.CheckAbsences(ctx, userID, year, month)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if !check.Allowed {
+		return nil, response.Conflict("Leave in this month breaches leave rules (" + check.Summary() + "); reject the timesheet so it can be corrected")
+	}
+	if check.RequiresEscalation && !escalated {
+		return nil, response.Forbidden("Leave in this month needs escalated approval (" + check.Summary() + ")")
+	}
+
+	now := time.Now()
+	signOff.Status = StatusManagerApproved
+	signOff.ManagerApprovedBy = &managerID
+	signOff.ManagerApprovedAt = &now
+	signOff.UpdatedAt = now
+	if err := s.repo.Save(ctx, signOff); err != nil {
+		return nil, response.Internal(err)
+	}
+	return signOff, nil
+}
+
+// Reject is the manager disputing an employee-confirmed month; the reason becomes a correction request
+func (s *Service) Reject(ctx context.Context, managerID, userID uint, year, month int, items []CorrectionItem) (*SignOff, error) {
+	if managerID == userID {
+		return nil, response.Forbidden("You cannot reject your own timesheet")
+	}
+	signOff, err := s.getExisting(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	if signOff.Status != StatusEmployeeConfirmed {
+		return nil, response.Conflict("Only employee-confirmed timesheets can be rejected")
+	}
+	return s.dispute(ctx, signOff, managerID, items)
+}
+
+// Reopen unlocks a signed month so attendance can be corrected; the employee has to confirm again
+func (s *Service) Reopen(ctx context.Context, adminID, userID uint, year, month int, reason string) (*SignOff, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, response.Validation("reason is required", nil)
+	}
+	signOff, err := s.getExisting(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	if !signOff.IsLocked() {
+		return nil, response.Conflict("Only confirmed or approved timesheets can be reopened")
+	}
+
+	now := time.Now()
+	signOff.Status = StatusPending
+	signOff.EmployeeConfirmedAt = nil
+	signOff.ManagerApprovedBy = nil
+	signOff.ManagerApprovedAt = nil
+	signOff.ReopenedBy = &adminID
+	signOff.ReopenedAt = &now
+	signOff.ReopenReason = &reason
+	signOff.UpdatedAt = now
+	if err := s.repo.Save(ctx, signOff); err != nil {
+		return nil, response.Internal(err)
+	}
+	return signOff, nil
+}
+
+// ListCorrections lists correction requests
+func (s *Service) ListCorrections(ctx context.Context, filter CorrectionFilter) ([]CorrectionRequest, error) {
+	return s.repo.ListCorrections(ctx, filter)
+}
+
+// CloseCorrection resolves or rejects a correction request
+func (s *Service) CloseCorrection(ctx context.Context, adminID, id uint, status, resolution string) (*CorrectionRequest, *SignOff, error) {
+	if status != CorrectionResolved && status != CorrectionRejected {
+		return nil, nil, response.Validation("status must be RESOLVED or REJECTED", nil)
+	}
+	if strings.TrimSpace(resolution) == "" {
+		return nil, nil, response.Validation("resolution is required", nil)
+	}
+
+	c, err := s.repo.GetCorrection(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, response.NotFound("Correction request not found")
+		}
+		return nil, nil, response.Internal(err)
+	}
+	if c.Status != CorrectionOpen {
+		return nil, nil, response.Conflict("Correction request is already closed")
+	}
+
+	now := time.Now()
+	c.Status = status
+	c.Resolution = &resolution
+	c.ResolvedBy = &adminID
+	c.ResolvedAt = &now
+	c.UpdatedAt = now
+	signOff, err := s.repo.CloseCorrection(ctx, c)
+	if err != nil {
+		return nil, nil, response.Internal(err)
+	}
+	return c, signOff, nil
+}
+
+// OverviewRow is one active user's sign-off state for a month
+type OverviewRow struct {
+	User    user.User
+	SignOff *SignOff // nil = not started (PENDING)
+}
+
+// Overview lists active users (optionally of one department) with their sign-off for the month
+func (s *Service) Overview(ctx context.Context, year, month int, departmentID *uint) ([]OverviewRow, error) {
+	var users []user.User
+	var err error
+	if departmentID != nil {
+		users, err = s.userRepo.ListActiveByDepartment(ctx, *departmentID)
+	} else {
+		users, err = s.userRepo.GetAllActiveUsers(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signOffs, err := s.repo.ListByMonth(ctx, year, month)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[uint]*SignOff, len(signOffs))
+	for i := range signOffs {
+		byUser[signOffs[i].UserID] = &signOffs[i]
+	}
+
+	rows := make([]OverviewRow, len(users))
+	for i := range users {
+		rows[i] = OverviewRow{User: users[i], SignOff: byUser[users[i].ID]}
+	}
+	return rows, nil
+}
+
+// UserDepartment returns the user's department, for department-scoped access checks
+func (s *Service) UserDepartment(ctx context.Context, userID uint) (*uint, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFound("User not found")
+		}
+		return nil, response.Internal(err)
+	}
+	return u.DepartmentID, nil
+}
+
+// IsMonthLocked reports whether the user's timesheet for the month of workDate is signed
+// (implements attendance.MonthLock)
+func (s *Service) IsMonthLocked(ctx context.Context, userID uint, workDate time.Time) (bool, error) {
+	signOff, err := s.repo.Get(ctx, userID, workDate.Year(), int(workDate.Month()))
+	if err != nil {
+		return false, err
+	}
+	return signOff != nil && signOff.IsLocked(), nil
+}
+
+func (s *Service) dispute(ctx context.Context, signOff *SignOff, raisedBy uint, items []CorrectionItem) (*SignOff, error) {
+	if len(items) == 0 {
+		return nil, response.Validation("at least one item is required", nil)
+	}
+	now := time.Now()
+	corrections := make([]CorrectionRequest, 0, len(items))
+	for _, item := range items {
+		if strings.TrimSpace(item.Message) == "" {
+			return nil, response.Validation("message is required for every item", nil)
+		}
+		corrections = append(corrections, CorrectionRequest{
+			UserID:    signOff.UserID,
+			WorkDate:  item.WorkDate,
+			Message:   item.Message,
+			RaisedBy:  raisedBy,
+			Status:    CorrectionOpen,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	signOff.Status = StatusDisputed
+	signOff.EmployeeConfirmedAt = nil
+	signOff.UpdatedAt = now
+	if err := s.repo.Dispute(ctx, signOff, corrections); err != nil {
+		return nil, response.Internal(err)
+	}
+	return signOff, nil
+}
+
+func (s *Service) getExisting(ctx context.Context, userID uint, year, month int) (*SignOff, error) {
+	signOff, err := s.repo.Get(ctx, userID, year, month)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if signOff == nil {
+		return nil, response.NotFound("Timesheet sign-off not found")
+	}
+	return signOff, nil
+}
+
+// checkMonthOver rejects sign-off actions on months that have not ended yet
+func (s *Service) checkMonthOver(year, month int) error {
+	loc := s.cfg.TimeLocation()
+	if !time.Now().In(loc).After(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc).AddDate(0, 1, 0).Add(-time.Nanosecond)) {
+		return response.Validation("The month is not over yet", nil)
+	}
+	return nil
+}
@@ -38,38 +38,55 @@ func (bd BirthdayDate) MarshalJSON() ([]byte, error) {
 
 // UserCreateInput represents the input for creating a user.
 type UserCreateInput struct {
-	Name         string        `json:"name" validate:"required,min=2,max=120"`
-	Email        string        `json:"email" validate:"required,email,max=190"`
-	Password     string        `json:"password" validate:"required,min=8,max=100"`
-	Role         string        `json:"role" validate:"omitempty,oneof=user admin"`
-	Status       string        `json:"status" validate:"omitempty,oneof=active disabled"`
-	DepartmentID *uint         `json:"departmentId" validate:"omitempty"`
-	Birthday     *BirthdayDate `json:"birthday" validate:"omitempty"` // Format: "2006-01-02"
+	Name            string        `json:"name" validate:"required,min=2,max=120"`
+	Email           string        `json:"email" validate:"required,email,max=190"`
+	Password        string        `json:"password" validate:"required,min=8,max=100"`
+	Role            string        `json:"role" validate:"omitempty,oneof=user admin"`
+	Status          string        `json:"status" validate:"omitempty,oneof=active disabled"`
+	DepartmentID    *uint         `json:"departmentId" validate:"omitempty"`
+	Birthday        *BirthdayDate `json:"birthday" validate:"omitempty"` // Format: "2006-01-02"
+	HireDate        *BirthdayDate `json:"hireDate" validate:"omitempty"` // Format: "2006-01-02"
+	AccrualPolicyID *uint         `json:"accrualPolicyId" validate:"omitempty"`
+	EmployeeCode    *string       `json:"employeeCode" validate:"omitempty,max=50"`
+	JobTitle        *string       `json:"jobTitle" validate:"omitempty,max=120"`
+	EmploymentType  string        `json:"employmentType" validate:"omitempty,oneof=full_time part_time contract intern"`
 }
 
-// UserUpdateInput represents the input for updating a user.
+// UserUpdateInput represents the input for updating a user. The termination date is set by
+// offboarding only (POST /admin/offboarding).
 type UserUpdateInput struct {
-	Name         *string       `json:"name" validate:"omitempty,min=2,max=120"`
-	Email        *string       `json:"email" validate:"omitempty,email,max=190"`
-	Password     *string       `json:"password" validate:"omitempty,min=8,max=100"`
-	Role         *string       `json:"role" validate:"omitempty,oneof=user admin"`
-	Status       *string       `json:"status" validate:"omitempty,oneof=active disabled"`
-	DepartmentID *uint         `json:"departmentId" validate:"omitempty"`
-	Birthday     *BirthdayDate `json:"birthday" validate:"omitempty"` // Format: "2006-01-02"
+	Name            *string       `json:"name" validate:"omitempty,min=2,max=120"`
+	Email           *string       `json:"email" validate:"omitempty,email,max=190"`
+	Password        *string       `json:"password" validate:"omitempty,min=8,max=100"`
+	Role            *string       `json:"role" validate:"omitempty,oneof=user admin"`
+	Status          *string       `json:"status" validate:"omitempty,oneof=active disabled"`
+	DepartmentID    *uint         `json:"departmentId" validate:"omitempty"`
+	Birthday        *BirthdayDate `json:"birthday" validate:"omitempty"`            // Format: "2006-01-02"
+	HireDate        *BirthdayDate `json:"hireDate" validate:"omitempty"`            // Format: "2006-01-02"
+	AccrualPolicyID *uint         `json:"accrualPolicyId" validate:"omitempty"`     // 0 = bỏ gán chính sách riêng
+	EmployeeCode    *string       `json:"employeeCode" validate:"omitempty,max=50"` // "" = bỏ mã nhân viên
+	JobTitle        *string       `json:"jobTitle" validate:"omitempty,max=120"`
+	EmploymentType  *string       `json:"employmentType" validate:"omitempty,oneof=full_time part_time contract intern"`
 }
 
 // UserResponse represents the user data sent to clients.
 type UserResponse struct {
-	ID             uint       `json:"id"`
-	Name           string     `json:"name"`
-	Email          string     `json:"email"`
-	Role           string     `json:"role"`
-	Status         string     `json:"status"`
-	DepartmentID   *uint      `json:"departmentId"`
-	DepartmentName *string    `json:"departmentName"`
-	Birthday       *time.Time `json:"birthday"`  // Format: "2006-01-02"
-	PaidLeave      float64    `json:"paidLeave"` // Số ngày nghỉ phép
-	CreatedAt      time.Time  `json:"createdAt"`
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	Status          string     `json:"status"`
+	DepartmentID    *uint      `json:"departmentId"`
+	DepartmentName  *string    `json:"departmentName"`
+	Birthday        *time.Time `json:"birthday"`  // Format: "2006-01-02"
+	PaidLeave       float64    `json:"paidLeave"` // Số ngày nghỉ phép
+	HireDate        *time.Time `json:"hireDate"`
+	TerminationDate *time.Time `json:"terminationDate"`
+	AccrualPolicyID *uint      `json:"accrualPolicyId"`
+	EmployeeCode    *string    `json:"employeeCode"`
+	JobTitle        *string    `json:"jobTitle"`
+	EmploymentType  string     `json:"employmentType"`
+	CreatedAt       time.Time  `json:"createdAt"`
 }
 
 func ToUserResponse(u *User) UserResponse {
@@ -78,16 +95,22 @@ func ToUserResponse(u *User) UserResponse {
 		deptName = &u.Department.Name
 	}
 	return UserResponse{
-		ID:             u.ID,
-		Name:           u.Name,
-		Email:          u.Email,
-		Role:           u.Role,
-		Status:         u.Status,
-		DepartmentID:   u.DepartmentID,
-		DepartmentName: deptName,
-		Birthday:       u.Birthday,
-		PaidLeave:      u.PaidLeave,
-		CreatedAt:      u.CreatedAt,
+		ID:              u.ID,
+		Name:            u.Name,
+		Email:           u.Email,
+		Role:            u.Role,
+		Status:          u.Status,
+		DepartmentID:    u.DepartmentID,
+		DepartmentName:  deptName,
+		Birthday:        u.Birthday,
+		PaidLeave:       u.PaidLeave,
+		HireDate:        u.HireDate,
+		TerminationDate: u.TerminationDate,
+		AccrualPolicyID: u.AccrualPolicyID,
+		EmployeeCode:    u.EmployeeCode,
+		JobTitle:        u.JobTitle,
+		EmploymentType:  u.EmploymentType,
+		CreatedAt:       u.CreatedAt,
 	}
 }
 
@@ -102,6 +125,10 @@ type MeResponse struct {
 	DepartmentName *string    `json:"departmentName"`
 	Birthday       *time.Time `json:"birthday"`  // Format: "2006-01-02"
 	PaidLeave      float64    `json:"paidLeave"` // Số ngày nghỉ phép
+	HireDate       *time.Time `json:"hireDate"`
+	EmployeeCode   *string    `json:"employeeCode"`
+	JobTitle       *string    `json:"jobTitle"`
+	EmploymentType string     `json:"employmentType"`
 }
 
 func ToMeResponse(u *User) MeResponse {
@@ -119,6 +146,10 @@ func ToMeResponse(u *User) MeResponse {
 		DepartmentName: deptName,
 		Birthday:       u.Birthday,
 		PaidLeave:      u.PaidLeave,
+		HireDate:       u.HireDate,
+		EmployeeCode:   u.EmployeeCode,
+		JobTitle:       u.JobTitle,
+		EmploymentType: u.EmploymentType,
 	}
 }
 
@@ -2,6 +2,7 @@ package user
 
 import (
 	"strconv"
+	"strings"
 
 	"time-attendance-be/internal/authx"
 	"time-attendance-be/internal/modules/audit"
@@ -36,7 +37,12 @@ func (h *Handler) Me(c *fiber.Ctx) error {
 }
 
 // GET /api/v1/admin/users
+// Holders of a department-scoped users.read only see users of their departments
 func (h *Handler) AdminList(c *fiber.Ctx) error {
+	scope, err := authx.PermissionScope(c, authx.PermUsersRead)
+	if err != nil {
+		return err
+	}
 	p := pagination.Parse(c, 1, 10, 100)
 	_, limit := pagination.OffsetLimit(p)
 
@@ -48,8 +54,15 @@ func (h *Handler) AdminList(c *fiber.Ctx) error {
 			deptID = &u
 		}
 	}
+	var deptIDs []uint
+	if !scope.All {
+		if deptID != nil && !scope.Allows(deptID) {
+			return response.Forbidden("You can only view users of your departments")
+		}
+		deptIDs = scope.Departments
+	}
 
-	rows, total, err := h.svc.List(c.Context(), query, deptID, p.Page, limit)
+	rows, total, err := h.svc.List(c.Context(), query, deptID, deptIDs, p.Page, limit)
 	if err != nil {
 		return response.Internal(err)
 	}
@@ -79,6 +92,11 @@ func (h *Handler) AdminCreate(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.Validation("Invalid body", nil)
 	}
+	if req.Role == "admin" {
+		if err := requireRolesManage(c); err != nil {
+			return err
+		}
+	}
 	created, err := h.svc.Create(c.Context(), req)
 	if err != nil {
 		return mapErr(err)
@@ -111,6 +129,13 @@ func (h *Handler) AdminGet(c *fiber.Ctx) error {
 		}
 		return response.Internal(err)
 	}
+	scope, err := authx.PermissionScope(c, authx.PermUsersRead)
+	if err != nil {
+		return err
+	}
+	if !scope.Allows(u.DepartmentID) {
+		return response.Forbidden("You can only view users of your departments")
+	}
 	return response.OK(c, ToUserResponse(u))
 }
 
@@ -138,6 +163,21 @@ func (h *Handler) AdminUpdate(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.Validation("Invalid body", nil)
 	}
+	if req.Role != nil && (before == nil || *req.Role != before.Role) {
+		if err := requireRolesManage(c); err != nil {
+			return err
+		}
+	}
+	// A new password or email (then a password reset) signs in as the user
+	if before != nil && (req.Password != nil || (req.Email != nil && !strings.EqualFold(*req.Email, before.Email))) {
+		covered, err := h.svc.CallerCovers(c.Context(), adminUser, before)
+		if err != nil {
+			return response.Internal(err)
+		}
+		if !covered {
+			return response.Forbidden("Changing the password or email of a user with permissions you don't hold requires the roles.manage permission")
+		}
+	}
 	u, err := h.svc.Update(c.Context(), uint(id), req)
 	if err != nil {
 		return mapErr(err)
@@ -230,6 +270,19 @@ func (h *Handler) AdminDelete(c *fiber.Ctx) error {
 	return response.OK(c, true)
 }
 
+// requireRolesManage guards account role changes: the admin account role grants every
+// permission, so setting it needs company-wide roles.manage
+func requireRolesManage(c *fiber.Ctx) error {
+	scope, err := authx.PermissionScope(c, authx.PermRolesManage)
+	if err != nil {
+		return err
+	}
+	if !scope.All {
+		return response.Forbidden("Changing the account role requires the roles.manage permission")
+	}
+	return nil
+}
+
 func mapErr(err error) error {
 	if fes := validator.AsFieldErrors(err); fes != nil {
 		return response.Validation("Validation error", fes)
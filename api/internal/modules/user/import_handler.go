@@ -0,0 +1,168 @@
+package user
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImportRowResponse is one line of the import report
+type ImportRowResponse struct {
+	Row      int           `json:"row"` // CSV line; 0 for users deactivated because they are missing
+	Email    string        `json:"email"`
+	Action   string        `json:"action"`
+	UserID   uint          `json:"userId,omitempty"`
+	Changes  []string      `json:"changes,omitempty"`
+	Errors   []string      `json:"errors,omitempty"`
+	Warnings []string      `json:"warnings,omitempty"`
+	User     *UserResponse `json:"user,omitempty"` // the user after the import
+}
+
+type ImportSummary struct {
+	Total       int `json:"total"`
+	Created     int `json:"created"`
+	Updated     int `json:"updated"`
+	Unchanged   int `json:"unchanged"`
+	Deactivated int `json:"deactivated"`
+	Errors      int `json:"errors"`
+}
+
+type ImportResponse struct {
+	DryRun  bool                `json:"dryRun"`
+	Applied bool                `json:"applied"`
+	Summary ImportSummary       `json:"summary"`
+	Rows    []ImportRowResponse `json:"rows"`
+}
+
+func toImportResponse(r *ImportReport) ImportResponse {
+	res := ImportResponse{
+		DryRun:  r.DryRun,
+		Applied: r.Applied,
+		Summary: ImportSummary{
+			Total:       len(r.Rows),
+			Created:     r.Count(ImportCreate),
+			Updated:     r.Count(ImportUpdate),
+			Unchanged:   r.Count(ImportUnchanged),
+			Deactivated: r.Count(ImportDeactivate),
+			Errors:      r.Count(ImportError),
+		},
+		Rows: make([]ImportRowResponse, len(r.Rows)),
+	}
+	for i, row := range r.Rows {
+		item := ImportRowResponse{
+			Row:      row.Row,
+			Email:    row.Email,
+			Action:   row.Action,
+			UserID:   row.UserID,
+			Changes:  row.Changes,
+			Errors:   row.Errors,
+			Warnings: row.Warnings,
+		}
+		if row.After != nil {
+			u := ToUserResponse(row.After)
+			item.User = &u
+		}
+		res.Rows[i] = item
+	}
+	return res
+}
+
+// POST /api/v1/admin/users/import?dryRun=true&deactivateMissing=false&reactivate=false
+// Body: multipart form with a "file" field, or the CSV itself (Content-Type: text/csv).
+// Columns: name, email, departmentCode, role, birthday, hireDate, initialPaidLeave.
+// Nothing is written on a dry run or when any row has errors.
+func (h *Handler) AdminImport(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	opts := ImportOptions{ActorID: adminUser.ID, Caller: adminUser}
+	var err error
+	if opts.DryRun, err = importFlag(c, "dryRun"); err != nil {
+		return err
+	}
+	if opts.DeactivateMissing, err = importFlag(c, "deactivateMissing"); err != nil {
+		return err
+	}
+	if opts.Reactivate, err = importFlag(c, "reactivate"); err != nil {
+		return err
+	}
+	scope, err := authx.PermissionScope(c, authx.PermRolesManage)
+	if err != nil {
+		return err
+	}
+	opts.AllowRoleChange = scope.All
+
+	var body io.Reader
+	if fh, ferr := c.FormFile("file"); ferr == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return response.Validation("Cannot read the uploaded file", nil)
+		}
+		defer f.Close()
+		body = f
+	} else if len(c.Body()) > 0 {
+		body = bytes.NewReader(c.Body())
+	} else {
+		return response.Validation("A CSV file is required", nil)
+	}
+
+	report, err := h.svc.Import(c.Context(), body, opts)
+	if err != nil {
+		return err
+	}
+
+	if report.Applied && h.auditSvc != nil {
+		for _, row := range report.Rows {
+			var action string
+			switch row.Action {
+			case ImportCreate:
+				action = "CREATE"
+			case ImportUpdate:
+				action = "UPDATE"
+			case ImportDeactivate:
+				action = "DEACTIVATE"
+			default:
+				continue
+			}
+			var before any
+			if row.Before != nil {
+				before = ToUserResponse(row.Before)
+			}
+			_ = h.auditSvc.LogAdminAction(
+				c.Context(),
+				adminUser.ID,
+				action,
+				"user",
+				strconv.FormatUint(uint64(row.UserID), 10),
+				before,
+				ToUserResponse(row.After),
+				"CSV import",
+			)
+		}
+	}
+
+	return response.OK(c, toImportResponse(report))
+}
+
+// importFlag reads a boolean from the query string or, for multipart uploads, the form
+func importFlag(c *fiber.Ctx, name string) (bool, error) {
+	v := c.Query(name)
+	if v == "" {
+		v = c.FormValue(name)
+	}
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, response.Validation("invalid "+name, nil)
+	}
+	return b, nil
+}
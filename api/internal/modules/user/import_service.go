@@ -0,0 +1,445 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+	"time-attendance-be/internal/pkg/security"
+)
+
+// maxImportRows caps the data rows of one CSV import
+const maxImportRows = 5000
+
+// Import columns
+const (
+	colName      = "name"
+	colEmail     = "email"
+	colDept      = "departmentCode"
+	colRole      = "role"
+	colBirthday  = "birthday"
+	colHireDate  = "hireDate"
+	colPaidLeave = "initialPaidLeave"
+)
+
+// importHeaders maps normalised header names (lower case, no spaces, dashes or underscores)
+// to columns
+var importHeaders = map[string]string{
+	"name":             colName,
+	"email":            colEmail,
+	"departmentcode":   colDept,
+	"department":       colDept,
+	"role":             colRole,
+	"birthday":         colBirthday,
+	"hiredate":         colHireDate,
+	"initialpaidleave": colPaidLeave,
+	"paidleave":        colPaidLeave,
+}
+
+// Import row actions
+const (
+	ImportCreate     = "CREATE"
+	ImportUpdate     = "UPDATE"
+	ImportUnchanged  = "UNCHANGED"
+	ImportDeactivate = "DEACTIVATE"
+	ImportError      = "ERROR"
+)
+
+// ImportOptions control a CSV import
+type ImportOptions struct {
+	DryRun            bool // validate and report only
+	DeactivateMissing bool // disable active users whose email is not in the file
+	Reactivate        bool // reactivate listed users who are not active (never terminated ones)
+	AllowRoleChange   bool // the caller may set the account role (roles.manage)
+	ActorID           uint // the importing admin; never deactivated
+	// Caller is the importing admin; users with permissions they don't hold aren't deactivated
+	Caller *authx.User
+}
+
+// ImportRowResult is the outcome of one CSV row (Row 0 for deactivations of missing users)
+type ImportRowResult struct {
+	Row      int
+	Email    string
+	Action   string
+	UserID   uint
+	Changes  []string
+	Errors   []string
+	Warnings []string
+	Before   *User // nil on create
+	After    *User
+}
+
+// ImportReport is the per-row result of an import. Nothing is written when any row fails.
+type ImportReport struct {
+	DryRun  bool
+	Applied bool
+	Rows    []ImportRowResult
+}
+
+// Count returns how many rows have the action
+func (r *ImportReport) Count(action string) int {
+	n := 0
+	for _, row := range r.Rows {
+		if row.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Import creates and updates users from an HR export, matched by email. Empty cells keep the
+// current value; initial paid leave only applies to new users. New users get an unusable
+// random password and sign in through password reset or single sign-on. Listed users who are not
+// active keep their status with a warning unless Reactivate is set; users with a termination date
+// are never reactivated. With DeactivateMissing, active users not listed are disabled, except
+// those with permissions the caller doesn't hold (reported with a warning).
+func (s *Service) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	records, err := readImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	depts, err := s.deptRepo.List(ctx)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	deptByCode := make(map[string]uint, len(depts))
+	for _, d := range depts {
+		if d.Code != nil {
+			deptByCode[strings.ToLower(*d.Code)] = d.ID
+		}
+	}
+	users, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	byEmail := make(map[string]*User, len(users))
+	for i := range users {
+		byEmail[strings.ToLower(users[i].Email)] = &users[i]
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun}
+	seen := map[string]int{}
+	now := time.Now().In(s.cfg.TimeLocation())
+	for i, rec := range records.rows {
+		res := s.planImportRow(rec, records.line[i], byEmail, deptByCode, seen, opts, now)
+		report.Rows = append(report.Rows, res)
+	}
+
+	if opts.DeactivateMissing {
+		for i := range users {
+			u := &users[i]
+			if u.Status != "active" || u.ID == opts.ActorID {
+				continue
+			}
+			if _, ok := seen[strings.ToLower(u.Email)]; ok {
+				continue
+			}
+			covered, err := s.CallerCovers(ctx, opts.Caller, u)
+			if err != nil {
+				return nil, response.Internal(err)
+			}
+			if !covered {
+				report.Rows = append(report.Rows, ImportRowResult{
+					Email:    u.Email,
+					Action:   ImportUnchanged,
+					UserID:   u.ID,
+					Warnings: []string{"not deactivated: the user has permissions you don't hold"},
+				})
+				continue
+			}
+			after := *u
+			after.Status = "disabled"
+			after.UpdatedAt = now
+			report.Rows = append(report.Rows, ImportRowResult{
+				Email:   u.Email,
+				Action:  ImportDeactivate,
+				UserID:  u.ID,
+				Changes: []string{"status"},
+				Before:  u,
+				After:   &after,
+			})
+		}
+	}
+
+	if opts.DryRun || report.Count(ImportError) > 0 {
+		return report, nil
+	}
+	if err := s.applyImport(ctx, report); err != nil {
+		return nil, response.Internal(err)
+	}
+	report.Applied = true
+	return report, nil
+}
+
+// planImportRow validates a row and works out what it would change
+func (s *Service) planImportRow(rec map[string]string, line int, byEmail map[string]*User, deptByCode map[string]uint, seen map[string]int, opts ImportOptions, now time.Time) ImportRowResult {
+	res := ImportRowResult{Row: line, Email: strings.TrimSpace(rec[colEmail])}
+	fail := func(msg string) { res.Errors = append(res.Errors, msg) }
+
+	email := strings.ToLower(res.Email)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email || len(email) > 190 {
+		fail("invalid email")
+	} else if prev, dup := seen[email]; dup {
+		fail(fmt.Sprintf("duplicate email (also on row %d)", prev))
+	} else {
+		seen[email] = line
+	}
+
+	name := strings.TrimSpace(rec[colName])
+	var deptID *uint
+	if code := strings.TrimSpace(rec[colDept]); code != "" {
+		id, ok := deptByCode[strings.ToLower(code)]
+		if !ok {
+			fail("unknown department code " + code)
+		}
+		deptID = &id
+	}
+	role := strings.ToLower(strings.TrimSpace(rec[colRole]))
+	if role != "" && role != "user" && role != "admin" {
+		fail("role must be user or admin")
+	}
+	birthday, err := parseImportDate(rec[colBirthday])
+	if err != nil {
+		fail("birthday must be YYYY-MM-DD")
+	}
+	hireDate, err := parseImportDate(rec[colHireDate])
+	if err != nil {
+		fail("hire date must be YYYY-MM-DD")
+	}
+	var paidLeave *float64
+	if v := strings.TrimSpace(rec[colPaidLeave]); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 365 || math.Mod(f*2, 1) != 0 {
+			fail("initial paid leave must be a number of days between 0 and 365 in steps of 0.5")
+		} else {
+			paidLeave = &f
+		}
+	}
+
+	existing := byEmail[email]
+	if existing == nil {
+		if len(name) < 2 || len(name) > 120 {
+			fail("name is required (2-120 characters)")
+		}
+		if role == "" {
+			role = "user"
+		}
+		if role != "user" && !opts.AllowRoleChange {
+			fail("setting the account role requires the roles.manage permission")
+		}
+		if len(res.Errors) > 0 {
+			res.Action = ImportError
+			return res
+		}
+		u := &User{
+			Name:           name,
+			Email:          email,
+			Role:           role,
+			Status:         "active",
+			EmploymentType: EmploymentFullTime,
+			DepartmentID:   deptID,
+			Birthday:       birthday,
+			HireDate:       hireDate,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if paidLeave != nil {
+			u.PaidLeave = *paidLeave
+		}
+		res.Action = ImportCreate
+		res.After = u
+		return res
+	}
+
+	res.UserID = existing.ID
+	after := *existing
+	after.Department = nil
+	if name != "" && name != existing.Name {
+		if len(name) < 2 || len(name) > 120 {
+			fail("name must be 2-120 characters")
+		}
+		after.Name = name
+		res.Changes = append(res.Changes, "name")
+	}
+	if deptID != nil && (existing.DepartmentID == nil || *existing.DepartmentID != *deptID) {
+		after.DepartmentID = deptID
+		res.Changes = append(res.Changes, "department")
+	}
+	if role != "" && role != existing.Role {
+		if !opts.AllowRoleChange {
+			fail("changing the account role requires the roles.manage permission")
+		}
+		after.Role = role
+		res.Changes = append(res.Changes, "role")
+	}
+	if birthday != nil && !sameDate(existing.Birthday, birthday) {
+		after.Birthday = birthday
+		res.Changes = append(res.Changes, "birthday")
+	}
+	if hireDate != nil && !sameDate(existing.HireDate, hireDate) {
+		after.HireDate = hireDate
+		res.Changes = append(res.Changes, "hireDate")
+	}
+	if existing.Status != "active" {
+		switch {
+		case existing.TerminationDate != nil:
+			res.Warnings = append(res.Warnings, "user is offboarded and stays "+existing.Status)
+		case opts.Reactivate:
+			after.Status = "active"
+			res.Changes = append(res.Changes, "status")
+		default:
+			res.Warnings = append(res.Warnings, "user is "+existing.Status+"; set reactivate to reactivate")
+		}
+	}
+	if paidLeave != nil && *paidLeave != existing.PaidLeave {
+		res.Warnings = append(res.Warnings, "initial paid leave ignored for an existing user")
+	}
+
+	switch {
+	case len(res.Errors) > 0:
+		res.Action = ImportError
+	case len(res.Changes) == 0:
+		res.Action = ImportUnchanged
+	default:
+		after.UpdatedAt = now
+		res.Action = ImportUpdate
+		res.Before = existing
+		res.After = &after
+	}
+	return res
+}
+
+// applyImport writes the planned creates, updates and deactivations in one transaction
+func (s *Service) applyImport(ctx context.Context, report *ImportReport) error {
+	return s.repo.Transaction(ctx, func(tx *Repo) error {
+		for i := range report.Rows {
+			row := &report.Rows[i]
+			switch row.Action {
+			case ImportCreate:
+				hash, err := randomPasswordHash()
+				if err != nil {
+					return err
+				}
+				row.After.PasswordHash = hash
+				if err := tx.Create(ctx, row.After); err != nil {
+					return fmt.Errorf("row %d: %w", row.Row, err)
+				}
+				row.UserID = row.After.ID
+			case ImportUpdate, ImportDeactivate:
+				if err := tx.Update(ctx, row.After); err != nil {
+					return fmt.Errorf("user %d: %w", row.UserID, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+type importRecords struct {
+	rows []map[string]string
+	line []int
+}
+
+// readImportCSV reads the header and the data rows, keyed by column
+func readImportCSV(r io.Reader) (*importRecords, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, response.Validation("The file is empty", nil)
+		}
+		return nil, response.Validation("Invalid CSV: "+err.Error(), nil)
+	}
+	cols := make([]string, len(header))
+	have := map[string]bool{}
+	for i, h := range header {
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff") // spreadsheet exports often start with a BOM
+		}
+		key := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(h)))
+		col, ok := importHeaders[key]
+		if !ok {
+			return nil, response.Validation("Unknown column: "+h, nil)
+		}
+		if have[col] {
+			return nil, response.Validation("Duplicate column: "+h, nil)
+		}
+		have[col] = true
+		cols[i] = col
+	}
+	if !have[colEmail] || !have[colName] {
+		return nil, response.Validation("The name and email columns are required", nil)
+	}
+
+	out := &importRecords{}
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, response.Validation("Invalid CSV: "+err.Error(), nil)
+		}
+		row := make(map[string]string, len(cols))
+		empty := true
+		for i, v := range rec {
+			if i < len(cols) {
+				row[cols[i]] = v
+				empty = empty && strings.TrimSpace(v) == ""
+			}
+		}
+		if empty {
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		out.rows = append(out.rows, row)
+		out.line = append(out.line, line)
+		if len(out.rows) > maxImportRows {
+			return nil, response.Validation(fmt.Sprintf("At most %d rows can be imported at once", maxImportRows), nil)
+		}
+	}
+	return out, nil
+}
+
+func parseImportDate(v string) (*time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// randomPasswordHash returns the hash of a random password nobody knows
+func randomPasswordHash() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return security.HashPassword(base64.RawURLEncoding.EncodeToString(b))
+}
@@ -8,21 +8,35 @@ import (
 
 // User represents a user in the system.
 type User struct {
-	ID           uint           `gorm:"primaryKey"`
-	Name         string         `gorm:"size:120;not null"`
-	Email        string         `gorm:"size:190;uniqueIndex;not null"`
-	PasswordHash string         `gorm:"size:255;not null"`
-	Role         string         `gorm:"type:enum('user','admin');not null;default:'user'"`
-	Status       string         `gorm:"type:enum('active','disabled');not null;default:'active'"`
-	DepartmentID *uint          `gorm:"index"`
-	Department   *Department    `gorm:"foreignKey:DepartmentID"`
-	Birthday     *time.Time     `gorm:"type:date"` // Ngày sinh nhật
-	PaidLeave    float64        `gorm:"type:decimal(5,1);not null;default:0.0"` // Số ngày nghỉ phép
-	CreatedAt    time.Time      `gorm:"not null"`
-	UpdatedAt    time.Time      `gorm:"not null"`
-	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	ID              uint           `gorm:"primaryKey"`
+	Name            string         `gorm:"size:120;not null"`
+	Email           string         `gorm:"size:190;uniqueIndex;not null"`
+	PasswordHash    string         `gorm:"size:255;not null"`
+	Role            string         `gorm:"type:enum('user','admin');not null;default:'user'"`
+	Status          string         `gorm:"type:enum('active','disabled');not null;default:'active'"`
+	DepartmentID    *uint          `gorm:"index"`
+	Department      *Department    `gorm:"foreignKey:DepartmentID"`
+	Birthday        *time.Time     `gorm:"type:date"`                              // Ngày sinh nhật
+	PaidLeave       float64        `gorm:"type:decimal(5,1);not null;default:0.0"` // Số ngày nghỉ phép
+	HireDate        *time.Time     `gorm:"type:date"`                              // Ngày vào làm, dùng cho thâm niên và pro-rate
+	TerminationDate *time.Time     `gorm:"type:date"`                              // Ngày nghỉ việc, dùng cho pro-rate tháng cuối
+	AccrualPolicyID *uint          `gorm:"index"`                                  // Chính sách cộng phép riêng (nil = theo phòng ban/mặc định)
+	EmployeeCode    *string        `gorm:"size:50;uniqueIndex"`                    // Mã nhân viên (theo hệ thống HR)
+	JobTitle        *string        `gorm:"size:120"`                               // Chức danh
+	EmploymentType  string         `gorm:"type:enum('full_time','part_time','contract','intern');not null;default:'full_time'"`
+	CreatedAt       time.Time      `gorm:"not null"`
+	UpdatedAt       time.Time      `gorm:"not null"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
 }
 
+// Employment types
+const (
+	EmploymentFullTime = "full_time"
+	EmploymentPartTime = "part_time"
+	EmploymentContract = "contract"
+	EmploymentIntern   = "intern"
+)
+
 // TableName specifies the table name for the User model.
 func (User) TableName() string {
 	return "users"
@@ -26,6 +26,18 @@ func (r *Repo) GetByEmail(ctx context.Context, email string) (*User, error) {
 	return &u, nil
 }
 
+// GetByEmployeeCode returns the user with the employee code, or nil
+func (r *Repo) GetByEmployeeCode(ctx context.Context, code string) (*User, error) {
+	var users []User
+	if err := r.db.WithContext(ctx).Where("employee_code = ?", code).Limit(1).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
 func (r *Repo) Create(ctx context.Context, u *User) error {
 	return r.db.WithContext(ctx).Create(u).Error
 }
@@ -39,20 +51,29 @@ func (r *Repo) Update(ctx context.Context, u *User) error {
 		Save(u).Error
 }
 
+// UpdatePassword sets a user's password hash
+func (r *Repo) UpdatePassword(ctx context.Context, id uint, hash string) error {
+	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", id).
+		Update("password_hash", hash).Error
+}
+
 func (r *Repo) Delete(ctx context.Context, id uint) error {
 	// Use Unscoped().Delete() to perform hard delete (permanently remove from database)
 	return r.db.WithContext(ctx).Unscoped().Delete(&User{}, id).Error
 }
 
-func (r *Repo) List(ctx context.Context, query string, departmentID *uint, page, limit int) ([]User, int64, error) {
+func (r *Repo) List(ctx context.Context, query string, departmentID *uint, departmentIDs []uint, page, limit int) ([]User, int64, error) {
 	q := r.db.WithContext(ctx).Model(&User{}).Preload("Department")
 	if query != "" {
 		like := "%" + query + "%"
-		q = q.Where("name LIKE ? OR email LIKE ?", like, like)
+		q = q.Where("name LIKE ? OR email LIKE ? OR employee_code LIKE ?", like, like, like)
 	}
 	if departmentID != nil {
 		q = q.Where("department_id = ?", *departmentID)
 	}
+	if departmentIDs != nil {
+		q = q.Where("department_id IN ?", departmentIDs)
+	}
 
 	var total int64
 	if err := q.Count(&total).Error; err != nil {
@@ -82,6 +103,18 @@ func (r *Repo) GetAllActiveUsers(ctx context.Context) ([]User, error) {
 	return users, nil
 }
 
+// ListActiveByDepartment returns active users of a department, ordered by name
+func (r *Repo) ListActiveByDepartment(ctx context.Context, departmentID uint) ([]User, error) {
+	var users []User
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND department_id = ?", "active", departmentID).
+		Order("name ASC").
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // IncrementPaidLeave increments paid leave for a user
 func (r *Repo) IncrementPaidLeave(ctx context.Context, userID uint, days float64) error {
 	return r.db.WithContext(ctx).Model(&User{}).
@@ -116,3 +149,19 @@ func (r *Repo) DecrementPaidLeave(ctx context.Context, userID uint, days float64
 		Where("id = ?", userID).
 		Update("paid_leave", gorm.Expr("GREATEST(paid_leave - ?, 0)", days)).Error
 }
+
+// ListAll returns every user (any status), for the CSV sync
+func (r *Repo) ListAll(ctx context.Context) ([]User, error) {
+	var users []User
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Transaction runs fn with a repo bound to one database transaction
+func (r *Repo) Transaction(ctx context.Context, fn func(tx *Repo) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Repo{db: tx})
+	})
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"time-attendance-be/internal/authx"
 	"time-attendance-be/internal/modules/audit"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,11 +19,12 @@ func (m *Module) RegisterMe(v1 fiber.Router, auth fiber.Handler) {
 
 func (m *Module) RegisterAdmin(admin fiber.Router) {
 	g := admin.Group("/users")
-	g.Get("/", m.h.AdminList)
-	g.Post("/", m.h.AdminCreate)
-	g.Get("/:id", m.h.AdminGet)
-	g.Patch("/:id", m.h.AdminUpdate)
-	g.Delete("/:id", m.h.AdminDelete)
+	g.Get("/", authx.Require(authx.PermUsersRead), m.h.AdminList)
+	g.Post("/", authx.Require(authx.PermUsersWrite), m.h.AdminCreate)
+	g.Post("/import", authx.Require(authx.PermUsersWrite), m.h.AdminImport)
+	g.Get("/:id", authx.Require(authx.PermUsersRead), m.h.AdminGet)
+	g.Patch("/:id", authx.Require(authx.PermUsersWrite), m.h.AdminUpdate)
+	g.Delete("/:id", authx.Require(authx.PermUsersWrite), m.h.AdminDelete)
 }
 
 
@@ -2,8 +2,10 @@ package user
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"time-attendance-be/internal/authx"
 	"time-attendance-be/internal/config"
 	"time-attendance-be/internal/modules/department"
 	"time-attendance-be/internal/pkg/response"
@@ -17,6 +19,7 @@ type Service struct {
 	cfg      *config.Config
 	repo     *Repo
 	deptRepo *department.Repo
+	perms    PermissionStore
 	v        *validator.Validator
 }
 
@@ -24,12 +27,45 @@ func NewService(cfg *config.Config, repo *Repo, deptRepo *department.Repo) *Serv
 	return &Service{cfg: cfg, repo: repo, deptRepo: deptRepo, v: validator.New()}
 }
 
+// PermissionStore loads a user's permissions (implemented by rbac.Service)
+type PermissionStore interface {
+	Grants(ctx context.Context, userID uint, role string) (*authx.Grants, error)
+}
+
+// SetPermissionStore limits changes that take over or lock out an account (password, email,
+// deactivation) to users whose permissions the caller holds too
+func (s *Service) SetPermissionStore(p PermissionStore) {
+	s.perms = p
+}
+
+// CallerCovers reports whether the caller holds every permission of target (for at least
+// the same departments). Company-wide roles.manage holders cover everyone.
+func (s *Service) CallerCovers(ctx context.Context, caller *authx.User, target *User) (bool, error) {
+	mine, err := caller.Grants(ctx)
+	if err != nil {
+		return false, err
+	}
+	if mine.Scope(authx.PermRolesManage).All {
+		return true, nil
+	}
+	if s.perms == nil {
+		return target.Role != "admin", nil
+	}
+	theirs, err := s.perms.Grants(ctx, target.ID, target.Role)
+	if err != nil {
+		return false, err
+	}
+	return mine.Covers(theirs), nil
+}
+
 func (s *Service) GetMe(ctx context.Context, userID uint) (*User, error) {
 	return s.repo.GetByID(ctx, userID)
 }
 
-func (s *Service) List(ctx context.Context, query string, departmentID *uint, page, limit int) ([]User, int64, error) {
-	return s.repo.List(ctx, query, departmentID, page, limit)
+// List returns a page of users. departmentIDs, when not nil, restricts the result to those
+// departments (department-scoped permissions).
+func (s *Service) List(ctx context.Context, query string, departmentID *uint, departmentIDs []uint, page, limit int) ([]User, int64, error) {
+	return s.repo.List(ctx, query, departmentID, departmentIDs, page, limit)
 }
 
 func (s *Service) Create(ctx context.Context, req UserCreateInput) (*User, error) {
@@ -47,6 +83,11 @@ func (s *Service) Create(ctx context.Context, req UserCreateInput) (*User, error
 		}
 	}
 
+	employeeCode, err := s.checkEmployeeCode(ctx, req.EmployeeCode, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	hash, err := security.HashPassword(req.Password)
 	if err != nil {
 		return nil, response.Internal(err)
@@ -60,23 +101,37 @@ func (s *Service) Create(ctx context.Context, req UserCreateInput) (*User, error
 	if req.Status != "" {
 		status = req.Status
 	}
+	employmentType := EmploymentFullTime
+	if req.EmploymentType != "" {
+		employmentType = req.EmploymentType
+	}
 
 	var birthday *time.Time
 	if req.Birthday != nil && req.Birthday.Time != nil {
 		birthday = req.Birthday.Time
 	}
 
+	var hireDate *time.Time
+	if req.HireDate != nil && req.HireDate.Time != nil {
+		hireDate = req.HireDate.Time
+	}
+
 	u := &User{
-		Name:         req.Name,
-		Email:        req.Email,
-		PasswordHash: hash,
-		Role:         role,
-		Status:       status,
-		DepartmentID: req.DepartmentID,
-		Birthday:     birthday,
-		PaidLeave:    0.0, // Mặc định 0 ngày phép khi tạo mới
-		CreatedAt:    time.Now().In(s.cfg.TimeLocation()),
-		UpdatedAt:    time.Now().In(s.cfg.TimeLocation()),
+		Name:            req.Name,
+		Email:           req.Email,
+		PasswordHash:    hash,
+		Role:            role,
+		Status:          status,
+		DepartmentID:    req.DepartmentID,
+		Birthday:        birthday,
+		PaidLeave:       0.0, // Mặc định 0 ngày phép khi tạo mới
+		HireDate:        hireDate,
+		AccrualPolicyID: req.AccrualPolicyID,
+		EmployeeCode:    employeeCode,
+		JobTitle:        emptyToNil(req.JobTitle),
+		EmploymentType:  employmentType,
+		CreatedAt:       time.Now().In(s.cfg.TimeLocation()),
+		UpdatedAt:       time.Now().In(s.cfg.TimeLocation()),
 	}
 
 	if err := s.repo.Create(ctx, u); err != nil {
@@ -117,6 +172,9 @@ func (s *Service) Update(ctx context.Context, id uint, req UserUpdateInput) (*Us
 		u.Role = *req.Role
 	}
 	if req.Status != nil {
+		if *req.Status == "active" && u.TerminationDate != nil {
+			return nil, response.Validation("An offboarded user cannot be reactivated", nil)
+		}
 		u.Status = *req.Status
 	}
 	if req.DepartmentID != nil {
@@ -147,6 +205,31 @@ func (s *Service) Update(ctx context.Context, id uint, req UserUpdateInput) (*Us
 			u.Birthday = nil
 		}
 	}
+	if req.HireDate != nil {
+		u.HireDate = req.HireDate.Time
+	}
+	if req.AccrualPolicyID != nil {
+		// 0 removes the per-user policy so the department/default policy applies again
+		if *req.AccrualPolicyID == 0 {
+			u.AccrualPolicyID = nil
+		} else {
+			u.AccrualPolicyID = req.AccrualPolicyID
+		}
+	}
+
+	if req.EmployeeCode != nil {
+		code, err := s.checkEmployeeCode(ctx, req.EmployeeCode, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		u.EmployeeCode = code
+	}
+	if req.JobTitle != nil {
+		u.JobTitle = emptyToNil(req.JobTitle)
+	}
+	if req.EmploymentType != nil && *req.EmploymentType != "" {
+		u.EmploymentType = *req.EmploymentType
+	}
 
 	u.UpdatedAt = time.Now().In(s.cfg.TimeLocation())
 	if err := s.repo.Update(ctx, u); err != nil {
@@ -163,3 +246,31 @@ func (s *Service) Update(ctx context.Context, id uint, req UserUpdateInput) (*Us
 func (s *Service) Delete(ctx context.Context, id uint) error {
 	return s.repo.Delete(ctx, id)
 }
+
+// checkEmployeeCode trims the code and makes sure no other user has it. An empty code
+// means none.
+func (s *Service) checkEmployeeCode(ctx context.Context, code *string, userID uint) (*string, error) {
+	code = emptyToNil(code)
+	if code == nil {
+		return nil, nil
+	}
+	other, err := s.repo.GetByEmployeeCode(ctx, *code)
+	if err != nil {
+		return nil, response.Internal(err)
+	}
+	if other != nil && other.ID != userID {
+		return nil, response.Conflict("Employee code already exists")
+	}
+	return code, nil
+}
+
+func emptyToNil(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*v)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
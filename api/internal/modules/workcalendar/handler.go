@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/config"
 	"time-attendance-be/internal/modules/audit"
 	"time-attendance-be/internal/modules/leave"
+	"time-attendance-be/internal/modules/user"
 	"time-attendance-be/internal/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,16 +17,22 @@ import (
 )
 
 type Handler struct {
+	cfg         *config.Config
 	repo        *Repo
+	svc         *Service
 	leaveService *leave.Service
+	userRepo    *user.Repo
 	logger      *zap.Logger
 	auditSvc    *audit.Service
 }
 
-func NewHandler(repo *Repo, leaveService *leave.Service, logger *zap.Logger, auditSvc *audit.Service) *Handler {
+func NewHandler(cfg *config.Config, repo *Repo, svc *Service, leaveService *leave.Service, userRepo *user.Repo, logger *zap.Logger, auditSvc *audit.Service) *Handler {
 	return &Handler{
+		cfg:         cfg,
 		repo:        repo,
+		svc:         svc,
 		leaveService: leaveService,
+		userRepo:    userRepo,
 		logger:      logger,
 		auditSvc:    auditSvc,
 	}
@@ -142,18 +150,10 @@ func (h *Handler) UpsertDay(c *fiber.Ctx) error {
 		)
 }
 
-	// Recalculate leave_monthly_summary for all users in this month
+	// Queue leave_monthly_summary recomputation for all users in this month
 	// because work calendar change affects expected units calculation
-	if h.leaveService != nil {
-		year := d.Year()
-		month := int(d.Month())
-		if err := h.recalculateSummariesForMonth(c.Context(), year, month); err != nil {
-			h.logger.Error("failed to recalculate summaries after work calendar update",
-				zap.Int("year", year),
-				zap.Int("month", month),
-				zap.Error(err))
-			// Don't fail the request, just log the error
-		}
+	if batchID := h.queueSummaryRecompute(c.Context(), adminUser.ID, d); batchID != nil {
+		after["recomputeBatchId"] = *batchID
 	}
 
 	return response.OK(c, after)
@@ -182,15 +182,22 @@ func (h *Handler) List(c *fiber.Ctx) error {
 	if err != nil {
 		return response.Internal(err)
 	}
+
+	rules, err := h.repo.ListRules(c.Context(), from, to)
+	if err != nil {
+		return response.Internal(err)
+	}
 	
 	// Convert to response format
 	result := make([]map[string]interface{}, len(days))
 	for i, day := range days {
+		date := day.WorkDate.Format("2006-01-02")
 		result[i] = map[string]interface{}{
-			"date":         day.WorkDate.Format("2006-01-02"),
+			"date":         date,
 			"isWorkingDay": day.IsWorkingDay,
 			"workUnit":     day.WorkUnit,
 			"note":         day.Note,
+			"rules":        rulesOnDay(rules, date, nil, true),
 		}
 	}
 	
@@ -288,52 +295,60 @@ func (h *Handler) BulkUpdate(c *fiber.Ctx) error {
 		)
 	}
 	
-	// Recalculate leave_monthly_summary for all affected months
-	if h.leaveService != nil {
-		for _, monthInfo := range updatedMonths {
-			if err := h.recalculateSummariesForMonth(c.Context(), monthInfo.Year, monthInfo.Month); err != nil {
-				h.logger.Error("failed to recalculate summaries after bulk work calendar update",
-					zap.Int("year", monthInfo.Year),
-					zap.Int("month", monthInfo.Month),
-					zap.Error(err))
-				// Don't fail the request, just log the error
-			}
-		}
+	// Queue leave_monthly_summary recomputation for all affected months (one batch)
+	months := make([]time.Time, 0, len(updatedMonths))
+	for _, monthInfo := range updatedMonths {
+		months = append(months, time.Date(monthInfo.Year, time.Month(monthInfo.Month), 1, 0, 0, 0, 0, time.UTC))
 	}
-	
-	return response.OK(c, map[string]interface{}{
+	res := map[string]interface{}{
 		"updated": len(req.Days),
-	})
+	}
+	if batchID := h.queueSummaryRecompute(c.Context(), adminUser.ID, months...); batchID != nil {
+		res["recomputeBatchId"] = *batchID
+	}
+
+	return response.OK(c, res)
 }
 
-// recalculateSummariesForMonth recalculates leave_monthly_summary for all users in a specific month
-func (h *Handler) recalculateSummariesForMonth(ctx context.Context, year, month int) error {
-	if h.leaveService == nil {
+// queueSummaryRecompute queues leave_monthly_summary recomputation for all users with a summary
+// in the months of the given dates. The calendar change is already saved, so failures are only
+// logged; the returned batch ID (nil on failure) lets the caller follow progress.
+func (h *Handler) queueSummaryRecompute(ctx context.Context, adminID uint, dates ...time.Time) *uint {
+	if h.leaveService == nil || len(dates) == 0 {
 		return nil
 	}
-	
-	// Get all user IDs that have summaries for this month
-	userIDs, err := h.leaveService.GetUserIDsWithSummaryInMonth(ctx, year, month)
-	if err != nil {
-		return err
-	}
-	
-	// Recalculate summary for each user
-	for _, userID := range userIDs {
-		if _, err := h.leaveService.ComputeMonthlySummary(ctx, userID, year, month); err != nil {
-			h.logger.Error("failed to recalculate summary for user",
-				zap.Uint("userID", userID),
+
+	var keys []leave.RecomputeKey
+	seen := make(map[string]bool)
+	for _, d := range dates {
+		year, month := d.Year(), int(d.Month())
+		monthKey := d.Format("2006-01")
+		if seen[monthKey] {
+			continue
+		}
+		seen[monthKey] = true
+
+		userIDs, err := h.leaveService.GetUserIDsWithSummaryInMonth(ctx, year, month)
+		if err != nil {
+			h.logger.Error("failed to list users for summary recompute",
 				zap.Int("year", year),
 				zap.Int("month", month),
 				zap.Error(err))
-			// Continue with other users
+			return nil
+		}
+		for _, userID := range userIDs {
+			keys = append(keys, leave.RecomputeKey{UserID: userID, Year: year, Month: month})
 		}
 	}
-	
-	h.logger.Info("recalculated summaries for month",
-		zap.Int("year", year),
-		zap.Int("month", month),
-		zap.Int("userCount", len(userIDs)))
-	
-	return nil
-}
\ No newline at end of file
+
+	batch, err := h.leaveService.EnqueueRecompute(ctx, keys, leave.RecomputeReasonCalendar, &adminID)
+	if err != nil {
+		h.logger.Error("failed to queue summary recompute after work calendar update", zap.Error(err))
+		return nil
+	}
+
+	h.logger.Info("queued summary recompute for work calendar update",
+		zap.Uint("batchID", batch.ID),
+		zap.Int("taskCount", len(keys)))
+	return &batch.ID
+}
@@ -100,6 +100,9 @@ func (r *Repo) EnsureYear(ctx context.Context, year int) error {
 		batch = append(batch, day)
 		cur = cur.AddDate(0, 0, 1)
 	}
-	return r.db.WithContext(ctx).Create(&batch).Error
+	// DoNothing: another replica may be generating the same year concurrently
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&batch).Error
 }
 
@@ -1,8 +1,11 @@
 package workcalendar
 
 import (
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/config"
 	"time-attendance-be/internal/modules/audit"
 	"time-attendance-be/internal/modules/leave"
+	"time-attendance-be/internal/modules/user"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
@@ -12,17 +15,27 @@ type Module struct {
 	h *Handler
 }
 
-func NewModule(repo *Repo, leaveService *leave.Service, logger *zap.Logger, auditSvc *audit.Service) *Module {
+func NewModule(cfg *config.Config, repo *Repo, svc *Service, leaveService *leave.Service, userRepo *user.Repo, logger *zap.Logger, auditSvc *audit.Service) *Module {
 	return &Module{
-		h: NewHandler(repo, leaveService, logger, auditSvc),
+		h: NewHandler(cfg, repo, svc, leaveService, userRepo, logger, auditSvc),
 	}
 }
 
+func (m *Module) RegisterMe(v1 fiber.Router, auth fiber.Handler) {
+	g := v1.Group("/calendar", auth)
+	g.Get("/team", m.h.TeamCalendar)
+	g.Get("/leave-check", m.h.LeaveCheck)
+}
+
 func (m *Module) RegisterAdmin(admin fiber.Router) {
-	g := admin.Group("/work-calendar")
+	g := admin.Group("/work-calendar", authx.Require(authx.PermCalendarManage))
 	g.Get("", m.h.List)
 	g.Post("/generate", m.h.Generate)
 	g.Put("/day", m.h.UpsertDay)
 	g.Post("/bulk", m.h.BulkUpdate)
+	g.Get("/rules", m.h.ListRules)
+	g.Post("/rules", m.h.CreateRule)
+	g.Put("/rules/:id", m.h.UpdateRule)
+	g.Delete("/rules/:id", m.h.DeleteRule)
 }
 
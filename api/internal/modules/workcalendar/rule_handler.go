@@ -0,0 +1,330 @@
+package workcalendar
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type leaveRuleRequest struct {
+	Kind         string  `json:"kind"` // BLACKOUT | MIN_STAFFING
+	Name         string  `json:"name"`
+	DepartmentID *uint   `json:"departmentId"` // null = company-wide
+	StartDate    string  `json:"startDate"`    // YYYY-MM-DD
+	EndDate      string  `json:"endDate"`      // YYYY-MM-DD
+	MinPresent   int     `json:"minPresent"`
+	Action       string  `json:"action"` // REJECT | ESCALATE (default REJECT)
+	Note         *string `json:"note"`
+}
+
+func (req *leaveRuleRequest) applyTo(rule *LeaveRule) error {
+	if req.Kind != RuleKindBlackout && req.Kind != RuleKindMinStaffing {
+		return response.Validation("kind must be BLACKOUT or MIN_STAFFING", nil)
+	}
+	if req.Name == "" {
+		return response.Validation("name is required", nil)
+	}
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return response.Validation("Invalid startDate format (YYYY-MM-DD)", nil)
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return response.Validation("Invalid endDate format (YYYY-MM-DD)", nil)
+	}
+	if end.Before(start) {
+		return response.Validation("endDate must not be before startDate", nil)
+	}
+	if req.Kind == RuleKindMinStaffing && req.MinPresent < 1 {
+		return response.Validation("minPresent must be at least 1", nil)
+	}
+	action := req.Action
+	if action == "" {
+		action = RuleActionReject
+	}
+	if action != RuleActionReject && action != RuleActionEscalate {
+		return response.Validation("action must be REJECT or ESCALATE", nil)
+	}
+
+	rule.Kind = req.Kind
+	rule.Name = req.Name
+	rule.DepartmentID = req.DepartmentID
+	rule.StartDate = start
+	rule.EndDate = end
+	rule.MinPresent = 0
+	if req.Kind == RuleKindMinStaffing {
+		rule.MinPresent = req.MinPresent
+	}
+	rule.Action = action
+	rule.Note = req.Note
+	return nil
+}
+
+type leaveRuleResponse struct {
+	ID           uint    `json:"id"`
+	Kind         string  `json:"kind"`
+	Name         string  `json:"name"`
+	DepartmentID *uint   `json:"departmentId"`
+	StartDate    string  `json:"startDate"`
+	EndDate      string  `json:"endDate"`
+	MinPresent   int     `json:"minPresent,omitempty"`
+	Action       string  `json:"action"`
+	Note         *string `json:"note,omitempty"`
+}
+
+func toLeaveRuleResponse(r *LeaveRule) leaveRuleResponse {
+	return leaveRuleResponse{
+		ID:           r.ID,
+		Kind:         r.Kind,
+		Name:         r.Name,
+		DepartmentID: r.DepartmentID,
+		StartDate:    r.StartDate.Format("2006-01-02"),
+		EndDate:      r.EndDate.Format("2006-01-02"),
+		MinPresent:   r.MinPresent,
+		Action:       r.Action,
+		Note:         r.Note,
+	}
+}
+
+// rulesOnDay returns the rules covering a date. With departmentID == nil and allDepartments set,
+// rules of every department are returned (admin calendar view).
+func rulesOnDay(rules []LeaveRule, date string, departmentID *uint, allDepartments bool) []leaveRuleResponse {
+	result := []leaveRuleResponse{}
+	for i := range rules {
+		if !rules[i].Covers(date) {
+			continue
+		}
+		if !allDepartments && !rules[i].AppliesTo(departmentID) {
+			continue
+		}
+		result = append(result, toLeaveRuleResponse(&rules[i]))
+	}
+	return result
+}
+
+// GET /api/v1/admin/work-calendar/rules?from=&to=
+func (h *Handler) ListRules(c *fiber.Ctx) error {
+	var from, to time.Time
+	if v := c.Query("from"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return response.Validation("Invalid from date format (YYYY-MM-DD)", nil)
+		}
+		from = d
+	}
+	if v := c.Query("to"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return response.Validation("Invalid to date format (YYYY-MM-DD)", nil)
+		}
+		to = d
+	}
+
+	rules, err := h.repo.ListRules(c.Context(), from, to)
+	if err != nil {
+		return response.Internal(err)
+	}
+	result := make([]leaveRuleResponse, len(rules))
+	for i := range rules {
+		result[i] = toLeaveRuleResponse(&rules[i])
+	}
+	return response.OK(c, result)
+}
+
+// POST /api/v1/admin/work-calendar/rules
+func (h *Handler) CreateRule(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	var req leaveRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	now := time.Now()
+	rule := &LeaveRule{CreatedBy: adminUser.ID, CreatedAt: now, UpdatedAt: now}
+	if err := req.applyTo(rule); err != nil {
+		return err
+	}
+	if err := h.repo.SaveRule(c.Context(), rule); err != nil {
+		return response.Internal(err)
+	}
+
+	res := toLeaveRuleResponse(rule)
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"CREATE",
+			"leave_rule",
+			strconv.FormatUint(uint64(rule.ID), 10),
+			nil,
+			res,
+			"",
+		)
+	}
+
+	return response.Created(c, res)
+}
+
+// PUT /api/v1/admin/work-calendar/rules/:id
+func (h *Handler) UpdateRule(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	var req leaveRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Validation("Invalid body", nil)
+	}
+
+	rule, err := h.repo.GetRule(c.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound("Rule not found")
+		}
+		return response.Internal(err)
+	}
+	before := toLeaveRuleResponse(rule)
+
+	if err := req.applyTo(rule); err != nil {
+		return err
+	}
+	rule.UpdatedAt = time.Now()
+	if err := h.repo.SaveRule(c.Context(), rule); err != nil {
+		return response.Internal(err)
+	}
+
+	res := toLeaveRuleResponse(rule)
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"UPDATE",
+			"leave_rule",
+			strconv.FormatUint(id, 10),
+			before,
+			res,
+			"",
+		)
+	}
+
+	return response.OK(c, res)
+}
+
+// DELETE /api/v1/admin/work-calendar/rules/:id
+func (h *Handler) DeleteRule(c *fiber.Ctx) error {
+	adminUser := authx.GetUser(c)
+	if adminUser == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Validation("invalid id", nil)
+	}
+
+	rule, err := h.repo.GetRule(c.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound("Rule not found")
+		}
+		return response.Internal(err)
+	}
+	if err := h.repo.DeleteRule(c.Context(), uint(id)); err != nil {
+		return response.Internal(err)
+	}
+
+	if h.auditSvc != nil {
+		_ = h.auditSvc.LogAdminAction(
+			c.Context(),
+			adminUser.ID,
+			"DELETE",
+			"leave_rule",
+			strconv.FormatUint(id, 10),
+			toLeaveRuleResponse(rule),
+			nil,
+			"",
+		)
+	}
+
+	return response.OK(c, true)
+}
+
+type ruleViolation struct {
+	RuleID  uint   `json:"ruleId"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Action  string `json:"action"`
+	Date    string `json:"date"`
+	Message string `json:"message"`
+}
+
+type leaveCheckResponse struct {
+	Allowed            bool            `json:"allowed"`            // false if any REJECT rule is breached
+	RequiresEscalation bool            `json:"requiresEscalation"` // true if any ESCALATE rule is breached
+	Violations         []ruleViolation `json:"violations"`
+}
+
+func toLeaveCheckResponse(check *LeaveCheck) leaveCheckResponse {
+	res := leaveCheckResponse{
+		Allowed:            check.Allowed,
+		RequiresEscalation: check.RequiresEscalation,
+		Violations:         make([]ruleViolation, len(check.Violations)),
+	}
+	for i, v := range check.Violations {
+		res.Violations[i] = ruleViolation{
+			RuleID:  v.Rule.ID,
+			Name:    v.Rule.Name,
+			Kind:    v.Rule.Kind,
+			Action:  v.Rule.Action,
+			Date:    v.Date,
+			Message: v.Message,
+		}
+	}
+	return res
+}
+
+// GET /api/v1/calendar/leave-check?from=YYYY-MM-DD&to=YYYY-MM-DD
+// Tells the caller whether time off in the range breaches a blackout or staffing rule
+func (h *Handler) LeaveCheck(c *fiber.Ctx) error {
+	user := authx.GetUser(c)
+	if user == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		return response.Validation("Invalid from date format (YYYY-MM-DD)", nil)
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		return response.Validation("Invalid to date format (YYYY-MM-DD)", nil)
+	}
+	if to.Before(from) {
+		return response.Validation("to must not be before from", nil)
+	}
+	if to.Sub(from) > maxTeamCalendarDays*24*time.Hour {
+		return response.Validation("Range must not exceed 62 days", nil)
+	}
+
+	check, err := h.svc.CheckLeave(c.Context(), user.ID, from, to)
+	if err != nil {
+		return response.Internal(err)
+	}
+	return response.OK(c, toLeaveCheckResponse(check))
+}
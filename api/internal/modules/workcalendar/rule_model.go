@@ -0,0 +1,47 @@
+package workcalendar
+
+import "time"
+
+// LeaveRule restricts leave/WFH in a date window, per department or company-wide.
+// BLACKOUT blocks time off entirely; MIN_STAFFING requires at least MinPresent people of the
+// department (or company) to be present each working day. Action decides whether a breach is
+// rejected outright or only flagged for escalated approval.
+type LeaveRule struct {
+	ID           uint      `gorm:"primaryKey"`
+	Kind         string    `gorm:"type:enum('BLACKOUT','MIN_STAFFING');not null"`
+	Name         string    `gorm:"size:120;not null"`
+	DepartmentID *uint     `gorm:"index"` // nil = company-wide
+	StartDate    time.Time `gorm:"type:date;not null;index"`
+	EndDate      time.Time `gorm:"type:date;not null;index"`
+	MinPresent   int       `gorm:"not null;default:0"` // MIN_STAFFING only
+	Action       string    `gorm:"type:enum('REJECT','ESCALATE');not null;default:'REJECT'"`
+	Note         *string   `gorm:"type:varchar(255)"`
+	CreatedBy    uint      `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (LeaveRule) TableName() string {
+	return "leave_rules"
+}
+
+const (
+	RuleKindBlackout    = "BLACKOUT"
+	RuleKindMinStaffing = "MIN_STAFFING"
+
+	RuleActionReject   = "REJECT"
+	RuleActionEscalate = "ESCALATE"
+)
+
+// Covers reports whether the rule window contains the date (YYYY-MM-DD)
+func (r *LeaveRule) Covers(date string) bool {
+	return date >= r.StartDate.Format("2006-01-02") && date <= r.EndDate.Format("2006-01-02")
+}
+
+// AppliesTo reports whether the rule applies to a department (nil rule department = everyone)
+func (r *LeaveRule) AppliesTo(departmentID *uint) bool {
+	if r.DepartmentID == nil {
+		return true
+	}
+	return departmentID != nil && *departmentID == *r.DepartmentID
+}
@@ -0,0 +1,39 @@
+package workcalendar
+
+import (
+	"context"
+	"time"
+)
+
+// ListRules returns rules overlapping [from, to]; zero dates mean unbounded
+func (r *Repo) ListRules(ctx context.Context, from, to time.Time) ([]LeaveRule, error) {
+	query := r.db.WithContext(ctx).Model(&LeaveRule{})
+	if !to.IsZero() {
+		query = query.Where("start_date <= ?", to.Format("2006-01-02"))
+	}
+	if !from.IsZero() {
+		query = query.Where("end_date >= ?", from.Format("2006-01-02"))
+	}
+	var rules []LeaveRule
+	err := query.Order("start_date ASC, id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// GetRule returns a rule by ID
+func (r *Repo) GetRule(ctx context.Context, id uint) (*LeaveRule, error) {
+	var rule LeaveRule
+	if err := r.db.WithContext(ctx).First(&rule, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// SaveRule creates or updates a rule
+func (r *Repo) SaveRule(ctx context.Context, rule *LeaveRule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+// DeleteRule deletes a rule
+func (r *Repo) DeleteRule(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&LeaveRule{}, id).Error
+}
@@ -0,0 +1,197 @@
+package workcalendar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/modules/leave"
+	"time-attendance-be/internal/modules/user"
+)
+
+// LeaveCheck is the outcome of checking time off against the blackout and staffing rules
+type LeaveCheck struct {
+	Allowed            bool // false if any REJECT rule is breached
+	RequiresEscalation bool // true if any ESCALATE rule is breached
+	Violations         []RuleViolation
+}
+
+// RuleViolation is one rule breached on one day
+type RuleViolation struct {
+	Rule    *LeaveRule
+	Date    string
+	Message string
+}
+
+// Summary joins the violation messages for an error response
+func (c *LeaveCheck) Summary() string {
+	messages := make([]string, len(c.Violations))
+	for i, v := range c.Violations {
+		messages[i] = v.Date + ": " + v.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// CheckLeave evaluates blackout and staffing rules for a user taking the working days of
+// [from, to] off (leave or WFH).
+func (s *Service) CheckLeave(ctx context.Context, userID uint, from, to time.Time) (*LeaveCheck, error) {
+	days, err := s.repo.ListRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	dates := make([]time.Time, 0, len(days))
+	for _, day := range days {
+		if day.IsWorkingDay && day.WorkUnit > 0 {
+			dates = append(dates, day.WorkDate)
+		}
+	}
+	return s.checkDates(ctx, userID, dates)
+}
+
+// CheckAbsences evaluates the rules for the days of a month a user was absent and covered
+// by leave (birthday, comp-off, paid or unpaid), as allocated by the month's breakdown.
+func (s *Service) CheckAbsences(ctx context.Context, userID uint, year, month int) (*LeaveCheck, error) {
+	b, err := s.leaveService.ProjectMonthlyBreakdown(ctx, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	var dates []time.Time
+	for _, d := range b.Days {
+		if leaveUnits(&d) > 0 {
+			dates = append(dates, d.Date)
+		}
+	}
+	return s.checkDates(ctx, userID, dates)
+}
+
+// checkDates evaluates the rules on each date (sorted ascending). Staffing counts the people in
+// the rule's scope who are not absent that day, without the requester. Only days before today
+// can have absences; later days count everyone else as present.
+func (s *Service) checkDates(ctx context.Context, userID uint, dates []time.Time) (*LeaveCheck, error) {
+	res := &LeaveCheck{Allowed: true, Violations: []RuleViolation{}}
+	if len(dates) == 0 {
+		return res, nil
+	}
+
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.repo.ListRules(ctx, dates[0], dates[len(dates)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	staffing := newStaffingCounter(s)
+	for _, day := range dates {
+		date := day.Format("2006-01-02")
+		for i := range rules {
+			rule := &rules[i]
+			if !rule.Covers(date) || !rule.AppliesTo(u.DepartmentID) {
+				continue
+			}
+
+			var message string
+			switch rule.Kind {
+			case RuleKindBlackout:
+				message = "Leave is blocked during " + rule.Name
+			case RuleKindMinStaffing:
+				present, err := staffing.present(ctx, rule.DepartmentID, day, userID)
+				if err != nil {
+					return nil, err
+				}
+				if present >= rule.MinPresent {
+					continue
+				}
+				message = fmt.Sprintf("At least %d people must be present (%d would remain)", rule.MinPresent, present)
+			}
+
+			res.Violations = append(res.Violations, RuleViolation{Rule: rule, Date: date, Message: message})
+			if rule.Action == RuleActionEscalate {
+				res.RequiresEscalation = true
+			} else {
+				res.Allowed = false
+			}
+		}
+	}
+	return res, nil
+}
+
+// staffingCounter caches the people in a rule scope and their absences per month
+type staffingCounter struct {
+	s       *Service
+	today   string
+	members map[uint][]user.User     // department ID (0 = company) -> active users
+	absent  map[string]map[uint]bool // "dept/YYYY-MM-DD" -> absent user IDs
+	loaded  map[string]bool          // "dept/YYYY-MM" projected
+}
+
+func newStaffingCounter(s *Service) *staffingCounter {
+	return &staffingCounter{
+		s:       s,
+		today:   time.Now().In(s.cfg.TimeLocation()).Format("2006-01-02"),
+		members: make(map[uint][]user.User),
+		absent:  make(map[string]map[uint]bool),
+		loaded:  make(map[string]bool),
+	}
+}
+
+// present counts the people of a department (nil = company) present on a day, without userID
+func (c *staffingCounter) present(ctx context.Context, departmentID *uint, day time.Time, userID uint) (int, error) {
+	var dept uint
+	if departmentID != nil {
+		dept = *departmentID
+	}
+	members, ok := c.members[dept]
+	if !ok {
+		var err error
+		if departmentID != nil {
+			members, err = c.s.userRepo.ListActiveByDepartment(ctx, dept)
+		} else {
+			members, err = c.s.userRepo.GetAllActiveUsers(ctx)
+		}
+		if err != nil {
+			return 0, err
+		}
+		c.members[dept] = members
+	}
+
+	date := day.Format("2006-01-02")
+	if date < c.today {
+		monthKey := fmt.Sprintf("%d/%s", dept, day.Format("2006-01"))
+		if !c.loaded[monthKey] {
+			breakdowns, err := c.s.leaveService.ProjectMonthlyBreakdowns(ctx, members, day.Year(), int(day.Month()))
+			if err != nil {
+				return 0, err
+			}
+			for id, b := range breakdowns {
+				for _, d := range b.Days {
+					if d.MissingUnits <= 0 {
+						continue
+					}
+					key := fmt.Sprintf("%d/%s", dept, d.Date.Format("2006-01-02"))
+					if c.absent[key] == nil {
+						c.absent[key] = make(map[uint]bool)
+					}
+					c.absent[key][id] = true
+				}
+			}
+			c.loaded[monthKey] = true
+		}
+	}
+
+	absent := c.absent[fmt.Sprintf("%d/%s", dept, date)]
+	present := 0
+	for i := range members {
+		if members[i].ID != userID && !absent[members[i].ID] {
+			present++
+		}
+	}
+	return present, nil
+}
+
+// leaveUnits is the part of a day's missing units covered by leave
+func leaveUnits(d *leave.DayAllocation) float64 {
+	return d.BirthdayUnits + d.CompOffUnits + d.PaidUnits + d.UnpaidUnits
+}
@@ -0,0 +1,23 @@
+package workcalendar
+
+import (
+	"time-attendance-be/internal/config"
+	"time-attendance-be/internal/modules/leave"
+	"time-attendance-be/internal/modules/user"
+)
+
+type Service struct {
+	cfg          *config.Config
+	repo         *Repo
+	leaveService *leave.Service
+	userRepo     *user.Repo
+}
+
+func NewService(cfg *config.Config, repo *Repo, leaveService *leave.Service, userRepo *user.Repo) *Service {
+	return &Service{
+		cfg:          cfg,
+		repo:         repo,
+		leaveService: leaveService,
+		userRepo:     userRepo,
+	}
+}
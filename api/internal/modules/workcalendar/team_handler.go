@@ -0,0 +1,189 @@
+package workcalendar
+
+import (
+	"strconv"
+	"time"
+
+	"time-attendance-be/internal/authx"
+	"time-attendance-be/internal/modules/leave"
+	"time-attendance-be/internal/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Team calendar entry kinds
+const (
+	TeamEntryLeave   = "LEAVE"   // absence covered by a leave balance (or unpaid leave)
+	TeamEntryMissing = "MISSING" // working day without (closed) attendance and no leave recorded
+)
+
+// maxTeamCalendarDays caps the range of one team calendar request
+const maxTeamCalendarDays = 62
+
+type teamEntryResponse struct {
+	Date      string  `json:"date"`
+	Kind      string  `json:"kind"`
+	Units     float64 `json:"units"`
+	LeaveType string  `json:"leaveType,omitempty"` // only for the member themselves and leave.read holders
+}
+
+type teamMemberResponse struct {
+	UserID  uint                `json:"userId"`
+	Name    string              `json:"name"`
+	Entries []teamEntryResponse `json:"entries"`
+}
+
+type teamDayResponse struct {
+	Date         string              `json:"date"`
+	IsWorkingDay bool                `json:"isWorkingDay"`
+	WorkUnit     float64             `json:"workUnit"`
+	Note         *string             `json:"note,omitempty"`
+	Rules        []leaveRuleResponse `json:"rules"` // blackout/staffing rules for this department
+}
+
+// GET /api/v1/calendar/team?from=YYYY-MM-DD&to=YYYY-MM-DD&departmentId=
+// Who's out: holidays from work_calendar plus each colleague's leave and missing attendance.
+// Employees see their own department; attendance.read holders can pick the departments it
+// covers. Leave type is hidden from peers (only leave.read holders see it) - they only see
+// that a colleague is out.
+func (h *Handler) TeamCalendar(c *fiber.Ctx) error {
+	viewer := authx.GetUser(c)
+	if viewer == nil {
+		return response.Unauthorized("Unauthorized")
+	}
+	attendanceScope, err := authx.PermissionScope(c, authx.PermAttendanceRead)
+	if err != nil {
+		return err
+	}
+	leaveScope, err := authx.PermissionScope(c, authx.PermLeaveRead)
+	if err != nil {
+		return err
+	}
+
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		return response.Validation("Invalid from date format (YYYY-MM-DD)", nil)
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		return response.Validation("Invalid to date format (YYYY-MM-DD)", nil)
+	}
+	if to.Before(from) {
+		return response.Validation("to must not be before from", nil)
+	}
+	if to.Sub(from) > maxTeamCalendarDays*24*time.Hour {
+		return response.Validation("Range must not exceed 62 days", nil)
+	}
+
+	me, err := h.userRepo.GetByID(c.Context(), viewer.ID)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	var departmentID uint
+	if v := c.Query("departmentId"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return response.Validation("invalid departmentId", nil)
+		}
+		departmentID = uint(n)
+	} else if me.DepartmentID != nil {
+		departmentID = *me.DepartmentID
+	}
+	if departmentID == 0 {
+		return response.Validation("departmentId is required", nil)
+	}
+	ownDepartment := me.DepartmentID != nil && *me.DepartmentID == departmentID
+	if !ownDepartment && !attendanceScope.Allows(&departmentID) {
+		return response.Forbidden("You can only view your own department")
+	}
+
+	days, err := h.repo.ListRange(c.Context(), from, to)
+	if err != nil {
+		return response.Internal(err)
+	}
+	rules, err := h.repo.ListRules(c.Context(), from, to)
+	if err != nil {
+		return response.Internal(err)
+	}
+	dayResults := make([]teamDayResponse, len(days))
+	for i, d := range days {
+		date := d.WorkDate.Format("2006-01-02")
+		dayResults[i] = teamDayResponse{
+			Date:         date,
+			IsWorkingDay: d.IsWorkingDay,
+			WorkUnit:     d.WorkUnit,
+			Note:         d.Note,
+			Rules:        rulesOnDay(rules, date, &departmentID, false),
+		}
+	}
+
+	members, err := h.userRepo.ListActiveByDepartment(c.Context(), departmentID)
+	if err != nil {
+		return response.Internal(err)
+	}
+
+	fromStr := from.Format("2006-01-02")
+	toStr := to.Format("2006-01-02")
+	// Today is not over yet, so only earlier days can be reported as out
+	todayStr := time.Now().In(h.cfg.TimeLocation()).Format("2006-01-02")
+
+	// One batch projection per month for the whole department
+	entriesByUser := make(map[uint][]teamEntryResponse, len(members))
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(to); month = month.AddDate(0, 1, 0) {
+		breakdowns, err := h.leaveService.ProjectMonthlyBreakdowns(c.Context(), members, month.Year(), int(month.Month()))
+		if err != nil {
+			return response.Internal(err)
+		}
+		for _, m := range members {
+			b, ok := breakdowns[m.ID]
+			if !ok {
+				continue
+			}
+			showType := leaveScope.All || m.ID == viewer.ID
+			for _, d := range b.Days {
+				dateStr := d.Date.Format("2006-01-02")
+				if dateStr < fromStr || dateStr > toStr || dateStr >= todayStr || d.MissingUnits <= 0 {
+					continue
+				}
+				entry := teamEntryFor(&d, showType)
+				entry.Date = dateStr
+				entriesByUser[m.ID] = append(entriesByUser[m.ID], entry)
+			}
+		}
+	}
+
+	memberResults := make([]teamMemberResponse, 0, len(members))
+	for _, m := range members {
+		entries := entriesByUser[m.ID]
+		if entries == nil {
+			entries = []teamEntryResponse{}
+		}
+		memberResults = append(memberResults, teamMemberResponse{
+			UserID:  m.ID,
+			Name:    m.Name,
+			Entries: entries,
+		})
+	}
+
+	return response.OK(c, map[string]interface{}{
+		"from":         fromStr,
+		"to":           toStr,
+		"departmentId": departmentID,
+		"days":         dayResults,
+		"members":      memberResults,
+	})
+}
+
+// teamEntryFor turns a day's leave allocation into a calendar entry
+func teamEntryFor(d *leave.DayAllocation, showType bool) teamEntryResponse {
+	units := leaveUnits(d)
+	if units <= 0 {
+		return teamEntryResponse{Kind: TeamEntryMissing, Units: d.MissingUnits}
+	}
+	entry := teamEntryResponse{Kind: TeamEntryLeave, Units: units}
+	if showType {
+		entry.LeaveType = d.Coverage
+	}
+	return entry
+}
@@ -0,0 +1,157 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+// bitsOf returns the values set in a parsed field
+func bitsOf(bits uint64) []int {
+	var out []int
+	for v := 0; v < 64; v++ {
+		if bits&(1<<uint(v)) != 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func sameInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseField(t *testing.T) {
+	cases := []struct {
+		name  string
+		field string
+		b     bounds
+		want  []int
+	}{
+		{"value", "5", minuteBounds, []int{5}},
+		{"star", "*", monthBounds, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+		{"range", "9-12", hourBounds, []int{9, 10, 11, 12}},
+		{"step", "*/15", minuteBounds, []int{0, 15, 30, 45}},
+		{"range step", "1-10/3", domBounds, []int{1, 4, 7, 10}},
+		{"value step runs to the max", "20/2", hourBounds, []int{20, 22}},
+		{"list", "1,15,31", domBounds, []int{1, 15, 31}},
+		{"list of ranges", "1-2,5-6", dowBounds, []int{1, 2, 5, 6}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bits, err := parseField(tc.field, tc.b)
+			if err != nil {
+				t.Fatalf("parseField(%q): %v", tc.field, err)
+			}
+			if got := bitsOf(bits); !sameInts(got, tc.want) {
+				t.Errorf("parseField(%q) = %v, want %v", tc.field, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"1,,2 * * * *",
+		"-1 * * * *",
+	}
+	for _, expr := range cases {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): want an error", expr)
+		}
+	}
+}
+
+func TestParseSundaySeven(t *testing.T) {
+	s, err := Parse("0 0 * * 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.dow&1 == 0 {
+		t.Errorf("day-of-week 7 should match Sunday (0)")
+	}
+}
+
+func TestNext(t *testing.T) {
+	// The default APP_TZ, and a zone with daylight saving time for the DST edges
+	appTZ, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	if err != nil {
+		t.Skipf("time zone not available: %v", err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone not available: %v", err)
+	}
+	at := func(loc *time.Location, y, mo, d, h, mi int) time.Time {
+		return time.Date(y, time.Month(mo), d, h, mi, 0, 0, loc)
+	}
+
+	cases := []struct {
+		name string
+		expr string
+		from time.Time
+		want []time.Time
+	}{
+		{"every minute is strictly after", "* * * * *", at(appTZ, 2026, 3, 1, 10, 0).Add(30 * time.Second),
+			[]time.Time{at(appTZ, 2026, 3, 1, 10, 1), at(appTZ, 2026, 3, 1, 10, 2)}},
+		{"daily at 01:00 in APP_TZ", "0 1 * * *", at(appTZ, 2026, 1, 31, 23, 0),
+			[]time.Time{at(appTZ, 2026, 2, 1, 1, 0), at(appTZ, 2026, 2, 2, 1, 0)}},
+		{"first of the month", "5 0 1 * *", at(appTZ, 2026, 12, 15, 0, 0),
+			[]time.Time{at(appTZ, 2027, 1, 1, 0, 5), at(appTZ, 2027, 2, 1, 0, 5)}},
+		{"weekdays at 09:00", "0 9 * * 1-5", at(appTZ, 2026, 3, 6, 9, 0), // a Friday
+			[]time.Time{at(appTZ, 2026, 3, 9, 9, 0), at(appTZ, 2026, 3, 10, 9, 0)}},
+		{"either day field when both are set", "0 0 13 * 5", at(appTZ, 2026, 3, 1, 0, 0),
+			[]time.Time{at(appTZ, 2026, 3, 6, 0, 0), at(appTZ, 2026, 3, 13, 0, 0), at(appTZ, 2026, 3, 20, 0, 0)}},
+		{"leap day", "0 0 29 2 *", at(appTZ, 2026, 3, 1, 0, 0),
+			[]time.Time{at(appTZ, 2028, 2, 29, 0, 0)}},
+		{"never", "0 0 30 2 *", at(appTZ, 2026, 1, 1, 0, 0),
+			[]time.Time{{}}},
+		// 29 March 2026: 02:00 CET jumps to 03:00 CEST, so 02:30 doesn't exist that day
+		{"spring forward skips the missing time", "30 2 * * *", at(berlin, 2026, 3, 28, 12, 0),
+			[]time.Time{at(berlin, 2026, 3, 30, 2, 30)}},
+		{"spring forward keeps the interval", "*/30 * * * *", at(berlin, 2026, 3, 29, 1, 45),
+			[]time.Time{at(berlin, 2026, 3, 29, 3, 0), at(berlin, 2026, 3, 29, 3, 30)}},
+		// 25 October 2026: 03:00 CEST falls back to 02:00 CET, so 02:30 happens twice; it fires once
+		{"fall back fires once", "30 2 * * *", at(berlin, 2026, 10, 24, 12, 0),
+			[]time.Time{time.Date(2026, 10, 25, 1, 30, 0, 0, time.UTC), at(berlin, 2026, 10, 26, 2, 30)}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tc.expr, err)
+			}
+			from := tc.from
+			for i, want := range tc.want {
+				got := s.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("Next #%d of %q after %v = %v, want %v", i+1, tc.expr, from, got, want)
+				}
+				if !got.IsZero() && got.Location() != tc.from.Location() {
+					t.Errorf("Next #%d in %v, want %v", i+1, got.Location(), tc.from.Location())
+				}
+				from = got
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors ("12345678901234567890"), base32 encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8-digit codes; 6-digit codes are their last 6 digits
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range rfc6238Vectors {
+		if got := totpCode(key, v.unix/totpPeriod); got != v.code {
+			t.Errorf("T=%d: code %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestValidateTOTPRFC6238(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		step, ok := ValidateTOTP(rfc6238Secret, v.code, time.Unix(v.unix, 0))
+		if !ok {
+			t.Errorf("T=%d: code %s rejected", v.unix, v.code)
+			continue
+		}
+		if step != v.unix/totpPeriod {
+			t.Errorf("T=%d: step %d, want %d", v.unix, step, v.unix/totpPeriod)
+		}
+	}
+
+	// Spaces and a lowercase secret are accepted; other lengths are not
+	if _, ok := ValidateTOTP("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", " 287 082 ", time.Unix(59, 0)); !ok {
+		t.Error("code with spaces and lowercase secret rejected")
+	}
+	for _, code := range []string{"", "28708", "2870820", "94287082"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, code, time.Unix(59, 0)); ok {
+			t.Errorf("code %q accepted", code)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", "287082", time.Unix(59, 0)); ok {
+		t.Error("invalid secret accepted")
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / totpPeriod
+
+	cases := []struct {
+		name   string
+		offset int64
+		ok     bool
+	}{
+		{"two steps behind", -2, false},
+		{"previous step", -1, true},
+		{"current step", 0, true},
+		{"next step", 1, true},
+		{"two steps ahead", 2, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := ValidateTOTP(rfc6238Secret, totpCode(key, step+tc.offset), now)
+			if ok != tc.ok {
+				t.Fatalf("accepted = %v, want %v", ok, tc.ok)
+			}
+			if ok && got != step+tc.offset {
+				t.Errorf("step %d, want %d (the code's own step)", got, step+tc.offset)
+			}
+		})
+	}
+}
+
+// A code is accepted once per time step: callers store the returned step and refuse steps at
+// or before the last one used (see auth.Repo.UseTwoFactorStep).
+func TestValidateTOTPReusedStep(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1234567890, 0)
+	step := start.Unix() / totpPeriod
+
+	var lastUsed int64
+	use := func(code string, at time.Time) bool {
+		s, ok := ValidateTOTP(rfc6238Secret, code, at)
+		if !ok || s <= lastUsed {
+			return false
+		}
+		lastUsed = s
+		return true
+	}
+
+	code := totpCode(key, step)
+	if !use(code, start) {
+		t.Fatal("fresh code rejected")
+	}
+	if use(code, start.Add(5*time.Second)) {
+		t.Error("same code accepted twice in its step")
+	}
+	// Still inside the skew window of the next step, but its step was used
+	if use(code, start.Add(totpPeriod*time.Second)) {
+		t.Error("used code accepted again in the next step")
+	}
+	// An older code within the window is a replay too
+	if use(totpCode(key, step-1), start) {
+		t.Error("code of an earlier step accepted after a later one was used")
+	}
+	if !use(totpCode(key, step+1), start.Add(totpPeriod*time.Second)) {
+		t.Error("code of the next step rejected")
+	}
+}
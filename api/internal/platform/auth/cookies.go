@@ -29,6 +29,40 @@ func (cm *CookieManager) ClearTokens(c *fiber.Ctx) {
 	cm.clearCookie(c, "refresh_token")
 }
 
+// oidcStateCookie binds an SSO login to the browser that started it (login CSRF protection)
+const oidcStateCookie = "oidc_state"
+
+// SetOIDCState stores the state of an SSO login in progress. Always SameSite=Lax: the
+// callback is a cross-site top-level redirect from the identity provider.
+func (cm *CookieManager) SetOIDCState(c *fiber.Ctx, state string, ttl time.Duration) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Expires:  time.Now().Add(ttl),
+		HTTPOnly: true,
+		Secure:   cm.cfg.Auth.CookieSecure,
+		Path:     "/api/v1/auth/oidc",
+		SameSite: "Lax",
+	})
+}
+
+// OIDCState returns the state stored by SetOIDCState
+func (cm *CookieManager) OIDCState(c *fiber.Ctx) string {
+	return c.Cookies(oidcStateCookie)
+}
+
+func (cm *CookieManager) ClearOIDCState(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		Expires:  time.Unix(0, 0),
+		HTTPOnly: true,
+		Secure:   cm.cfg.Auth.CookieSecure,
+		Path:     "/api/v1/auth/oidc",
+		SameSite: "Lax",
+	})
+}
+
 func (cm *CookieManager) setCookie(c *fiber.Ctx, name, value string, ttl time.Duration) {
 	cookie := new(fiber.Cookie)
 	cookie.Name = name
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -11,11 +13,22 @@ import (
 
 // Claims represents the standard JWT claims plus custom ones.
 type Claims struct {
-	UserID uint   `json:"uid"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"uid"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid,omitempty"` // server-side login session (auth_sessions)
+	TokenType string `json:"typ,omitempty"` // TokenTypeAccess, TokenTypeRefresh or TokenTypeTwoFactor
 	jwt.RegisteredClaims
 }
 
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeTwoFactor = "2fa" // login challenge: password checked, TOTP code still due
+)
+
+// twoFactorChallengeTTL is how long the user has to enter the code after the password step
+const twoFactorChallengeTTL = 5 * time.Minute
+
 // Manager handles JWT creation and verification.
 type Manager struct {
 	secret          []byte
@@ -31,18 +44,55 @@ func NewManager(cfg *config.Config) *Manager {
 	}
 }
 
-func (m *Manager) GenerateTokens(userID uint, role string) (accessToken, refreshToken string, err error) {
-	accessToken, err = m.generateAccessToken(userID, role)
+// GenerateTokens issues an access and a refresh token for a login session.
+// refreshID is the refresh token's jti, which the caller stores to rotate it.
+func (m *Manager) GenerateTokens(userID uint, role string, sessionID uint) (accessToken, refreshToken, refreshID string, err error) {
+	accessToken, err = m.generateAccessToken(userID, role, sessionID)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
-	refreshToken, err = m.generateRefreshToken(userID, role)
+	refreshID, err = newTokenID()
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
+	}
+	refreshToken, err = m.generateRefreshToken(userID, role, sessionID, refreshID)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return accessToken, refreshToken, refreshID, nil
+}
+
+// RefreshTokenTTL is how long a refresh token (and an idle session) stays valid
+func (m *Manager) RefreshTokenTTL() time.Duration {
+	return m.refreshTokenTTL
+}
+
+// GenerateTwoFactorChallenge issues the short-lived token that carries a login from the
+// password step to the TOTP step. It grants no access by itself.
+func (m *Manager) GenerateTwoFactorChallenge(userID uint, role string) (string, error) {
+	id, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := Claims{
+		UserID:    userID,
+		Role:      role,
+		TokenType: TokenTypeTwoFactor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(twoFactorChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "time-attendance-be",
+			Subject:   fmt.Sprintf("%d", userID),
+			ID:        id,
+		},
 	}
 
-	return accessToken, refreshToken, nil
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(m.secret)
 }
 
 func (m *Manager) VerifyToken(tokenString string) (*Claims, error) {
@@ -65,11 +115,13 @@ func (m *Manager) VerifyToken(tokenString string) (*Claims, error) {
 	return nil, fmt.Errorf("invalid token")
 }
 
-func (m *Manager) generateAccessToken(userID uint, role string) (string, error) {
+func (m *Manager) generateAccessToken(userID uint, role string, sessionID uint) (string, error) {
 	now := time.Now()
 	claims := Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -84,18 +136,20 @@ func (m *Manager) generateAccessToken(userID uint, role string) (string, error)
 	return token.SignedString(m.secret)
 }
 
-func (m *Manager) generateRefreshToken(userID uint, role string) (string, error) {
+func (m *Manager) generateRefreshToken(userID uint, role string, sessionID uint, id string) (string, error) {
 	now := time.Now()
 	claims := Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		TokenType: TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.refreshTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "time-attendance-be",
 			Subject:   fmt.Sprintf("%d", userID),
-			ID:        fmt.Sprintf("refresh-%d-%d", userID, now.Unix()),
+			ID:        id,
 		},
 	}
 
@@ -103,6 +157,15 @@ func (m *Manager) generateRefreshToken(userID uint, role string) (string, error)
 	return token.SignedString(m.secret)
 }
 
+// newTokenID returns a random, unguessable token ID
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 
 
 
@@ -0,0 +1,69 @@
+// Package events is an in-process domain event bus: modules publish facts about what changed
+// and other modules subscribe, without importing each other's services.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Event is a domain event; Name identifies the subscribers it is delivered to
+type Event interface {
+	Name() string
+}
+
+// Handler reacts to one event
+type Handler func(ctx context.Context, e Event) error
+
+// Bus delivers events synchronously, in subscription order. A failing or panicking handler is
+// logged and does not stop the others or the publisher: the change that raised the event has
+// already been committed.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	logger   *zap.Logger
+}
+
+func NewBus(logger *zap.Logger) *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+		logger:   logger.Named("events"),
+	}
+}
+
+// Subscribe registers a handler for events with the given name
+func (b *Bus) Subscribe(name string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], h)
+}
+
+// Publish delivers e to every subscriber. Publishing on a nil bus is a no-op.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	handlers := b.handlers[e.Name()]
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := b.deliver(ctx, h, e); err != nil {
+			b.logger.Error("event handler failed",
+				zap.String("event", e.Name()),
+				zap.Error(err))
+		}
+	}
+}
+
+func (b *Bus) deliver(ctx context.Context, h Handler, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, e)
+}
@@ -0,0 +1,180 @@
+package lease
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Lease is a named, time-limited lock held by one instance (table scheduler_leases).
+// The holder renews it well before ExpiresAt; if the holder dies the lease expires and
+// another instance takes over.
+type Lease struct {
+	Name       string    `gorm:"primaryKey;size:64"`
+	Holder     string    `gorm:"size:190;not null"`
+	AcquiredAt time.Time `gorm:"not null"`
+	RenewedAt  time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index"`
+}
+
+func (Lease) TableName() string {
+	return "scheduler_leases"
+}
+
+// Elector runs a callback only while this instance holds the lease.
+// Instances compare expires_at against their own clock, so the TTL should be
+// comfortably larger than the expected clock skew between pods.
+type Elector struct {
+	db     *gorm.DB
+	name   string
+	holder string
+	ttl    time.Duration
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	leader bool
+}
+
+func NewElector(db *gorm.DB, name, holder string, ttl time.Duration, logger *zap.Logger) *Elector {
+	return &Elector{
+		db:     db,
+		name:   name,
+		holder: holder,
+		ttl:    ttl,
+		logger: logger.With(zap.String("lease", name), zap.String("holder", holder)),
+	}
+}
+
+// Holder returns this instance's identity
+func (e *Elector) Holder() string {
+	return e.holder
+}
+
+// IsLeader reports whether this instance currently holds the lease
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+func (e *Elector) setLeader(v bool) {
+	e.mu.Lock()
+	e.leader = v
+	e.mu.Unlock()
+}
+
+// Current returns the lease row, or nil if nobody ever acquired it
+func (e *Elector) Current(ctx context.Context) (*Lease, error) {
+	var l Lease
+	err := e.db.WithContext(ctx).First(&l, "name = ?", e.name).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+// tryAcquire takes the lease if it is free, expired or already ours, and extends it by ttl
+func (e *Elector) tryAcquire(ctx context.Context) (bool, error) {
+	now := time.Now()
+	expires := now.Add(e.ttl)
+
+	// acquired_at is assigned first: MySQL applies SET clauses left to right
+	res := e.db.WithContext(ctx).Model(&Lease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", e.name, e.holder, now).
+		Updates(map[string]interface{}{
+			"acquired_at": gorm.Expr("CASE WHEN holder = ? THEN acquired_at ELSE ? END", e.holder, now),
+			"holder":      e.holder,
+			"renewed_at":  now,
+			"expires_at":  expires,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// No row yet (first start): insert, losing the race is fine
+	res = e.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Lease{Name: e.name, Holder: e.holder, AcquiredAt: now, RenewedAt: now, ExpiresAt: expires})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// release gives the lease up so another instance can take over without waiting for the TTL
+func (e *Elector) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := e.db.WithContext(ctx).Model(&Lease{}).
+		Where("name = ? AND holder = ?", e.name, e.holder).
+		Update("expires_at", time.Now().Add(-time.Second)).Error
+	if err != nil {
+		e.logger.Warn("failed to release lease", zap.Error(err))
+	}
+}
+
+// Run competes for the lease until ctx is done. While leader, run is called with a context
+// that is cancelled as soon as the lease cannot be renewed (failover) or ctx ends.
+func (e *Elector) Run(ctx context.Context, run func(ctx context.Context)) {
+	interval := e.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		cancelRun context.CancelFunc
+		done      chan struct{}
+	)
+	stopRun := func() {
+		if cancelRun == nil {
+			return
+		}
+		cancelRun()
+		<-done
+		cancelRun = nil
+		e.setLeader(false)
+	}
+
+	for {
+		ok, err := e.tryAcquire(ctx)
+		if err != nil {
+			e.logger.Error("lease acquire/renew failed", zap.Error(err))
+		}
+
+		switch {
+		case ok && cancelRun == nil:
+			e.logger.Info("acquired lease - starting scheduled jobs")
+			e.setLeader(true)
+			var runCtx context.Context
+			runCtx, cancelRun = context.WithCancel(ctx)
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				run(runCtx)
+			}()
+		case !ok && cancelRun != nil:
+			e.logger.Warn("lost lease - stopping scheduled jobs")
+			stopRun()
+		}
+
+		select {
+		case <-ctx.Done():
+			wasLeader := cancelRun != nil
+			stopRun()
+			if wasLeader {
+				e.release()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package mailer sends transactional email. The driver is chosen by MAIL_DRIVER:
+// "smtp" delivers through an SMTP server, "outbox" (default) writes each message
+// as an .eml file into MAIL_OUTBOX_DIR for local testing.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"time-attendance-be/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer configured by cfg.Mail
+func New(cfg *config.Config, logger *zap.Logger) (Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		if cfg.Mail.SMTPHost == "" {
+			return nil, fmt.Errorf("mailer: MAIL_SMTP_HOST is required for the smtp driver")
+		}
+		return NewSMTP(cfg.Mail), nil
+	case "outbox", "":
+		return NewOutbox(cfg.Mail.OutboxDir, cfg.Mail.From, logger), nil
+	default:
+		return nil, fmt.Errorf("mailer: unknown driver %q", cfg.Mail.Driver)
+	}
+}
+
+// render builds an RFC 5322 message
+func render(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+	return []byte(b.String())
+}
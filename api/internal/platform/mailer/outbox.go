@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Outbox writes each message to an .eml file instead of sending it (local development)
+type Outbox struct {
+	dir    string
+	from   string
+	logger *zap.Logger
+}
+
+func NewOutbox(dir, from string, logger *zap.Logger) *Outbox {
+	return &Outbox{dir: dir, from: from, logger: logger}
+}
+
+func (m *Outbox) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("outbox dir: %w", err)
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), hex.EncodeToString(suffix))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, render(m.from, msg), 0o600); err != nil {
+		return fmt.Errorf("outbox write: %w", err)
+	}
+	m.logger.Info("mail written to outbox", zap.String("path", path), zap.Strings("to", msg.To), zap.String("subject", msg.Subject))
+	return nil
+}
@@ -0,0 +1,174 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	jwksTTL           = time.Hour        // keys are refetched at least this often
+	jwksRefreshMinGap = 30 * time.Second // an unknown kid triggers a refetch at most this often
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// keySet caches the provider's signing keys by kid. A token signed with an unknown kid
+// (key rotation) triggers a refetch, rate limited so bad tokens can't hammer the provider.
+type keySet struct {
+	client *http.Client
+
+	mu        sync.Mutex
+	uri       string
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+func newKeySet(client *http.Client) *keySet {
+	return &keySet{client: client}
+}
+
+func (ks *keySet) setURI(uri string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if ks.uri != uri {
+		ks.uri = uri
+		ks.keys = nil
+	}
+}
+
+// key returns the public key for kid; an empty kid matches the only key of a single-key set
+func (ks *keySet) key(ctx context.Context, kid string) (interface{}, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	stale := ks.keys == nil || time.Since(ks.fetchedAt) > jwksTTL
+	if k, ok := ks.lookup(kid); ok && !stale {
+		return k, nil
+	}
+	if !stale && time.Since(ks.fetchedAt) < jwksRefreshMinGap {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if err := ks.fetch(ctx); err != nil {
+		return nil, err
+	}
+	if k, ok := ks.lookup(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (ks *keySet) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, k := range ks.keys {
+			return k, true
+		}
+	}
+	k, ok := ks.keys[kid]
+	return k, ok
+}
+
+func (ks *keySet) fetch(ctx context.Context) error {
+	if ks.uri == "" {
+		return errors.New("jwks: provider not discovered")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.uri, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks: GET %s: status %d", ks.uri, resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue // skip key types we don't support
+		}
+		keys[k.Kid] = pub
+	}
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	return nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,251 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockCodeTTL is how long an authorization code issued by the mock is valid
+const mockCodeTTL = time.Minute
+
+// MockIdP is a tiny OpenID provider for local development and tests. It signs in whoever
+// types an email (or passes login_hint), accepts any client secret, and supports exactly
+// what Provider uses: discovery, JWKS, authorization code + PKCE S256. Never run it in production.
+type MockIdP struct {
+	issuer   string
+	basePath string
+	key      *rsa.PrivateKey
+	kid      string
+
+	mu    sync.Mutex
+	codes map[string]mockGrant
+}
+
+type mockGrant struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	email         string
+	name          string
+	expiresAt     time.Time
+}
+
+// NewMockIdP returns a mock provider whose issuer is issuer; it serves requests under the
+// issuer URL's path (e.g. http://localhost:8080/mock-idp -> /mock-idp/...)
+func NewMockIdP(issuer string) (*MockIdP, error) {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return nil, err
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := RandomString(8)
+	if err != nil {
+		return nil, err
+	}
+	return &MockIdP{
+		issuer:   strings.TrimRight(issuer, "/"),
+		basePath: strings.TrimRight(u.Path, "/"),
+		key:      key,
+		kid:      kid,
+		codes:    map[string]mockGrant{},
+	}, nil
+}
+
+// BasePath is the path prefix the mock must be mounted at
+func (m *MockIdP) BasePath() string {
+	return m.basePath
+}
+
+func (m *MockIdP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, m.basePath) {
+	case "/.well-known/openid-configuration":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"issuer":                                m.issuer,
+			"authorization_endpoint":                m.issuer + "/authorize",
+			"token_endpoint":                        m.issuer + "/token",
+			"jwks_uri":                              m.issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"code_challenge_methods_supported":      []string{"S256"},
+		})
+	case "/jwks":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": m.kid,
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	case "/authorize":
+		m.authorize(w, r)
+	case "/token":
+		m.token(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+var mockLoginPage = template.Must(template.New("login").Parse(`<!doctype html>
+<html><head><title>Mock IdP</title></head>
+<body style="font-family: sans-serif; max-width: 24rem; margin: 4rem auto">
+<h2>Mock identity provider</h2>
+<p>Development only. Any email signs in.</p>
+<form method="post">
+{{range $k, $v := .Params}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">
+{{end}}<p><label>Email<br><input name="email" type="email" required autofocus></label></p>
+<p><label>Name<br><input name="name"></label></p>
+<button type="submit">Sign in</button>
+</form>
+</body></html>`))
+
+// authorize shows a login form (GET) or issues a code (POST, or GET with login_hint)
+func (m *MockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	email := r.Form.Get("email")
+	if email == "" {
+		email = r.Form.Get("login_hint")
+	}
+	if email == "" {
+		params := url.Values{}
+		for _, k := range []string{"client_id", "redirect_uri", "state", "nonce", "code_challenge", "code_challenge_method", "scope", "response_type"} {
+			if v := r.Form.Get(k); v != "" {
+				params.Set(k, v)
+			}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = mockLoginPage.Execute(w, map[string]interface{}{"Params": params})
+		return
+	}
+
+	if r.Form.Get("response_type") != "code" || r.Form.Get("code_challenge_method") != "S256" || r.Form.Get("code_challenge") == "" {
+		http.Error(w, "mock idp supports response_type=code with PKCE S256 only", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(r.Form.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code, err := RandomString(24)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	m.mu.Lock()
+	m.codes[code] = mockGrant{
+		clientID:      r.Form.Get("client_id"),
+		redirectURI:   r.Form.Get("redirect_uri"),
+		codeChallenge: r.Form.Get("code_challenge"),
+		nonce:         r.Form.Get("nonce"),
+		email:         strings.ToLower(strings.TrimSpace(email)),
+		name:          r.Form.Get("name"),
+		expiresAt:     time.Now().Add(mockCodeTTL),
+	}
+	m.mu.Unlock()
+
+	q := redirectURI.Query()
+	q.Set("code", code)
+	q.Set("state", r.Form.Get("state"))
+	redirectURI.RawQuery = q.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token exchanges a code for an ID token
+func (m *MockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+	if id, _, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(id)
+	}
+
+	code := r.PostForm.Get("code")
+	m.mu.Lock()
+	g, ok := m.codes[code]
+	delete(m.codes, code)
+	m.mu.Unlock()
+
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	case !ok || time.Now().After(g.expiresAt):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "unknown or expired code"})
+		return
+	case g.clientID != clientID || g.redirectURI != r.PostForm.Get("redirect_uri"):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "client or redirect_uri mismatch"})
+		return
+	case S256Challenge(r.PostForm.Get("code_verifier")) != g.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	name := g.name
+	if name == "" {
+		name = strings.SplitN(g.email, "@", 2)[0]
+	}
+	now := time.Now()
+	verified := true
+	claims := Claims{
+		Nonce:         g.nonce,
+		Email:         g.email,
+		EmailVerified: &verified,
+		Name:          name,
+		AMR:           []string{"pwd"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   "mock|" + g.email,
+			Audience:  jwt.ClaimStrings{g.clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = m.kid
+	idToken, err := tok.SignedString(m.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "mock-" + code,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
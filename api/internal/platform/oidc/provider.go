@@ -0,0 +1,237 @@
+// Package oidc is a minimal OpenID Connect relying party: discovery, authorization code
+// flow with PKCE, and ID token validation against the provider's JWKS.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"time-attendance-be/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// discoveryTTL is how long the discovery document is cached
+const discoveryTTL = time.Hour
+
+// Discovery is the part of the provider metadata (/.well-known/openid-configuration) we use
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Claims are the ID token claims we read
+type Claims struct {
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     *bool    `json:"email_verified,omitempty"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+	AMR               []string `json:"amr,omitempty"` // authentication methods, e.g. ["pwd","mfa"]
+	AuthorizedParty   string   `json:"azp,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Provider talks to one OpenID provider
+type Provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       string
+	client       *http.Client
+
+	mu           sync.Mutex
+	discovery    *Discovery
+	discoveredAt time.Time
+	keys         *keySet
+}
+
+// NewProvider returns a provider for cfg. Discovery happens lazily on first use.
+// client may be nil (a client with a 10s timeout is used).
+func NewProvider(cfg config.OIDCConfig, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	p := &Provider{
+		issuer:       strings.TrimRight(cfg.IssuerURL, "/"),
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		scopes:       cfg.Scopes,
+		client:       client,
+	}
+	p.keys = newKeySet(client)
+	return p
+}
+
+// Discover returns the provider metadata, fetching it when not cached
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	var d Discovery
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimRight(d.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match configured %q", d.Issuer, p.issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: incomplete provider metadata")
+	}
+	p.discovery = &d
+	p.discoveredAt = time.Now()
+	p.keys.setURI(d.JWKSURI)
+	return p.discovery, nil
+}
+
+// AuthCodeURL is where the browser is sent to sign in
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.clientID)
+	q.Set("redirect_uri", p.redirectURL)
+	q.Set("scope", p.scopes)
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange trades the authorization code for tokens and returns the raw ID token
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("client_id", p.clientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oidc token exchange: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("oidc token exchange: status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("oidc token exchange: status %d: %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("oidc token exchange: no id_token in response")
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken checks the ID token signature (JWKS), issuer, audience, expiry and nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*Claims, error) {
+	if _, err := p.Discover(ctx); err != nil {
+		return nil, err
+	}
+
+	claims, err := p.parseIDToken(ctx, raw, p.issuer)
+	if errors.Is(err, jwt.ErrTokenInvalidIssuer) {
+		// Some providers put a trailing slash on iss
+		claims, err = p.parseIDToken(ctx, raw, p.issuer+"/")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("oidc id token: %w", err)
+	}
+
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.clientID {
+		return nil, errors.New("oidc id token: azp does not match client id")
+	}
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, errors.New("oidc id token: nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("oidc id token: missing sub")
+	}
+	return claims, nil
+}
+
+func (p *Provider) parseIDToken(ctx context.Context, raw, issuer string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(raw, &claims,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return p.keys.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// Issuer is the configured issuer URL (without trailing slash)
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+func (p *Provider) getJSON(ctx context.Context, u string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(dst)
+}
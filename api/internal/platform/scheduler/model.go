@@ -0,0 +1,36 @@
+package scheduler
+
+import "time"
+
+// Run is one persisted execution of a job (table scheduler_job_runs)
+type Run struct {
+	ID          uint      `gorm:"primaryKey"`
+	JobName     string    `gorm:"size:64;not null;index"`
+	Trigger     string    `gorm:"type:enum('SCHEDULE','STARTUP','MANUAL','RETRY');not null"`
+	Status      string    `gorm:"type:enum('RUNNING','SUCCESS','FAILED');not null;index"`
+	Instance    string    `gorm:"size:190;not null"`
+	TriggeredBy *uint     // admin user for MANUAL/RETRY
+	RetryOf     *uint     // run ID being retried
+	StartedAt   time.Time `gorm:"not null;index"`
+	FinishedAt  *time.Time
+	DurationMs  int64   `gorm:"not null;default:0"`
+	Processed   int     `gorm:"not null;default:0"`
+	Failed      int     `gorm:"not null;default:0"`
+	Message     *string `gorm:"type:varchar(255)"`
+	Error       *string `gorm:"type:text"`
+}
+
+func (Run) TableName() string {
+	return "scheduler_job_runs"
+}
+
+const (
+	TriggerSchedule = "SCHEDULE"
+	TriggerStartup  = "STARTUP"
+	TriggerManual   = "MANUAL"
+	TriggerRetry    = "RETRY"
+
+	StatusRunning = "RUNNING"
+	StatusSuccess = "SUCCESS"
+	StatusFailed  = "FAILED"
+)
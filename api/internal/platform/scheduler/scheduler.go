@@ -0,0 +1,364 @@
+// Package scheduler runs named jobs on cron schedules and records every run in the database.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"time-attendance-be/internal/pkg/cron"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Result carries the counts a job reports for one run
+type Result struct {
+	Processed int
+	Failed    int
+	Message   string
+}
+
+// Func is the work done by a job
+type Func func(ctx context.Context) (Result, error)
+
+// Job is a registered unit of scheduled work
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string // 5-field cron expression, evaluated in the scheduler's location (APP_TZ)
+	RunOnStart  bool   // also run when the scheduler starts (catch up after downtime)
+	Run         Func
+
+	schedule *cron.Schedule
+}
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+)
+
+// staleRunAfter is how long a RUNNING row blocks new runs before it is treated as abandoned
+const staleRunAfter = 6 * time.Hour
+
+type Scheduler struct {
+	db       *gorm.DB
+	loc      *time.Location
+	instance string
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+func New(db *gorm.DB, loc *time.Location, instance string, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		db:       db,
+		loc:      loc,
+		instance: instance,
+		logger:   logger.Named("scheduler"),
+		jobs:     make(map[string]*Job),
+		running:  make(map[string]bool),
+	}
+}
+
+// Register adds a job; names must be unique and schedules valid
+func (s *Scheduler) Register(job Job) error {
+	sched, err := cron.Parse(job.Schedule)
+	if err != nil {
+		return err
+	}
+	job.schedule = sched
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %q already registered", job.Name)
+	}
+	s.jobs[job.Name] = &job
+	return nil
+}
+
+// MustRegister is Register that panics on error (wiring time)
+func (s *Scheduler) MustRegister(job Job) {
+	if err := s.Register(job); err != nil {
+		panic(err)
+	}
+}
+
+// JobInfo describes a registered job
+type JobInfo struct {
+	Name        string
+	Description string
+	Schedule    string
+	RunOnStart  bool
+	NextRun     time.Time
+	Running     bool
+}
+
+// Jobs lists registered jobs ordered by name
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().In(s.loc)
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		infos = append(infos, JobInfo{
+			Name:        j.Name,
+			Description: j.Description,
+			Schedule:    j.Schedule,
+			RunOnStart:  j.RunOnStart,
+			NextRun:     j.schedule.Next(now),
+			Running:     s.running[j.Name],
+		})
+	}
+	sort.Slice(infos, func(i, k int) bool { return infos[i].Name < infos[k].Name })
+	return infos
+}
+
+// History returns the latest runs of a job (all jobs if name is empty), newest first
+func (s *Scheduler) History(ctx context.Context, name string, limit int) ([]Run, error) {
+	query := s.db.WithContext(ctx).Model(&Run{})
+	if name != "" {
+		query = query.Where("job_name = ?", name)
+	}
+	var runs []Run
+	err := query.Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+// GetRun returns a run by ID
+func (s *Scheduler) GetRun(ctx context.Context, id uint) (*Run, error) {
+	var r Run
+	if err := s.db.WithContext(ctx).First(&r, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Start fires jobs on their schedules until ctx is done (blocking).
+// Runs marked RUNNING for too long (instance died mid-run) are closed as FAILED first.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.failStaleRuns(ctx)
+
+	s.mu.Lock()
+	startup := make([]string, 0)
+	for name, j := range s.jobs {
+		if j.RunOnStart {
+			startup = append(startup, name)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(startup)
+	s.logger.Info("scheduler started", zap.Int("jobs", len(s.jobs)), zap.String("instance", s.instance))
+
+	// Startup runs happen in order so e.g. grants land before deductions
+	for _, name := range startup {
+		if ctx.Err() != nil {
+			break
+		}
+		_, _ = s.execute(ctx, name, TriggerStartup, nil, nil)
+	}
+
+	for {
+		now := time.Now().In(s.loc)
+		next, due := s.nextDue(now)
+		if next.IsZero() {
+			<-ctx.Done()
+			break
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.wg.Wait()
+			s.logger.Info("scheduler stopped")
+			return
+		case <-timer.C:
+		}
+
+		for _, name := range due {
+			name := name
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				_, _ = s.execute(ctx, name, TriggerSchedule, nil, nil)
+			}()
+		}
+	}
+
+	s.wg.Wait()
+	s.logger.Info("scheduler stopped")
+}
+
+// nextDue returns the earliest next activation and the jobs due at that time
+func (s *Scheduler) nextDue(now time.Time) (time.Time, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	var due []string
+	for name, j := range s.jobs {
+		t := j.schedule.Next(now)
+		if t.IsZero() {
+			continue
+		}
+		switch {
+		case next.IsZero() || t.Before(next):
+			next = t
+			due = []string{name}
+		case t.Equal(next):
+			due = append(due, name)
+		}
+	}
+	sort.Strings(due)
+	return next, due
+}
+
+// Trigger starts a job now in the background and returns its run record.
+// retryOf links the run to an earlier (failed) run.
+func (s *Scheduler) Trigger(ctx context.Context, name string, triggeredBy uint, retryOf *uint) (*Run, error) {
+	trigger := TriggerManual
+	if retryOf != nil {
+		trigger = TriggerRetry
+	}
+
+	started := make(chan *Run, 1)
+	errc := make(chan error, 1)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		// Detached from the request context: the job outlives the HTTP call
+		run, err := s.executeWithStart(context.Background(), name, trigger, &triggeredBy, retryOf, started)
+		if run == nil && err != nil {
+			errc <- err
+		}
+	}()
+
+	select {
+	case run := <-started:
+		return run, nil
+	case err := <-errc:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, name, trigger string, triggeredBy, retryOf *uint) (*Run, error) {
+	return s.executeWithStart(ctx, name, trigger, triggeredBy, retryOf, nil)
+}
+
+// executeWithStart runs a job and records it. `started` (optional) receives the RUNNING record
+// once it is persisted. Returns (nil, err) if the run could not start.
+func (s *Scheduler) executeWithStart(ctx context.Context, name, trigger string, triggeredBy, retryOf *uint, started chan<- *Run) (*Run, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	if !ok {
+		s.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+	if s.running[name] {
+		s.mu.Unlock()
+		return nil, ErrJobRunning
+	}
+	s.running[name] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, name)
+		s.mu.Unlock()
+	}()
+
+	// Another instance (manual trigger on a non-leader pod) may be running it
+	var active int64
+	if err := s.db.WithContext(ctx).Model(&Run{}).
+		Where("job_name = ? AND status = ? AND started_at > ?", name, StatusRunning, time.Now().Add(-staleRunAfter)).
+		Count(&active).Error; err != nil {
+		return nil, err
+	}
+	if active > 0 {
+		return nil, ErrJobRunning
+	}
+
+	run := &Run{
+		JobName:     name,
+		Trigger:     trigger,
+		Status:      StatusRunning,
+		Instance:    s.instance,
+		TriggeredBy: triggeredBy,
+		RetryOf:     retryOf,
+		StartedAt:   time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(run).Error; err != nil {
+		s.logger.Error("failed to record job run", zap.String("job", name), zap.Error(err))
+		return nil, err
+	}
+	if started != nil {
+		snapshot := *run
+		started <- &snapshot
+	}
+
+	s.logger.Info("job started", zap.String("job", name), zap.String("trigger", trigger), zap.Uint("runID", run.ID))
+	res, err := s.safeRun(ctx, job)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	run.Processed = res.Processed
+	run.Failed = res.Failed
+	if res.Message != "" {
+		msg := truncate(res.Message, 255)
+		run.Message = &msg
+	}
+	if err != nil {
+		run.Status = StatusFailed
+		e := err.Error()
+		run.Error = &e
+		s.logger.Error("job failed", zap.String("job", name), zap.Uint("runID", run.ID), zap.Error(err))
+	} else {
+		run.Status = StatusSuccess
+		s.logger.Info("job finished", zap.String("job", name), zap.Uint("runID", run.ID),
+			zap.Int("processed", res.Processed), zap.Int("failed", res.Failed), zap.Int64("durationMs", run.DurationMs))
+	}
+
+	// Record the outcome even if ctx was cancelled mid-run
+	saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if saveErr := s.db.WithContext(saveCtx).Save(run).Error; saveErr != nil {
+		s.logger.Error("failed to update job run", zap.String("job", name), zap.Uint("runID", run.ID), zap.Error(saveErr))
+	}
+	return run, err
+}
+
+// safeRun turns a panicking job into a failed run
+func (s *Scheduler) safeRun(ctx context.Context, job *Job) (res Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func (s *Scheduler) failStaleRuns(ctx context.Context) {
+	msg := "interrupted (instance stopped before the run finished)"
+	err := s.db.WithContext(ctx).Model(&Run{}).
+		Where("status = ? AND (instance = ? OR started_at < ?)", StatusRunning, s.instance, time.Now().Add(-staleRunAfter)).
+		Updates(map[string]interface{}{"status": StatusFailed, "error": msg, "finished_at": time.Now()}).Error
+	if err != nil {
+		s.logger.Warn("failed to close stale job runs", zap.Error(err))
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
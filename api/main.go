@@ -24,8 +24,19 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	// Start leave scheduler in background
-	go container.Leave.Service().StartScheduler(ctx)
+	// Start scheduled jobs in background - only on the instance holding the scheduler lease
+	electorDone := make(chan struct{})
+	go func() {
+		defer close(electorDone)
+		container.Elector.Run(ctx, container.Jobs.Start)
+	}()
+
+	// Process queued leave summary recomputations (every instance claims tasks from the shared queue)
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		container.Leave.Service().RunRecomputeWorker(ctx)
+	}()
 
 	go func() {
 		_ = app.Listen(cfg.HTTPAddr)
@@ -36,5 +47,13 @@ func main() {
 	defer cancel()
 	_ = app.ShutdownWithContext(shutdownCtx)
 
+	// ctx is done, so the elector stops the jobs and releases the lease; wait for both before exiting
+	for _, done := range []chan struct{}{electorDone, workerDone} {
+		select {
+		case <-done:
+		case <-shutdownCtx.Done():
+		}
+	}
+
 	os.Exit(0)
 }